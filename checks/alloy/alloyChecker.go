@@ -1,5 +1,361 @@
 package alloy
 
-import "github.com/grafana/otel-checker/checks/utils"
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
 
-func CheckAlloySetup(reporter *utils.ComponentReporter, language string) {}
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// signals are the outputs of the otelcol components
+var signals = []string{"traces", "metrics", "logs"}
+
+var instanceIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+func CheckAlloySetup(reporter *utils.ComponentReporter, commands utils.Commands) {
+	checkAlloyConfig(reporter, commands.AlloyConfigPath, commands.Target, os.Getenv)
+	if commands.AlloyURL != "" {
+		checkRunningAlloy(reporter, commands.AlloyURL, os.Getenv)
+	}
+}
+
+func checkAlloyConfig(reporter *utils.ComponentReporter, configPath string, profile target.Profile, getenv func(string) string) {
+	c, err := LoadConfig(configPath)
+	if err != nil {
+		reporter.AddError(utils.Capitalize(err.Error()))
+		return
+	}
+	checkConfig(reporter, c, profile, getenv)
+}
+
+func checkConfig(reporter *utils.ComponentReporter, c *Config, profile target.Profile, getenv func(string) string) {
+	checkDuplicates(reporter, c)
+	checkReferences(reporter, c)
+	checkEnvFunction(reporter, c)
+
+	if !slices.ContainsFunc(c.Scopes(), func(s *Scope) bool {
+		return slices.ContainsFunc(s.Components, func(b *Block) bool { return strings.HasPrefix(b.Name, "otelcol.") })
+	}) {
+		reporter.AddWarning("config.alloy has no otelcol components, so Alloy doesn't receive OpenTelemetry data. Add otelcol.receiver.otlp, otelcol.processor.batch and otelcol.exporter.otlphttp")
+		return
+	}
+	checkReceivers(reporter, c)
+	checkExporters(reporter, c, profile, getenv)
+}
+
+func checkDuplicates(reporter *utils.ComponentReporter, c *Config) {
+	for _, s := range c.Scopes() {
+		var seen []string
+		for _, b := range s.Components {
+			if slices.Contains(seen, b.ID()) {
+				reporter.AddError(fmt.Sprintf("Component %s is defined more than once on config.alloy (line %d). Use a different label, Alloy fails to start otherwise", s.location(b), b.Line))
+			}
+			seen = append(seen, b.ID())
+		}
+	}
+}
+
+// checkReferences checks that the components referenced in the arguments, e.g. in output { ... } blocks, are defined
+// in the same scope
+func checkReferences(reporter *utils.ComponentReporter, c *Config) {
+	for _, s := range c.Scopes() {
+		for _, b := range s.Components {
+			if b.Name == "declare" {
+				// the body has a scope of its own
+				continue
+			}
+			walkReferences(b.Body, []string{s.location(b)}, func(location []string, path []string) {
+				if component, isComponent := s.resolve(path); isComponent && component == nil {
+					reporter.AddError(fmt.Sprintf("Value of %s on config.alloy references %s, which is not defined. Define the component or fix the reference, Alloy fails to start otherwise", strings.Join(location, " > "), strings.Join(path[:max(len(path)-1, 2)], ".")))
+				}
+			})
+		}
+	}
+}
+
+// checkEnvFunction reports the env function, which is deprecated in favor of sys.env
+func checkEnvFunction(reporter *utils.ComponentReporter, c *Config) {
+	walkCalls(c.File.Body, nil, func(location []string, call *Call) {
+		if FunctionName(call) == "env" {
+			reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy uses env, which is deprecated. Use sys.env instead", strings.Join(location, " > ")))
+		}
+	})
+}
+
+// checkReceivers checks that an otelcol.receiver.otlp accepts OTLP over HTTP and that each signal it receives passes
+// otelcol.processor.batch and reaches an exporter
+func checkReceivers(reporter *utils.ComponentReporter, c *Config) {
+	found := false
+	for _, s := range c.Scopes() {
+		for _, r := range s.ComponentsNamed("otelcol.receiver.otlp") {
+			found = true
+			if r.Block("http") == nil {
+				reporter.AddWarning(fmt.Sprintf("Component %s on config.alloy has no http block, so it doesn't receive OTLP over HTTP, which the SDKs use by default. Add http {}", s.location(r)))
+			}
+			output := r.Block("output")
+			if output == nil || len(output.Attributes) == 0 {
+				reporter.AddError(fmt.Sprintf("Component %s on config.alloy has no output block, so the received data is dropped. Add output { traces = [...] } with the next components", s.location(r)))
+				continue
+			}
+			for _, signal := range signals {
+				if output.Attribute(signal) != nil {
+					checkPipeline(reporter, s, r, signal)
+				}
+			}
+		}
+	}
+	if !found {
+		reporter.AddWarning("config.alloy has no otelcol.receiver.otlp component. Add it to receive OTLP from the applications")
+	}
+}
+
+// checkPipeline follows the output of the signal from the receiver through the components. Paths through the
+// components of imported modules and through the arguments of a declare block whose instance is not known can't be
+// followed, so they are not reported.
+func checkPipeline(reporter *utils.ComponentReporter, s *Scope, receiver *Block, signal string) {
+	p := pipeline{signal: signal, instances: map[instanceKey]*instance{}}
+	p.outputs(receiver, s, nil)
+	for len(p.queue) > 0 {
+		r := p.queue[0]
+		p.queue = p.queue[1:]
+		p.follow(r)
+	}
+
+	switch {
+	case p.unknown && (!p.exported || !p.batched):
+	case !p.exported:
+		reporter.AddError(fmt.Sprintf("The %s of %s on config.alloy don't reach an otelcol.exporter component, so they are dropped. Connect the output to an exporter", signal, s.location(receiver)))
+	case !p.batched:
+		reporter.AddWarning(fmt.Sprintf("The %s of %s on config.alloy don't pass an otelcol.processor.batch component. Add it before the exporter to compress data better and reduce the number of requests", signal, s.location(receiver)))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("The %s of %s on config.alloy are batched and exported by %s", signal, s.location(receiver), strings.Join(p.exporters, ", ")))
+	}
+}
+
+// maxInstanceDepth limits how deep custom components are followed, since a declare block can't use itself
+const maxInstanceDepth = 10
+
+// instance is a custom component used in a scope, e.g. pipeline "default" { ... } of declare "pipeline"
+type instance struct {
+	block  *Block
+	scope  *Scope
+	caller *instance
+	depth  int
+}
+
+type instanceKey struct {
+	block  *Block
+	caller *instance
+}
+
+// pipelineReference is a reference in the output of a component, with the scope it is resolved in and the custom component
+// whose declare block the scope belongs to, or nil if it is not known
+type pipelineReference struct {
+	path   []string
+	scope  *Scope
+	caller *instance
+}
+
+// pipeline follows the references of the outputs of one signal
+type pipeline struct {
+	signal    string
+	queue     []pipelineReference
+	visited   []string
+	instances map[instanceKey]*instance
+	batched   bool
+	exported  bool
+	unknown   bool
+	exporters []string
+}
+
+// outputs adds the references of the output block of a component for the signal
+func (p *pipeline) outputs(b *Block, s *Scope, caller *instance) {
+	output := b.Block("output")
+	if output == nil {
+		return
+	}
+	if attribute := output.Attribute(p.signal); attribute != nil {
+		p.add(attribute.Value, s, caller)
+	}
+}
+
+func (p *pipeline) add(e Expr, s *Scope, caller *instance) {
+	walkExpr(e, func(path []string) {
+		p.queue = append(p.queue, pipelineReference{path: path, scope: s, caller: caller})
+	})
+}
+
+func (p *pipeline) follow(r pipelineReference) {
+	if r.path[0] == "argument" && r.scope.parent != nil {
+		// the value of the argument is set by the instance of the custom component
+		if r.caller == nil {
+			p.unknown = true
+			return
+		}
+		if len(r.path) < 2 {
+			return
+		}
+		if a := r.caller.block.Attribute(r.path[1]); a != nil {
+			p.add(a.Value, r.caller.scope, r.caller.caller)
+		}
+		return
+	}
+
+	b, _ := r.scope.resolve(r.path)
+	if b == nil {
+		// undefined components are reported by checkReferences
+		return
+	}
+	key := fmt.Sprintf("%p %p %s", r.scope, r.caller, strings.Join(r.path, "."))
+	if slices.Contains(p.visited, key) {
+		return
+	}
+	p.visited = append(p.visited, key)
+
+	if d, ok := r.scope.Declare(b.Name); ok {
+		p.followExport(d, b, r)
+		return
+	}
+	switch {
+	case r.scope.imported(b.Name):
+		p.unknown = true
+		return
+	case b.Name == "otelcol.processor.batch":
+		p.batched = true
+	case strings.HasPrefix(b.Name, "otelcol.exporter."):
+		p.exported = true
+		if location := r.scope.location(b); !slices.Contains(p.exporters, location) {
+			p.exporters = append(p.exporters, location)
+		}
+	}
+	p.outputs(b, r.scope, r.caller)
+}
+
+// followExport follows the export of a custom component that a reference such as pipeline.default.input refers to
+// into the body of its declare block
+func (p *pipeline) followExport(d *Declare, b *Block, r pipelineReference) {
+	parts := len(strings.Split(b.ID(), "."))
+	if len(r.path) <= parts {
+		return
+	}
+	key := instanceKey{block: b, caller: r.caller}
+	i, ok := p.instances[key]
+	if !ok {
+		i = &instance{block: b, scope: r.scope, caller: r.caller}
+		if r.caller != nil {
+			i.depth = r.caller.depth + 1
+		}
+		p.instances[key] = i
+	}
+	if i.depth > maxInstanceDepth {
+		p.unknown = true
+		return
+	}
+	export, ok := d.Component("export." + r.path[parts])
+	if !ok {
+		return
+	}
+	if value := export.Attribute("value"); value != nil {
+		p.add(value.Value, &d.Scope, i)
+	}
+}
+
+// checkExporters checks the endpoint and the authentication of the otelcol.exporter.otlphttp components
+func checkExporters(reporter *utils.ComponentReporter, c *Config, profile target.Profile, getenv func(string) string) {
+	found := false
+	for _, s := range c.Scopes() {
+		for _, e := range s.ComponentsNamed("otelcol.exporter.otlphttp") {
+			found = true
+			checkExporter(reporter, s, e, profile, getenv)
+		}
+	}
+	if !found {
+		reporter.AddWarning("config.alloy has no otelcol.exporter.otlphttp component. Add it to send OTLP to " + profile.Backend)
+	}
+}
+
+func checkExporter(reporter *utils.ComponentReporter, s *Scope, e *Block, profile target.Profile, getenv func(string) string) {
+	client := e.Block("client")
+	if client == nil {
+		reporter.AddError(fmt.Sprintf("Component %s on config.alloy has no client block. Add client { endpoint = ... }", s.location(e)))
+		return
+	}
+	location := s.location(e) + " > client"
+	endpoint := attributeValue(client.Body, "endpoint", getenv)
+	checkEndpoint(reporter, location+" > endpoint", endpoint, profile)
+
+	auth := client.Attribute("auth")
+	if auth == nil {
+		if strings.Contains(endpoint.Value, "grafana.net") {
+			reporter.AddError(fmt.Sprintf("Component %s on config.alloy sends to Grafana Cloud without authentication. Add an otelcol.auth.basic component and set client > auth to its handler", s.location(e)))
+		}
+		return
+	}
+	path, _ := ReferencePath(auth.Value)
+	if authenticator, _ := s.resolve(path); authenticator != nil && authenticator.Name == "otelcol.auth.basic" {
+		checkBasicAuth(reporter, s, authenticator, strings.Contains(endpoint.Value, "grafana.net"), getenv)
+	}
+}
+
+func attributeValue(body Body, name string, getenv func(string) string) stringValue {
+	a := body.Attribute(name)
+	if a == nil {
+		return stringValue{Known: true}
+	}
+	return evalString(a.Value, getenv)
+}
+
+// checkEndpoint checks the endpoint of an exporter against the target. The exporters are not checked against a local
+// target, since Alloy is the local target itself.
+func checkEndpoint(reporter *utils.ComponentReporter, location string, endpoint stringValue, profile target.Profile) {
+	switch {
+	case profile.Local || !endpoint.Known:
+		return
+	case endpoint.Env != "" && endpoint.Value == "":
+		reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy reads the environment variable %s, which is not set. Make sure it is set for Alloy", location, endpoint.Env))
+	case profile.Warns(target.WarnLocalhost) && strings.Contains(endpoint.Value, "localhost"):
+		reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy is set to localhost. Update to an endpoint similar to %s to be able to send telemetry to %s", location, profile.EndpointExample, profile.Backend))
+	case endpoint.Value != "" && profile.MatchesEndpoint(endpoint.Value):
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of %s on config.alloy set in the format similar to %s", location, profile.EndpointExample))
+	default:
+		reporter.AddError(fmt.Sprintf("Value of %s on config.alloy is not set in the format similar to %s", location, profile.EndpointExample))
+	}
+}
+
+// checkBasicAuth checks the credentials of an otelcol.auth.basic component. They should be read with sys.env, and
+// the username is the numeric instance id for Grafana Cloud.
+func checkBasicAuth(reporter *utils.ComponentReporter, s *Scope, auth *Block, toGrafanaCloud bool, getenv func(string) string) {
+	username := attributeValue(auth.Body, "username", getenv)
+	location := s.location(auth) + " > username"
+	switch {
+	case !username.Known:
+		// read from another component, e.g. local.file
+	case username.Env != "" && username.Value == "":
+		reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy reads the environment variable %s, which is not set. Make sure it is set for Alloy", location, username.Env))
+	case username.Value == "":
+		reporter.AddError(fmt.Sprintf("Value of %s on config.alloy is not set", location))
+	case toGrafanaCloud && !instanceIDPattern.MatchString(username.Value):
+		reporter.AddError(fmt.Sprintf("Value of %s on config.alloy is not a Grafana Cloud instance id. Use the numeric instance id of your stack", location))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of %s on config.alloy is set", location))
+	}
+
+	password := attributeValue(auth.Body, "password", getenv)
+	location = s.location(auth) + " > password"
+	switch {
+	case !password.Known:
+		// read from another component, e.g. local.file
+	case password.Env != "" && password.Value == "":
+		reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy reads the environment variable %s, which is not set. Make sure it is set for Alloy", location, password.Env))
+	case password.Value == "":
+		reporter.AddError(fmt.Sprintf("Value of %s on config.alloy is not set. Set it through sys.env(\"GRAFANA_CLOUD_API_KEY\")", location))
+	case password.Env == "":
+		reporter.AddWarning(fmt.Sprintf("Value of %s on config.alloy is written in the config. Read it through sys.env(\"GRAFANA_CLOUD_API_KEY\") instead, so that it is not stored with the config", location))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of %s on config.alloy is set", location))
+	}
+}
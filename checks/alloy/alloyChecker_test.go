@@ -0,0 +1,293 @@
+package alloy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validConfig = `
+otelcol.receiver.otlp "default" {
+  grpc { }
+  http { }
+
+  output {
+    metrics = [otelcol.processor.batch.default.input]
+    logs    = [otelcol.processor.batch.default.input]
+    traces  = [otelcol.processor.batch.default.input]
+  }
+}
+
+otelcol.processor.batch "default" {
+  output {
+    metrics = [otelcol.exporter.otlphttp.grafana_cloud.input]
+    logs    = [otelcol.exporter.otlphttp.grafana_cloud.input]
+    traces  = [otelcol.exporter.otlphttp.grafana_cloud.input]
+  }
+}
+
+otelcol.auth.basic "grafana_cloud" {
+  username = sys.env("GRAFANA_CLOUD_INSTANCE_ID")
+  password = sys.env("GRAFANA_CLOUD_API_KEY")
+}
+
+otelcol.exporter.otlphttp "grafana_cloud" {
+  client {
+    endpoint = "https://otlp-gateway-prod-us-east-0.grafana.net/otlp"
+    auth     = otelcol.auth.basic.grafana_cloud.handler
+  }
+}
+`
+
+func TestCheckAlloyConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		env              map[string]string
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name:   "valid Grafana Cloud pipeline",
+			config: validConfig,
+			env:    map[string]string{"GRAFANA_CLOUD_INSTANCE_ID": "123456", "GRAFANA_CLOUD_API_KEY": "glc_token"},
+			expectedChecks: []string{
+				"alloy: The metrics of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.grafana_cloud",
+				"alloy: The logs of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.grafana_cloud",
+				"alloy: The traces of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.grafana_cloud",
+				"alloy: Value of otelcol.exporter.otlphttp.grafana_cloud > client > endpoint on config.alloy set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+				"alloy: Value of otelcol.auth.basic.grafana_cloud > username on config.alloy is set",
+				"alloy: Value of otelcol.auth.basic.grafana_cloud > password on config.alloy is set",
+			},
+		},
+		{
+			name: "undefined references and missing batch processor",
+			config: `
+otelcol.receiver.otlp "default" {
+  grpc { }
+
+  output {
+    traces = [otelcol.processor.batch.missing.input]
+    logs   = [otelcol.exporter.otlphttp.default.input]
+  }
+}
+
+otelcol.exporter.otlphttp "default" {
+  client {
+    endpoint = env("OTLP_ENDPOINT")
+    auth     = otelcol.auth.basic.creds.handler
+  }
+}
+`,
+			expectedErrors: []string{
+				"alloy: Value of otelcol.receiver.otlp.default > output > traces on config.alloy references otelcol.processor.batch.missing, which is not defined. Define the component or fix the reference, Alloy fails to start otherwise",
+				"alloy: Value of otelcol.exporter.otlphttp.default > client > auth on config.alloy references otelcol.auth.basic.creds, which is not defined. Define the component or fix the reference, Alloy fails to start otherwise",
+				"alloy: The traces of otelcol.receiver.otlp.default on config.alloy don't reach an otelcol.exporter component, so they are dropped. Connect the output to an exporter",
+			},
+			expectedWarnings: []string{
+				"alloy: Value of otelcol.exporter.otlphttp.default > client > endpoint on config.alloy uses env, which is deprecated. Use sys.env instead",
+				"alloy: Component otelcol.receiver.otlp.default on config.alloy has no http block, so it doesn't receive OTLP over HTTP, which the SDKs use by default. Add http {}",
+				"alloy: The logs of otelcol.receiver.otlp.default on config.alloy don't pass an otelcol.processor.batch component. Add it before the exporter to compress data better and reduce the number of requests",
+				"alloy: Value of otelcol.exporter.otlphttp.default > client > endpoint on config.alloy reads the environment variable OTLP_ENDPOINT, which is not set. Make sure it is set for Alloy",
+			},
+		},
+		{
+			name: "credentials written in the config",
+			config: `
+otelcol.receiver.otlp "default" {
+  http { }
+  output {
+    traces = [otelcol.processor.batch.default.input]
+  }
+}
+
+otelcol.processor.batch "default" {
+  output {
+    traces = [otelcol.exporter.otlphttp.default.input]
+  }
+}
+
+otelcol.auth.basic "default" {
+  username = "my-stack"
+  password = "glc_token"
+}
+
+otelcol.exporter.otlphttp "default" {
+  client {
+    endpoint = "https://otlp-gateway-prod-eu-west-2.grafana.net/otlp"
+    auth     = otelcol.auth.basic.default.handler
+  }
+}
+
+otelcol.exporter.otlphttp "local" {
+  client {
+    endpoint = "http://localhost:4318"
+  }
+}
+
+otelcol.exporter.otlphttp "local" {
+  client {
+    endpoint = "https://otlp-gateway-prod-eu-west-2.grafana.net/otlp"
+  }
+}
+`,
+			expectedErrors: []string{
+				"alloy: Component otelcol.exporter.otlphttp.local is defined more than once on config.alloy (line 33). Use a different label, Alloy fails to start otherwise",
+				"alloy: Value of otelcol.auth.basic.default > username on config.alloy is not a Grafana Cloud instance id. Use the numeric instance id of your stack",
+				"alloy: Component otelcol.exporter.otlphttp.local on config.alloy sends to Grafana Cloud without authentication. Add an otelcol.auth.basic component and set client > auth to its handler",
+			},
+			expectedWarnings: []string{
+				"alloy: Value of otelcol.auth.basic.default > password on config.alloy is written in the config. Read it through sys.env(\"GRAFANA_CLOUD_API_KEY\") instead, so that it is not stored with the config",
+				"alloy: Value of otelcol.exporter.otlphttp.local > client > endpoint on config.alloy is set to localhost. Update to an endpoint similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp to be able to send telemetry to your Grafana Cloud instance",
+			},
+			expectedChecks: []string{
+				"alloy: The traces of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.default",
+				"alloy: Value of otelcol.exporter.otlphttp.default > client > endpoint on config.alloy set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+				"alloy: Value of otelcol.exporter.otlphttp.local > client > endpoint on config.alloy set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			},
+		},
+		{
+			name: "custom components of declare blocks and modules",
+			config: `
+otelcol.receiver.otlp "default" {
+  http { }
+
+  output {
+    traces  = [batched.default.input]
+    metrics = [batched.default.unbatched]
+    logs    = [module.pipeline.default.input]
+  }
+}
+
+declare "batched" {
+  argument "next" { }
+
+  otelcol.processor.batch "default" {
+    output {
+      traces  = argument.next.value
+      metrics = argument.next.value
+    }
+  }
+
+  export "input" {
+    value = otelcol.processor.batch.default.input
+  }
+
+  export "unbatched" {
+    value = otelcol.processor.memory_limiter.missing.input
+  }
+}
+
+batched "default" {
+  next = [cloud.default.input]
+}
+
+declare "cloud" {
+  otelcol.exporter.otlphttp "default" {
+    client {
+      endpoint = "https://otlp-gateway-prod-us-east-0.grafana.net/otlp"
+      auth     = otelcol.auth.basic.default.handler
+    }
+  }
+
+  otelcol.auth.basic "default" {
+    username = sys.env("GRAFANA_CLOUD_INSTANCE_ID")
+    password = sys.env("GRAFANA_CLOUD_API_KEY")
+  }
+
+  export "input" {
+    value = otelcol.exporter.otlphttp.default.input
+  }
+}
+
+cloud "default" { }
+
+import.file "module" {
+  filename = "module.alloy"
+}
+
+module.pipeline "default" { }
+`,
+			env: map[string]string{"GRAFANA_CLOUD_INSTANCE_ID": "123456", "GRAFANA_CLOUD_API_KEY": "glc_token"},
+			expectedErrors: []string{
+				"alloy: Value of declare.batched > export.unbatched > value on config.alloy references otelcol.processor.memory_limiter.missing, which is not defined. Define the component or fix the reference, Alloy fails to start otherwise",
+				"alloy: The metrics of otelcol.receiver.otlp.default on config.alloy don't reach an otelcol.exporter component, so they are dropped. Connect the output to an exporter",
+			},
+			expectedChecks: []string{
+				"alloy: The traces of otelcol.receiver.otlp.default on config.alloy are batched and exported by declare.cloud > otelcol.exporter.otlphttp.default",
+				"alloy: Value of declare.cloud > otelcol.exporter.otlphttp.default > client > endpoint on config.alloy set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+				"alloy: Value of declare.cloud > otelcol.auth.basic.default > username on config.alloy is set",
+				"alloy: Value of declare.cloud > otelcol.auth.basic.default > password on config.alloy is set",
+			},
+		},
+		{
+			name: "no OpenTelemetry components",
+			config: `
+prometheus.scrape "default" {
+  targets    = [{"__address__" = constants.hostname + ":9090"}]
+  forward_to = [prometheus.remote_write.default.receiver]
+}
+
+prometheus.remote_write "default" {
+  endpoint {
+    url = sys.env("PROMETHEUS_URL")
+  }
+}
+`,
+			expectedWarnings: []string{
+				"alloy: config.alloy has no otelcol components, so Alloy doesn't receive OpenTelemetry data. Add otelcol.receiver.otlp, otelcol.processor.batch and otelcol.exporter.otlphttp",
+			},
+		},
+		{
+			name:   "syntax error",
+			config: "otelcol.receiver.otlp \"default\" {\n  http {\n}\n",
+			expectedErrors: []string{
+				"alloy: Could not parse file DIR/config.alloy: 4:1: expected an attribute or a block, found end of file",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "config.alloy"), []byte(tt.config), 0644))
+			getenv := func(name string) string { return tt.env[name] }
+
+			reporter := utils.Reporter{}
+			c := reporter.Component("alloy")
+			checkAlloyConfig(c, dir, target.Default(), getenv)
+
+			for i := range c.Errors {
+				c.Errors[i] = strings.ReplaceAll(c.Errors[i], dir, "DIR")
+			}
+			assert.ElementsMatch(t, tt.expectedErrors, c.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, c.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, c.Checks, "checks mismatch")
+		})
+	}
+}
+
+func TestMissingAlloyConfig(t *testing.T) {
+	dir := t.TempDir()
+	reporter := utils.Reporter{}
+	c := reporter.Component("alloy")
+	checkAlloyConfig(c, dir+"/", target.Default(), os.Getenv)
+
+	require.Len(t, c.Errors, 1)
+	assert.Contains(t, c.Errors[0], "Could not check file "+dir+"/config.alloy")
+}
+
+func TestConfigPath(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "config.alloy", ConfigPath(""))
+	assert.Equal(t, "alloy/config.alloy", ConfigPath("alloy/"))
+	assert.Equal(t, filepath.Join(dir, "config.alloy"), ConfigPath(dir))
+	assert.Equal(t, "/etc/alloy/main.alloy", ConfigPath("/etc/alloy/main.alloy"))
+}
@@ -0,0 +1,298 @@
+package alloy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DefaultConfigFile is the config file Alloy is usually started with
+const DefaultConfigFile = "config.alloy"
+
+// componentNamespaces are the first parts of the names of the built-in Alloy components. References starting with
+// them, with the name of a custom component or with the label of an import block refer to the exports of a
+// component, other references refer to the standard library, e.g. constants.hostname.
+var componentNamespaces = []string{
+	"beyla", "database_observability", "discovery", "faro", "local", "loki", "mimir", "otelcol", "prometheus",
+	"pyroscope", "remote",
+}
+
+// Config is a parsed Alloy config
+type Config struct {
+	File *File
+	// Scope has the components of the top level of the file
+	Scope
+}
+
+// Scope is the top level of the file or the body of a declare block. The components of a scope can only reference
+// the components of the same scope.
+type Scope struct {
+	// Components are the blocks with a label in the order they are defined, e.g. otelcol.receiver.otlp "default"
+	Components []*Block
+	// Declares are the custom components defined by the declare blocks of the scope
+	Declares []*Declare
+	parent   *Scope
+	// declares are the ids of the declare blocks around the scope, e.g. [declare.pipeline]
+	declares []string
+}
+
+// Declare is a custom component defined by a declare block, e.g. declare "pipeline" { ... }
+type Declare struct {
+	Block *Block
+	// Scope has the components of the body of the declare block
+	Scope
+}
+
+// ConfigPath returns the path of the config file of the -alloy-config-path flag, which accepts a file or a directory
+// containing a config.alloy
+func ConfigPath(path string) string {
+	if path == "" || strings.HasSuffix(path, "/") {
+		return path + DefaultConfigFile
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return filepath.Join(path, DefaultConfigFile)
+	}
+	return path
+}
+
+// LoadConfig loads the Alloy config of the -alloy-config-path flag
+func LoadConfig(path string) (*Config, error) {
+	path = ConfigPath(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not check file %s: %w", path, err)
+	}
+	c, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse file %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ParseConfig parses an Alloy config
+func ParseConfig(data []byte) (*Config, error) {
+	f, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{File: f}
+	c.Scope.add(f.Body)
+	return c, nil
+}
+
+// add registers the components and declare blocks of the body in the scope
+func (s *Scope) add(body Body) {
+	for _, b := range body.Blocks {
+		if b.Label == "" {
+			continue
+		}
+		s.Components = append(s.Components, b)
+		if b.Name == "declare" {
+			d := &Declare{Block: b, Scope: Scope{parent: s, declares: append(slices.Clone(s.declares), b.ID())}}
+			d.Scope.add(b.Body)
+			s.Declares = append(s.Declares, d)
+		}
+	}
+}
+
+// Scopes returns the scope and the scopes of all declare blocks in it
+func (s *Scope) Scopes() []*Scope {
+	scopes := []*Scope{s}
+	for _, d := range s.Declares {
+		scopes = append(scopes, d.Scopes()...)
+	}
+	return scopes
+}
+
+// location returns where a component of the scope is defined, e.g. declare.pipeline > otelcol.processor.batch.default
+func (s *Scope) location(b *Block) string {
+	return strings.Join(append(slices.Clone(s.declares), b.ID()), " > ")
+}
+
+// Component returns the component with the id, e.g. otelcol.receiver.otlp.default
+func (s *Scope) Component(id string) (*Block, bool) {
+	for _, b := range s.Components {
+		if b.ID() == id {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// ComponentsNamed returns the components with the name, e.g. otelcol.exporter.otlphttp
+func (s *Scope) ComponentsNamed(name string) []*Block {
+	var result []*Block
+	for _, b := range s.Components {
+		if b.Name == name {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
+// Declare returns the custom component with the name, which is defined in the scope or in one of the scopes around
+// it
+func (s *Scope) Declare(name string) (*Declare, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		for _, d := range scope.Declares {
+			if d.Block.Label == name {
+				return d, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// imported returns true if the component name belongs to a module of an import block, e.g. module.pipeline of
+// import.file "module". The components of the module are not known without loading it.
+func (s *Scope) imported(name string) bool {
+	namespace, _, _ := strings.Cut(name, ".")
+	for scope := s; scope != nil; scope = scope.parent {
+		if slices.ContainsFunc(scope.Components, func(b *Block) bool {
+			return strings.HasPrefix(b.Name, "import.") && b.Label == namespace
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the component a reference such as otelcol.processor.batch.default.input refers to. isComponent is
+// false for references to the standard library and to the arguments of a declare block.
+func (s *Scope) resolve(path []string) (component *Block, isComponent bool) {
+	_, isCustom := s.Declare(path[0])
+	if !slices.Contains(componentNamespaces, path[0]) && !isCustom && !s.imported(path[0]) {
+		return nil, false
+	}
+	for i := len(path); i >= 2; i-- {
+		if b, ok := s.Component(strings.Join(path[:i], ".")); ok {
+			return b, true
+		}
+	}
+	return nil, true
+}
+
+// walkReferences calls fn for each reference in the attributes of the body and its nested blocks, with the location
+// of the attribute, e.g. [otelcol.receiver.otlp.default output traces]
+func walkReferences(body Body, location []string, fn func(location []string, path []string)) {
+	for _, a := range body.Attributes {
+		walkExpr(a.Value, func(path []string) { fn(append(slices.Clone(location), a.Name), path) })
+	}
+	for _, b := range body.Blocks {
+		walkReferences(b.Body, append(slices.Clone(location), b.ID()), fn)
+	}
+}
+
+func walkExpr(e Expr, fn func(path []string)) {
+	switch e := e.(type) {
+	case *Identifier:
+		fn([]string{e.Name})
+	case *Access:
+		if path, ok := ReferencePath(e); ok {
+			fn(path)
+		} else {
+			walkExpr(e.X, fn)
+		}
+	case *Index:
+		walkExpr(e.X, fn)
+		walkExpr(e.Index, fn)
+	case *Call:
+		for _, arg := range e.Args {
+			walkExpr(arg, fn)
+		}
+	case *Array:
+		for _, element := range e.Elements {
+			walkExpr(element, fn)
+		}
+	case *Object:
+		for _, field := range e.Fields {
+			walkExpr(field.Value, fn)
+		}
+	case *Binary:
+		walkExpr(e.X, fn)
+		walkExpr(e.Y, fn)
+	case *Unary:
+		walkExpr(e.X, fn)
+	}
+}
+
+// walkCalls calls fn for each function call in the attributes of the body and its nested blocks
+func walkCalls(body Body, location []string, fn func(location []string, call *Call)) {
+	var walk func(e Expr, location []string)
+	walk = func(e Expr, location []string) {
+		switch e := e.(type) {
+		case *Call:
+			fn(location, e)
+			for _, arg := range e.Args {
+				walk(arg, location)
+			}
+		case *Access:
+			walk(e.X, location)
+		case *Index:
+			walk(e.X, location)
+			walk(e.Index, location)
+		case *Array:
+			for _, element := range e.Elements {
+				walk(element, location)
+			}
+		case *Object:
+			for _, field := range e.Fields {
+				walk(field.Value, location)
+			}
+		case *Binary:
+			walk(e.X, location)
+			walk(e.Y, location)
+		case *Unary:
+			walk(e.X, location)
+		}
+	}
+	for _, a := range body.Attributes {
+		walk(a.Value, append(slices.Clone(location), a.Name))
+	}
+	for _, b := range body.Blocks {
+		walkCalls(b.Body, append(slices.Clone(location), b.ID()), fn)
+	}
+}
+
+// stringValue is the value of a string expression as far as it can be evaluated without running Alloy
+type stringValue struct {
+	Value string
+	// Env is the environment variable the value is read from, e.g. with sys.env("GRAFANA_CLOUD_API_KEY")
+	Env string
+	// Known is false if the value depends on other components or functions, e.g. local.file.token.content
+	Known bool
+}
+
+// evalString evaluates string literals, sys.env calls and their concatenation
+func evalString(e Expr, getenv func(string) string) stringValue {
+	switch e := e.(type) {
+	case *Literal:
+		if e.Value == nil {
+			return stringValue{Known: true}
+		}
+		if f, ok := e.Value.(float64); ok {
+			return stringValue{Value: fmt.Sprint(f), Known: true}
+		}
+		return stringValue{Value: fmt.Sprint(e.Value), Known: true}
+	case *Call:
+		name := FunctionName(e)
+		if (name == "sys.env" || name == "env") && len(e.Args) == 1 {
+			if arg, ok := e.Args[0].(*Literal); ok {
+				variable := fmt.Sprint(arg.Value)
+				return stringValue{Value: getenv(variable), Env: variable, Known: true}
+			}
+		}
+	case *Binary:
+		if e.Op == "+" {
+			x, y := evalString(e.X, getenv), evalString(e.Y, getenv)
+			env := x.Env
+			if env == "" {
+				env = y.Env
+			}
+			return stringValue{Value: x.Value + y.Value, Env: env, Known: x.Known && y.Known}
+		}
+	}
+	return stringValue{}
+}
@@ -0,0 +1,596 @@
+package alloy
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/collector"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// componentConverter sets the arguments of an Alloy component from the config of a collector component
+type componentConverter struct {
+	// name is the name of the Alloy component, e.g. otelcol.receiver.otlp
+	name    string
+	convert func(s *source, body *Body)
+}
+
+// receiverConverters, processorConverters, exporterConverters and extensionConverters are the collector components
+// that can be converted, by type
+var (
+	receiverConverters = map[string]componentConverter{
+		"otlp": {name: "otelcol.receiver.otlp", convert: convertOTLPReceiver},
+	}
+	processorConverters = map[string]componentConverter{
+		"batch":             {name: "otelcol.processor.batch", convert: convertAttributes},
+		"memory_limiter":    {name: "otelcol.processor.memory_limiter", convert: convertMemoryLimiter},
+		"attributes":        {name: "otelcol.processor.attributes", convert: convertAttributesProcessor},
+		"resourcedetection": {name: "otelcol.processor.resourcedetection", convert: convertAttributes},
+		"transform":         {name: "otelcol.processor.transform", convert: convertTransformProcessor},
+	}
+	exporterConverters = map[string]componentConverter{
+		"otlp":     {name: "otelcol.exporter.otlp", convert: convertOTLPExporter},
+		"otlphttp": {name: "otelcol.exporter.otlphttp", convert: convertOTLPHTTPExporter},
+		"debug":    {name: "otelcol.exporter.debug", convert: convertAttributes},
+	}
+	extensionConverters = map[string]componentConverter{
+		"basicauth":       {name: "otelcol.auth.basic", convert: convertBasicAuth},
+		"bearertokenauth": {name: "otelcol.auth.bearer", convert: convertAttributes},
+	}
+)
+
+// exporterClientSettings are the settings of the otlp and otlphttp exporters that Alloy has in the client block
+var exporterClientSettings = []string{"endpoint", "compression", "headers", "tls", "auth", "balancer_name", "read_buffer_size", "write_buffer_size", "keepalive", "wait_for_ready", "max_idle_conns", "idle_conn_timeout"}
+
+// ConvertCollector converts the collector config of the -collector-config-path flag to an Alloy config, writes it to
+// the -output file or out, and checks the result like the alloy component does
+func ConvertCollector(reporter *utils.ComponentReporter, commands utils.Commands, out io.Writer) {
+	c, err := collector.LoadRawConfigs(commands.CollectorConfigPath)
+	if err != nil {
+		reporter.AddError(utils.Capitalize(err.Error()))
+		return
+	}
+	file := ConvertCollectorConfig(reporter, c)
+	data := append([]byte("// Converted from the collector config by otel-checker convert-collector\n\n"), Format(file)...)
+
+	if commands.AlloyOutputPath == "" {
+		_, _ = out.Write(data)
+	} else {
+		if err := os.WriteFile(commands.AlloyOutputPath, data, 0644); err != nil {
+			reporter.AddError(fmt.Sprintf("Could not write file %s: %s", commands.AlloyOutputPath, err))
+			return
+		}
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Converted the collector config to %s", commands.AlloyOutputPath))
+	}
+
+	converted, err := ParseConfig(data)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not parse the converted config: %s", err))
+		return
+	}
+	checkConfig(reporter, converted, commands.Target, os.Getenv)
+}
+
+// ConvertCollectorConfig converts the components used by the pipelines of a collector config to Alloy components,
+// connected through their output blocks like the pipelines connect them. The components and settings that can't be
+// converted are reported and left out.
+func ConvertCollectorConfig(reporter *utils.ComponentReporter, c *collector.Config) *File {
+	conv := &converter{reporter: reporter, config: c, blocks: map[string]*Block{}}
+	file := &File{}
+
+	for _, r := range c.UsedReceivers() {
+		if b := conv.component(collector.KindReceiver, r, receiverConverters, labelOf(r.ID)); b != nil {
+			conv.blocks[collector.KindReceiver+"/"+r.ID.String()] = b
+			file.Blocks = append(file.Blocks, b)
+		}
+	}
+	// processors are instantiated for each pipeline in the collector. A processor becomes one Alloy component per
+	// group of pipelines with the same name, e.g. traces/backend and metrics/backend, in which each signal is unique.
+	// The components of the other groups get the name of the group in the label, e.g. backend for batch and
+	// mask_backend for attributes/mask.
+	for _, p := range c.Processors {
+		groups := processorGroups(c, p.ID)
+		for _, group := range groups {
+			label := labelOf(p.ID)
+			switch {
+			case len(groups) == 1 || group == "":
+			case p.ID.Name == "":
+				label = sanitizeLabel(group)
+			default:
+				label += "_" + sanitizeLabel(group)
+			}
+			if b := conv.component(collector.KindProcessor, p, processorConverters, label); b != nil {
+				conv.blocks[processorKey(p.ID, group)] = b
+				file.Blocks = append(file.Blocks, b)
+			}
+		}
+	}
+	for _, e := range c.UsedExporters() {
+		if b := conv.component(collector.KindExporter, e, exporterConverters, labelOf(e.ID)); b != nil {
+			conv.blocks[collector.KindExporter+"/"+e.ID.String()] = b
+			file.Blocks = append(file.Blocks, b)
+		}
+	}
+	for _, id := range conv.authenticators {
+		e, ok := c.Component(collector.KindExtension, id)
+		if !ok {
+			continue
+		}
+		if b := conv.component(collector.KindExtension, e, extensionConverters, labelOf(e.ID)); b != nil {
+			file.Blocks = append(file.Blocks, b)
+		}
+	}
+	for _, id := range c.ServiceExtensions {
+		if !slices.Contains(conv.authenticators, id) {
+			reporter.AddWarning(fmt.Sprintf("Extension %s on config.yaml is not converted to Alloy, which has no equivalent component or provides it itself, e.g. health and metrics on its HTTP server", id))
+		}
+	}
+	for _, id := range connectorsOf(c) {
+		reporter.AddWarning(fmt.Sprintf("Connector %s on config.yaml can't be converted to Alloy, so the pipelines it connects are not connected. Add the equivalent otelcol.connector component by hand", id))
+	}
+	if len(c.Telemetry) > 0 {
+		reporter.AddWarning("Value of service > telemetry on config.yaml is not converted to Alloy. Configure the logging block and the HTTP server of Alloy instead")
+	}
+
+	conv.connect()
+	if len(file.Blocks) > 0 {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Converted %d components of the collector config to Alloy", len(file.Blocks)))
+	}
+	return file
+}
+
+type converter struct {
+	reporter *utils.ComponentReporter
+	config   *collector.Config
+	// blocks are the Alloy components by kind and id of the collector component, and pipeline group for processors
+	blocks map[string]*Block
+	// authenticators are the extensions referenced in auth > authenticator by the converted exporters
+	authenticators []collector.ComponentID
+}
+
+// component converts a collector component, or reports it and returns nil if it can't be converted
+func (conv *converter) component(kind string, c collector.Component, converters map[string]componentConverter, label string) *Block {
+	converter, ok := converters[c.ID.Type]
+	if !ok {
+		conv.reporter.AddWarning(fmt.Sprintf("Component %s > %s on config.yaml can't be converted to Alloy, so it is left out. Add the equivalent otelcol component by hand", kind, c.ID))
+		return nil
+	}
+	s := &source{conv: conv, path: []string{kind, c.ID.String()}, config: c.Config}
+	b := &Block{Name: converter.name, Label: label}
+	converter.convert(s, &b.Body)
+	for _, path := range s.unused() {
+		conv.reporter.AddWarning(fmt.Sprintf("Value of %s on config.yaml is not converted to Alloy. Set the equivalent argument of %s by hand", path, b.ID()))
+	}
+	return b
+}
+
+// connect adds the output blocks that send the data of each signal to the next components of the pipelines
+func (conv *converter) connect() {
+	outputs := map[*Block]map[string][]Expr{}
+	addOutput := func(from *Block, signal string, to []*Block) {
+		if outputs[from] == nil {
+			outputs[from] = map[string][]Expr{}
+		}
+		for _, b := range to {
+			ref := reference(append(strings.Split(b.Name, "."), b.Label, "input")...)
+			if !slices.ContainsFunc(outputs[from][signal], func(e Expr) bool { return formatExpr(e, 0) == formatExpr(ref, 0) }) {
+				outputs[from][signal] = append(outputs[from][signal], ref)
+			}
+		}
+	}
+
+	for _, p := range conv.config.Pipelines {
+		var stages [][]*Block
+		stages = append(stages, conv.stage(collector.KindReceiver, p.Receivers))
+		for _, id := range p.Processors {
+			if b, ok := conv.blocks[processorKey(id, p.ID.Name)]; ok {
+				stages = append(stages, []*Block{b})
+			}
+		}
+		stages = append(stages, conv.stage(collector.KindExporter, p.Exporters))
+		for i := 0; i < len(stages)-1; i++ {
+			for _, from := range stages[i] {
+				addOutput(from, p.Signal(), stages[i+1])
+			}
+		}
+	}
+
+	for b, signalOutputs := range outputs {
+		output := &Block{Name: "output"}
+		for _, signal := range []string{"metrics", "logs", "traces"} {
+			if refs := signalOutputs[signal]; len(refs) > 0 {
+				output.Attributes = append(output.Attributes, &Attribute{Name: signal, Value: &Array{Elements: refs}})
+			}
+		}
+		if len(output.Attributes) > 0 {
+			b.Blocks = append(b.Blocks, output)
+		}
+	}
+}
+
+// stage returns the converted components of a pipeline stage
+func (conv *converter) stage(kind string, ids []collector.ComponentID) []*Block {
+	var blocks []*Block
+	for _, id := range ids {
+		if b, ok := conv.blocks[kind+"/"+id.String()]; ok {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks
+}
+
+// processorGroups returns the names of the pipelines a processor is used in, e.g. "" for traces and metrics and
+// "backend" for traces/backend
+func processorGroups(c *collector.Config, id collector.ComponentID) []string {
+	var groups []string
+	for _, p := range c.Pipelines {
+		if slices.Contains(p.Processors, id) && !slices.Contains(groups, p.ID.Name) {
+			groups = append(groups, p.ID.Name)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+func processorKey(id collector.ComponentID, group string) string {
+	return collector.KindProcessor + "/" + id.String() + "@" + group
+}
+
+// connectorsOf returns the connectors used by the pipelines
+func connectorsOf(c *collector.Config) []collector.ComponentID {
+	var ids []collector.ComponentID
+	for _, connector := range c.Connectors {
+		if slices.ContainsFunc(c.Pipelines, func(p collector.Pipeline) bool {
+			return slices.Contains(p.Receivers, connector.ID) || slices.Contains(p.Exporters, connector.ID)
+		}) {
+			ids = append(ids, connector.ID)
+		}
+	}
+	return ids
+}
+
+// labelOf returns the label of the Alloy component of a collector component, e.g. grafana for otlphttp/grafana
+func labelOf(id collector.ComponentID) string {
+	if id.Name == "" {
+		return "default"
+	}
+	return sanitizeLabel(id.Name)
+}
+
+// sanitizeLabel replaces the characters that are not allowed in the label of an Alloy component
+func sanitizeLabel(s string) string {
+	label := []byte(s)
+	for i, c := range label {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			label[i] = '_'
+		}
+	}
+	if len(label) > 0 && label[0] >= '0' && label[0] <= '9' {
+		return "_" + string(label)
+	}
+	return string(label)
+}
+
+// reference returns the expression of a reference such as otelcol.processor.batch.default.input
+func reference(path ...string) Expr {
+	var e Expr = &Identifier{Name: path[0]}
+	for _, name := range path[1:] {
+		e = &Access{X: e, Name: name}
+	}
+	return e
+}
+
+// source is the config of a collector component being converted. It records the settings that are converted, so
+// that the others can be reported.
+type source struct {
+	conv   *converter
+	path   []string
+	config map[string]any
+	taken  [][]string
+}
+
+// value returns the value at the path of keys and marks it as converted
+func (s *source) value(path ...string) (any, bool) {
+	v, ok := s.peek(path...)
+	if ok {
+		s.taken = append(s.taken, path)
+	}
+	return v, ok
+}
+
+// peek returns the value at the path of keys without marking it as converted
+func (s *source) peek(path ...string) (any, bool) {
+	return collector.Component{Config: s.config}.Value(path...)
+}
+
+// has returns whether the path of keys exists, without marking it as converted
+func (s *source) has(path ...string) bool {
+	_, ok := s.peek(path...)
+	return ok
+}
+
+// attribute converts the value at the path of keys to an attribute of the body, if it is set
+func (s *source) attribute(body *Body, name string, path ...string) {
+	if v, ok := s.value(path...); ok && v != nil {
+		body.Attributes = append(body.Attributes, &Attribute{Name: name, Value: s.expr(v, path)})
+	}
+}
+
+// unused returns the locations of the settings that are not converted. Keys without a value, such as grpc in
+// protocols > grpc, are considered converted, since they carry no settings.
+func (s *source) unused() []string {
+	var result []string
+	var walk func(v any, path []string)
+	walk = func(v any, path []string) {
+		if slices.ContainsFunc(s.taken, func(taken []string) bool {
+			return len(taken) <= len(path) && slices.Equal(taken, path[:len(taken)])
+		}) {
+			return
+		}
+		m, isMap := v.(map[string]any)
+		switch {
+		case v == nil || isMap && len(m) == 0:
+		case isMap:
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(m[k], append(slices.Clone(path), k))
+			}
+		default:
+			result = append(result, strings.Join(append(slices.Clone(s.path), path...), " > "))
+		}
+	}
+	if s.config != nil {
+		walk(s.config, nil)
+	}
+	return result
+}
+
+// expr converts a value of the collector config to an Alloy expression
+func (s *source) expr(v any, path []string) Expr {
+	switch value := v.(type) {
+	case string:
+		return s.stringExpr(value, path)
+	case int:
+		return &Literal{Value: float64(value)}
+	case float64, bool:
+		return &Literal{Value: value}
+	case []any:
+		a := &Array{}
+		for i, element := range value {
+			a.Elements = append(a.Elements, s.expr(element, append(slices.Clone(path), fmt.Sprint(i))))
+		}
+		return a
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		o := &Object{}
+		for _, k := range keys {
+			o.Fields = append(o.Fields, ObjectField{Key: k, Value: s.expr(value[k], append(slices.Clone(path), k))})
+		}
+		return o
+	}
+	return &Literal{Value: v}
+}
+
+// stringExpr converts a string with ${env:...} references to sys.env calls, concatenated with the rest of the string
+func (s *source) stringExpr(value string, path []string) Expr {
+	var parts []Expr
+	var literal strings.Builder
+	last := 0
+	for _, m := range collector.ReferencePattern.FindAllStringSubmatchIndex(value, -1) {
+		literal.WriteString(value[last:m[0]])
+		last = m[1]
+		ref := value[m[0]:m[1]]
+		if ref == "$$" {
+			literal.WriteString("$")
+			continue
+		}
+		scheme, rest, hasScheme := strings.Cut(value[m[2]:m[3]], ":")
+		if !hasScheme || !collector.SchemePattern.MatchString(scheme) {
+			scheme, rest = "env", value[m[2]:m[3]]
+		}
+		if scheme != "env" {
+			s.conv.reporter.AddWarning(fmt.Sprintf("Value of %s on config.yaml uses %s, which can't be converted to Alloy. Read the value with a local.file component or sys.env by hand", strings.Join(append(slices.Clone(s.path), path...), " > "), ref))
+			literal.WriteString(ref)
+			continue
+		}
+		if literal.Len() > 0 {
+			parts = append(parts, &Literal{Value: literal.String()})
+			literal.Reset()
+		}
+		name, fallback, hasFallback := strings.Cut(rest, ":-")
+		var e Expr = &Call{Func: reference("sys", "env"), Args: []Expr{&Literal{Value: name}}}
+		if hasFallback {
+			e = &Call{Func: &Identifier{Name: "coalesce"}, Args: []Expr{e, &Literal{Value: fallback}}}
+		}
+		parts = append(parts, e)
+	}
+	literal.WriteString(value[last:])
+	if literal.Len() > 0 || len(parts) == 0 {
+		parts = append(parts, &Literal{Value: literal.String()})
+	}
+
+	result := parts[0]
+	for _, part := range parts[1:] {
+		result = &Binary{Op: "+", X: result, Y: part}
+	}
+	return result
+}
+
+// convertAttributes converts the settings with a scalar or list value to attributes of the same name, and settings
+// with a map value to blocks of the same name, which is how most arguments of Alloy components mirror the collector
+func convertAttributes(s *source, body *Body) {
+	convertMap(s, body, nil)
+}
+
+func convertMap(s *source, body *Body, path []string) {
+	v, _ := s.peek(path...)
+	m, _ := v.(map[string]any)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		child := append(slices.Clone(path), k)
+		if nested, ok := m[k].(map[string]any); ok {
+			block := &Block{Name: k}
+			if len(nested) == 0 {
+				s.value(child...)
+			}
+			convertMap(s, &block.Body, child)
+			body.Blocks = append(body.Blocks, block)
+			continue
+		}
+		s.attribute(body, k, child...)
+	}
+}
+
+func convertOTLPReceiver(s *source, body *Body) {
+	for _, protocol := range []string{"grpc", "http"} {
+		if !s.has("protocols", protocol) {
+			continue
+		}
+		block := &Block{Name: protocol}
+		s.attribute(&block.Body, "endpoint", "protocols", protocol, "endpoint")
+		body.Blocks = append(body.Blocks, block)
+	}
+}
+
+// convertMemoryLimiter converts the limits in MiB to the limit and spike_limit sizes of Alloy
+func convertMemoryLimiter(s *source, body *Body) {
+	s.attribute(body, "check_interval", "check_interval")
+	for _, limit := range []string{"limit", "spike_limit"} {
+		if v, ok := s.value(limit + "_mib"); ok {
+			body.Attributes = append(body.Attributes, &Attribute{Name: limit, Value: &Literal{Value: fmt.Sprintf("%vMiB", v)}})
+		}
+	}
+	s.attribute(body, "limit_percentage", "limit_percentage")
+	s.attribute(body, "spike_limit_percentage", "spike_limit_percentage")
+}
+
+func convertAttributesProcessor(s *source, body *Body) {
+	actions, _ := s.value("actions")
+	list, _ := actions.([]any)
+	for i, action := range list {
+		m, _ := action.(map[string]any)
+		block := &Block{Name: "action"}
+		for _, key := range []string{"key", "value", "pattern", "from_attribute", "from_context", "converted_type", "action"} {
+			if v, ok := m[key]; ok {
+				block.Attributes = append(block.Attributes, &Attribute{Name: key, Value: s.expr(v, []string{"actions", fmt.Sprint(i), key})})
+			}
+		}
+		body.Blocks = append(body.Blocks, block)
+	}
+}
+
+func convertTransformProcessor(s *source, body *Body) {
+	s.attribute(body, "error_mode", "error_mode")
+	for _, name := range []string{"trace_statements", "metric_statements", "log_statements"} {
+		statements, _ := s.peek(name)
+		list, _ := statements.([]any)
+		converted := true
+		var blocks []*Block
+		for i, group := range list {
+			m, ok := group.(map[string]any)
+			if !ok {
+				// the basic configuration with a list of statements has no equivalent in Alloy
+				converted = false
+				break
+			}
+			block := &Block{Name: name}
+			for _, key := range []string{"context", "conditions", "statements"} {
+				if v, ok := m[key]; ok {
+					block.Attributes = append(block.Attributes, &Attribute{Name: key, Value: s.expr(v, []string{name, fmt.Sprint(i), key})})
+				}
+			}
+			blocks = append(blocks, block)
+		}
+		if converted && len(list) > 0 {
+			s.value(name)
+			body.Blocks = append(body.Blocks, blocks...)
+		}
+	}
+}
+
+func convertOTLPExporter(s *source, body *Body) {
+	convertExporterClient(s, body, false)
+}
+
+func convertOTLPHTTPExporter(s *source, body *Body) {
+	convertExporterClient(s, body, true)
+}
+
+// convertExporterClient converts the settings of the otlp and otlphttp exporters. Alloy has the timeout of
+// otelcol.exporter.otlphttp in the client block and the timeout of otelcol.exporter.otlp outside of it.
+func convertExporterClient(s *source, body *Body, timeoutInClient bool) {
+	client := &Block{Name: "client"}
+	settings := exporterClientSettings
+	if timeoutInClient {
+		settings = append(slices.Clone(settings), "timeout")
+	} else {
+		s.attribute(body, "timeout", "timeout")
+	}
+	for _, setting := range settings {
+		switch setting {
+		case "auth":
+			authenticator, ok := s.value("auth", "authenticator")
+			if !ok {
+				continue
+			}
+			id, err := collector.ParseComponentID(fmt.Sprint(authenticator))
+			if err != nil {
+				continue
+			}
+			converter, ok := extensionConverters[id.Type]
+			if !ok {
+				s.conv.reporter.AddWarning(fmt.Sprintf("Extension %s on config.yaml can't be converted to Alloy, so %s > auth is left out. Add the equivalent otelcol.auth component by hand", id, strings.Join(s.path, " > ")))
+				continue
+			}
+			if !slices.Contains(s.conv.authenticators, id) {
+				s.conv.authenticators = append(s.conv.authenticators, id)
+			}
+			client.Attributes = append(client.Attributes, &Attribute{Name: "auth", Value: reference(append(strings.Split(converter.name, "."), labelOf(id), "handler")...)})
+		case "headers":
+			s.attribute(&client.Body, "headers", "headers")
+		default:
+			if v, ok := s.peek(setting); ok {
+				if _, isMap := v.(map[string]any); isMap {
+					block := &Block{Name: setting}
+					convertMap(s, &block.Body, []string{setting})
+					client.Blocks = append(client.Blocks, block)
+					continue
+				}
+			}
+			s.attribute(&client.Body, setting, setting)
+		}
+	}
+	body.Blocks = append(body.Blocks, client)
+
+	for _, setting := range []string{"traces_endpoint", "metrics_endpoint", "logs_endpoint", "encoding"} {
+		s.attribute(body, setting, setting)
+	}
+	for _, setting := range []string{"sending_queue", "retry_on_failure"} {
+		if s.has(setting) {
+			block := &Block{Name: setting}
+			convertMap(s, &block.Body, []string{setting})
+			body.Blocks = append(body.Blocks, block)
+		}
+	}
+}
+
+func convertBasicAuth(s *source, body *Body) {
+	s.attribute(body, "username", "client_auth", "username")
+	s.attribute(body, "password", "client_auth", "password")
+}
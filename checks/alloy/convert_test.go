@@ -0,0 +1,359 @@
+package alloy
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/collector"
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const grafanaCloudCollectorConfig = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+      http:
+        endpoint: 0.0.0.0:4318
+
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_mib: 512
+  batch:
+    timeout: 5s
+
+exporters:
+  otlphttp/grafana-cloud:
+    endpoint: ${env:GRAFANA_CLOUD_OTLP_ENDPOINT:-https://otlp-gateway-prod-us-east-0.grafana.net/otlp}
+    auth:
+      authenticator: basicauth/grafana-cloud
+    headers:
+      X-Scope-OrgID: tenant-${env:TENANT}
+    retry_on_failure:
+      enabled: true
+      max_elapsed_time: 5m
+
+extensions:
+  basicauth/grafana-cloud:
+    client_auth:
+      username: ${env:GRAFANA_CLOUD_INSTANCE_ID}
+      password: ${GRAFANA_CLOUD_API_KEY}
+
+service:
+  extensions: [basicauth/grafana-cloud]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp/grafana-cloud]
+    metrics:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp/grafana-cloud]
+`
+
+func TestConvertCollectorConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		expected         string
+		expectedWarnings []string
+	}{
+		{
+			name:   "Grafana Cloud pipeline",
+			config: grafanaCloudCollectorConfig,
+			expected: `otelcol.receiver.otlp "default" {
+  grpc { }
+
+  http {
+    endpoint = "0.0.0.0:4318"
+  }
+
+  output {
+    metrics = [otelcol.processor.memory_limiter.default.input]
+    traces  = [otelcol.processor.memory_limiter.default.input]
+  }
+}
+
+otelcol.processor.batch "default" {
+  timeout = "5s"
+
+  output {
+    metrics = [otelcol.exporter.otlphttp.grafana_cloud.input]
+    traces  = [otelcol.exporter.otlphttp.grafana_cloud.input]
+  }
+}
+
+otelcol.processor.memory_limiter "default" {
+  check_interval = "1s"
+  limit          = "512MiB"
+
+  output {
+    metrics = [otelcol.processor.batch.default.input]
+    traces  = [otelcol.processor.batch.default.input]
+  }
+}
+
+otelcol.exporter.otlphttp "grafana_cloud" {
+  client {
+    endpoint = coalesce(sys.env("GRAFANA_CLOUD_OTLP_ENDPOINT"), "https://otlp-gateway-prod-us-east-0.grafana.net/otlp")
+    headers  = {
+      "X-Scope-OrgID" = "tenant-" + sys.env("TENANT"),
+    }
+    auth = otelcol.auth.basic.grafana_cloud.handler
+  }
+
+  retry_on_failure {
+    enabled          = true
+    max_elapsed_time = "5m"
+  }
+}
+
+otelcol.auth.basic "grafana_cloud" {
+  username = sys.env("GRAFANA_CLOUD_INSTANCE_ID")
+  password = sys.env("GRAFANA_CLOUD_API_KEY")
+}
+`,
+		},
+		{
+			name: "constructs without an equivalent",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      http:
+        tls:
+          cert_file: /certs/cert.pem
+  kafka:
+    brokers: [localhost:9092]
+
+processors:
+  resource:
+    attributes:
+      - key: deployment.environment.name
+        value: prod
+        action: upsert
+  transform:
+    trace_statements:
+      - set(span.name, "checkout")
+
+exporters:
+  otlp:
+    endpoint: tempo:4317
+    timeout: 10s
+    tls:
+      insecure: true
+    headers:
+      Authorization: ${file:/run/secrets/token}
+  otlphttp:
+    endpoint: http://localhost:4318
+    auth:
+      authenticator: oauth2client
+
+extensions:
+  health_check:
+  oauth2client:
+    client_id: app
+
+connectors:
+  spanmetrics:
+
+service:
+  extensions: [health_check, oauth2client]
+  telemetry:
+    logs:
+      level: debug
+  pipelines:
+    traces:
+      receivers: [otlp, kafka]
+      processors: [transform, resource]
+      exporters: [otlp, spanmetrics]
+    metrics:
+      receivers: [spanmetrics]
+      exporters: [otlphttp]
+`,
+			expected: `otelcol.receiver.otlp "default" {
+  http { }
+
+  output {
+    traces = [otelcol.processor.transform.default.input]
+  }
+}
+
+otelcol.processor.transform "default" {
+  output {
+    traces = [otelcol.exporter.otlp.default.input]
+  }
+}
+
+otelcol.exporter.otlp "default" {
+  timeout = "10s"
+
+  client {
+    endpoint = "tempo:4317"
+    headers  = {
+      Authorization = "${file:/run/secrets/token}",
+    }
+
+    tls {
+      insecure = true
+    }
+  }
+}
+
+otelcol.exporter.otlphttp "default" {
+  client {
+    endpoint = "http://localhost:4318"
+  }
+}
+`,
+			expectedWarnings: []string{
+				"alloy: Value of receivers > otlp > protocols > http > tls > cert_file on config.yaml is not converted to Alloy. Set the equivalent argument of otelcol.receiver.otlp.default by hand",
+				"alloy: Component receivers > kafka on config.yaml can't be converted to Alloy, so it is left out. Add the equivalent otelcol component by hand",
+				"alloy: Component processors > resource on config.yaml can't be converted to Alloy, so it is left out. Add the equivalent otelcol component by hand",
+				"alloy: Value of processors > transform > trace_statements on config.yaml is not converted to Alloy. Set the equivalent argument of otelcol.processor.transform.default by hand",
+				"alloy: Value of exporters > otlp > headers > Authorization on config.yaml uses ${file:/run/secrets/token}, which can't be converted to Alloy. Read the value with a local.file component or sys.env by hand",
+				"alloy: Extension oauth2client on config.yaml can't be converted to Alloy, so exporters > otlphttp > auth is left out. Add the equivalent otelcol.auth component by hand",
+				"alloy: Extension health_check on config.yaml is not converted to Alloy, which has no equivalent component or provides it itself, e.g. health and metrics on its HTTP server",
+				"alloy: Extension oauth2client on config.yaml is not converted to Alloy, which has no equivalent component or provides it itself, e.g. health and metrics on its HTTP server",
+				"alloy: Connector spanmetrics on config.yaml can't be converted to Alloy, so the pipelines it connects are not connected. Add the equivalent otelcol.connector component by hand",
+				"alloy: Value of service > telemetry on config.yaml is not converted to Alloy. Configure the logging block and the HTTP server of Alloy instead",
+			},
+		},
+		{
+			name: "processor shared by pipelines with different names",
+			config: `
+receivers:
+  otlp:
+    protocols:
+      http:
+processors:
+  batch:
+  attributes/mask:
+    actions:
+      - key: user.email
+        action: hash
+exporters:
+  otlphttp/backend:
+    endpoint: http://backend:4318
+  debug:
+    verbosity: detailed
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [attributes/mask, batch]
+      exporters: [otlphttp/backend]
+    traces/debug:
+      receivers: [otlp]
+      processors: [attributes/mask, batch]
+      exporters: [debug]
+`,
+			expected: `otelcol.receiver.otlp "default" {
+  http { }
+
+  output {
+    traces = [otelcol.processor.attributes.mask.input, otelcol.processor.attributes.mask_debug.input]
+  }
+}
+
+otelcol.processor.attributes "mask" {
+  action {
+    key    = "user.email"
+    action = "hash"
+  }
+
+  output {
+    traces = [otelcol.processor.batch.default.input]
+  }
+}
+
+otelcol.processor.attributes "mask_debug" {
+  action {
+    key    = "user.email"
+    action = "hash"
+  }
+
+  output {
+    traces = [otelcol.processor.batch.debug.input]
+  }
+}
+
+otelcol.processor.batch "default" {
+  output {
+    traces = [otelcol.exporter.otlphttp.backend.input]
+  }
+}
+
+otelcol.processor.batch "debug" {
+  output {
+    traces = [otelcol.exporter.debug.default.input]
+  }
+}
+
+otelcol.exporter.debug "default" {
+  verbosity = "detailed"
+}
+
+otelcol.exporter.otlphttp "backend" {
+  client {
+    endpoint = "http://backend:4318"
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := collector.ParseConfig([]byte(tt.config))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			r := reporter.Component("alloy")
+			file := ConvertCollectorConfig(r, c)
+
+			assert.Equal(t, tt.expected, string(Format(file)))
+			assert.ElementsMatch(t, tt.expectedWarnings, r.Warnings, "warnings mismatch")
+			assert.Empty(t, r.Errors)
+			_, err = ParseConfig(Format(file))
+			assert.NoError(t, err, "the converted config can be parsed")
+		})
+	}
+}
+
+func TestConvertCollector(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(grafanaCloudCollectorConfig), 0644))
+	t.Setenv("GRAFANA_CLOUD_INSTANCE_ID", "123456")
+	t.Setenv("GRAFANA_CLOUD_API_KEY", "glc_token")
+	t.Setenv("GRAFANA_CLOUD_OTLP_ENDPOINT", "")
+	output := filepath.Join(dir, "config.alloy")
+
+	reporter := utils.Reporter{}
+	r := reporter.Component("alloy")
+	var out bytes.Buffer
+	ConvertCollector(r, utils.Commands{CollectorConfigPath: dir, AlloyOutputPath: output, Target: target.Default()}, &out)
+
+	assert.Empty(t, out.String(), "the config is written to the output file")
+	converted, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(converted), `otelcol.exporter.otlphttp "grafana_cloud" {`)
+
+	assert.Empty(t, r.Errors)
+	assert.Empty(t, r.Warnings)
+	assert.ElementsMatch(t, []string{
+		"alloy: Converted 5 components of the collector config to Alloy",
+		"alloy: Converted the collector config to " + output,
+		"alloy: The metrics of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.grafana_cloud",
+		"alloy: The traces of otelcol.receiver.otlp.default on config.alloy are batched and exported by otelcol.exporter.otlphttp.grafana_cloud",
+		"alloy: Value of otelcol.auth.basic.grafana_cloud > username on config.alloy is set",
+		"alloy: Value of otelcol.auth.basic.grafana_cloud > password on config.alloy is set",
+	}, r.Checks)
+}
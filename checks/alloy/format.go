@@ -0,0 +1,130 @@
+package alloy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Format prints a file in the style of alloy fmt: blocks are separated by an empty line, attributes come before the
+// nested blocks of a body and the '=' of consecutive attributes is aligned
+func Format(f *File) []byte {
+	var b strings.Builder
+	formatBody(&b, f.Body, 0)
+	return []byte(b.String())
+}
+
+func formatBody(b *strings.Builder, body Body, depth int) {
+	indent := strings.Repeat("  ", depth)
+	values := make([]string, len(body.Attributes))
+	for i, a := range body.Attributes {
+		values[i] = formatExpr(a.Value, depth)
+	}
+	// an attribute with a value over several lines ends the group of attributes that are aligned
+	for start := 0; start < len(body.Attributes); {
+		end, width := start, 0
+		for end < len(body.Attributes) {
+			width = max(width, len(body.Attributes[end].Name))
+			end++
+			if strings.Contains(values[end-1], "\n") {
+				break
+			}
+		}
+		for i := start; i < end; i++ {
+			fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, body.Attributes[i].Name, values[i])
+		}
+		start = end
+	}
+	for i, block := range body.Blocks {
+		if i > 0 || len(body.Attributes) > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(indent + block.Name)
+		if block.Label != "" {
+			b.WriteString(" " + strconv.Quote(block.Label))
+		}
+		if len(block.Attributes) == 0 && len(block.Blocks) == 0 {
+			b.WriteString(" { }\n")
+			continue
+		}
+		b.WriteString(" {\n")
+		formatBody(b, block.Body, depth+1)
+		b.WriteString(indent + "}\n")
+	}
+}
+
+func formatExpr(e Expr, depth int) string {
+	switch e := e.(type) {
+	case *Literal:
+		switch v := e.Value.(type) {
+		case nil:
+			return "null"
+		case string:
+			return strconv.Quote(v)
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	case *Identifier:
+		return e.Name
+	case *Access:
+		return formatExpr(e.X, depth) + "." + e.Name
+	case *Index:
+		return formatExpr(e.X, depth) + "[" + formatExpr(e.Index, depth) + "]"
+	case *Call:
+		args := make([]string, len(e.Args))
+		for i, arg := range e.Args {
+			args[i] = formatExpr(arg, depth)
+		}
+		return formatExpr(e.Func, depth) + "(" + strings.Join(args, ", ") + ")"
+	case *Array:
+		elements := make([]string, len(e.Elements))
+		for i, element := range e.Elements {
+			elements[i] = formatExpr(element, depth)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Object:
+		if len(e.Fields) == 0 {
+			return "{}"
+		}
+		indent := strings.Repeat("  ", depth+1)
+		var b strings.Builder
+		b.WriteString("{\n")
+		for _, field := range e.Fields {
+			key := field.Key
+			if !identifierPattern.MatchString(key) {
+				key = strconv.Quote(key)
+			}
+			fmt.Fprintf(&b, "%s%s = %s,\n", indent, key, formatExpr(field.Value, depth+1))
+		}
+		b.WriteString(strings.Repeat("  ", depth) + "}")
+		return b.String()
+	case *Binary:
+		return formatOperand(e.X, e.Op, depth, false) + " " + e.Op + " " + formatOperand(e.Y, e.Op, depth, true)
+	case *Unary:
+		return e.Op + formatOperand(e.X, "", depth, false)
+	}
+	return ""
+}
+
+// formatOperand puts an operand of a binary or unary expression in parentheses if its operator binds less tightly
+func formatOperand(e Expr, op string, depth int, right bool) string {
+	s := formatExpr(e, depth)
+	binary, ok := e.(*Binary)
+	if !ok {
+		return s
+	}
+	if op == "" || precedence(binary.Op) < precedence(op) || right && precedence(binary.Op) == precedence(op) {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func precedence(op string) int {
+	return slices.IndexFunc(binaryPrecedence, func(ops []string) bool { return slices.Contains(ops, op) })
+}
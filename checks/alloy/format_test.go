@@ -0,0 +1,57 @@
+package alloy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	src := `
+logging { level = "info" }
+otelcol.exporter.otlphttp "default" {
+  client {
+    endpoint = "http://" + (sys.env("HOST") + ":4318")
+    headers = { "X-Scope-OrgID" = "tenant", retries = -3, enabled = !false }
+    compression = "gzip"
+  }
+  sending_queue { }
+  retry_on_failure {
+    max_elapsed_time = "5m"
+    multiplier = 1.5 * (2 - 1)
+  }
+}
+`
+	expected := `logging {
+  level = "info"
+}
+
+otelcol.exporter.otlphttp "default" {
+  client {
+    endpoint = "http://" + (sys.env("HOST") + ":4318")
+    headers  = {
+      "X-Scope-OrgID" = "tenant",
+      retries = -3,
+      enabled = !false,
+    }
+    compression = "gzip"
+  }
+
+  sending_queue { }
+
+  retry_on_failure {
+    max_elapsed_time = "5m"
+    multiplier       = 1.5 * (2 - 1)
+  }
+}
+`
+	f, err := Parse([]byte(src))
+	require.NoError(t, err)
+	formatted := Format(f)
+	assert.Equal(t, expected, string(formatted))
+
+	again, err := Parse(formatted)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(Format(again)), "formatting is stable")
+}
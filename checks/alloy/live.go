@@ -0,0 +1,153 @@
+package alloy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/network"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// componentsPath is the path of the components API of the HTTP server of Alloy, which the Alloy UI uses as well
+const componentsPath = "/api/v0/web/components"
+
+// liveComponent is a component returned by the components API
+type liveComponent struct {
+	ModuleID string `json:"moduleID"`
+	LocalID  string `json:"localID"`
+	Name     string `json:"name"`
+	Health   struct {
+		// State is unknown, healthy, unhealthy or exited
+		State   string `json:"state"`
+		Message string `json:"message"`
+	} `json:"health"`
+	// Arguments are only returned by the API of a single component
+	Arguments []argumentField `json:"arguments"`
+}
+
+// argumentField is an attribute or a block of the evaluated arguments of a component
+type argumentField struct {
+	Name string `json:"name"`
+	// Type is attr or block
+	Type  string `json:"type"`
+	Value *struct {
+		Type  string `json:"type"`
+		Value any    `json:"value"`
+	} `json:"value"`
+	Body []argumentField `json:"body"`
+}
+
+func (c liveComponent) ID() string {
+	if c.ModuleID != "" {
+		return c.ModuleID + "/" + c.LocalID
+	}
+	return c.LocalID
+}
+
+// field returns the attribute or block with the name
+func field(fields []argumentField, name string) (argumentField, bool) {
+	for _, f := range fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return argumentField{}, false
+}
+
+// checkRunningAlloy checks the otelcol components of the Alloy running at alloyURL through its HTTP API
+func checkRunningAlloy(reporter *utils.ComponentReporter, alloyURL string, getenv func(string) string) {
+	if !strings.Contains(alloyURL, "://") {
+		alloyURL = "http://" + alloyURL
+	}
+	u, err := url.Parse(alloyURL)
+	if err != nil || u.Host == "" {
+		reporter.AddError(fmt.Sprintf("Value of -alloy-url %s is not a valid URL. Use the address of the HTTP server of Alloy, e.g. http://localhost:12345", alloyURL))
+		return
+	}
+	probeAlloy(reporter, network.NewClient(nil, 5*time.Second), strings.TrimSuffix(u.String(), "/"), getenv)
+}
+
+func probeAlloy(reporter *utils.ComponentReporter, client *http.Client, alloyURL string, getenv func(string) string) {
+	var components []liveComponent
+	if err := getJSON(client, alloyURL+componentsPath, &components); err != nil {
+		reporter.AddError(fmt.Sprintf("Could not get the components of Alloy at %s: %s. Make sure Alloy is running and -alloy-url points to its HTTP server, which listens on 127.0.0.1:12345 by default", alloyURL, err))
+		return
+	}
+
+	healthy := 0
+	var receivers []env.OTLPReceiver
+	for _, c := range components {
+		if !strings.HasPrefix(c.Name, "otelcol.") {
+			continue
+		}
+		switch c.Health.State {
+		case "healthy":
+			healthy++
+		case "unknown":
+			reporter.AddWarning(fmt.Sprintf("Component %s of Alloy at %s has not reported its health yet. Check again once Alloy has started", c.ID(), alloyURL))
+		default:
+			if strings.HasPrefix(c.Health.Message, "component evaluation failed") {
+				reporter.AddError(fmt.Sprintf("Component %s of Alloy at %s has an evaluation error: %s. Fix its arguments on config.alloy", c.ID(), alloyURL, c.Health.Message))
+			} else {
+				reporter.AddError(fmt.Sprintf("Component %s of Alloy at %s is %s: %s. Check the logs of Alloy for the reason", c.ID(), alloyURL, c.Health.State, c.Health.Message))
+			}
+		}
+		if c.Name == "otelcol.receiver.otlp" {
+			rs, err := receiverServers(client, alloyURL, c)
+			if err != nil {
+				reporter.AddWarning(fmt.Sprintf("Could not get the arguments of %s of Alloy at %s: %s", c.ID(), alloyURL, err))
+				continue
+			}
+			receivers = append(receivers, rs...)
+		}
+	}
+	if healthy > 0 {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("%d otelcol components of Alloy at %s are healthy", healthy, alloyURL))
+	}
+
+	env.CheckSDKReceivers(reporter, receivers, "otelcol.receiver.otlp of Alloy at "+alloyURL, fmt.Sprintf("Alloy at %s runs no otelcol.receiver.otlp component", alloyURL), getenv)
+}
+
+// receiverServers returns the servers an otelcol.receiver.otlp component starts according to its evaluated arguments
+func receiverServers(client *http.Client, alloyURL string, c liveComponent) ([]env.OTLPReceiver, error) {
+	var detail liveComponent
+	if err := getJSON(client, alloyURL+componentsPath+"/"+url.PathEscape(c.ID()), &detail); err != nil {
+		return nil, err
+	}
+	var result []env.OTLPReceiver
+	for _, transport := range []string{"grpc", "http"} {
+		block, ok := field(detail.Arguments, transport)
+		if !ok || block.Type != "block" {
+			continue
+		}
+		port := "4318"
+		if transport == "grpc" {
+			port = "4317"
+		}
+		if endpoint, ok := field(block.Body, "endpoint"); ok && endpoint.Value != nil {
+			if _, p, err := net.SplitHostPort(fmt.Sprint(endpoint.Value.Value)); err == nil && p != "" {
+				port = p
+			}
+		}
+		result = append(result, env.OTLPReceiver{Name: fmt.Sprintf("%s of Alloy at %s", c.ID(), alloyURL), Transport: transport, Port: port})
+	}
+	return result, nil
+}
+
+func getJSON(client *http.Client, u string, v any) error {
+	resp, err := client.Get(u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,132 @@
+package alloy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// alloyServer serves the components API of Alloy from the responses recorded in testdata
+func alloyServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc(componentsPath, func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/components.json")
+	})
+	mux.HandleFunc(componentsPath+"/otelcol.receiver.otlp.default", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/otelcol.receiver.otlp.default.json")
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProbeAlloy(t *testing.T) {
+	componentErrors := []string{
+		"alloy: Component otelcol.exporter.otlphttp.grafana_cloud of Alloy at URL has an evaluation error: component evaluation failed: decoding configuration: client: endpoint: must not be empty. Fix its arguments on config.alloy",
+		"alloy: Component otelcol.processor.memory_limiter.default of Alloy at URL is unhealthy: component shut down with error: failed to get total memory. Check the logs of Alloy for the reason",
+	}
+	healthyCheck := "alloy: 3 otelcol components of Alloy at URL are healthy"
+
+	tests := []struct {
+		name           string
+		env            map[string]string
+		expectedErrors []string
+		expectedChecks []string
+	}{
+		{
+			name:           "SDK not configured",
+			expectedErrors: componentErrors,
+			expectedChecks: []string{healthyCheck},
+		},
+		{
+			name:           "SDK sends to the grpc receiver",
+			env:            map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://alloy:4317", "OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"},
+			expectedErrors: componentErrors,
+			expectedChecks: []string{
+				healthyCheck,
+				"alloy: The SDK sends OTLP over grpc to port 4317, where otelcol.receiver.otlp.default of Alloy at URL receives it",
+			},
+		},
+		{
+			name: "SDK sends to the default http port",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf"},
+			expectedErrors: append(componentErrors,
+				"alloy: The SDK sends OTLP over http to port 4318, but no otelcol.receiver.otlp of Alloy at URL listens on it. Configured ports: 4317 (grpc), 4319 (http)",
+			),
+			expectedChecks: []string{healthyCheck},
+		},
+		{
+			name: "SDK sends http to the grpc port",
+			env:  map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4317"},
+			expectedErrors: append(componentErrors,
+				"alloy: The SDK sends OTLP over http to port 4317, but otelcol.receiver.otlp.default of Alloy at URL receives OTLP over grpc on this port. Set OTEL_EXPORTER_OTLP_PROTOCOL to grpc or send to the port that receives OTLP over http",
+			),
+			expectedChecks: []string{healthyCheck},
+		},
+		{
+			name:           "SDK sends to Grafana Cloud directly",
+			env:            map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net/otlp"},
+			expectedErrors: componentErrors,
+			expectedChecks: []string{healthyCheck},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := alloyServer(t)
+			getenv := func(name string) string { return tt.env[name] }
+
+			reporter := utils.Reporter{}
+			c := reporter.Component("alloy")
+			probeAlloy(c, server.Client(), server.URL, getenv)
+
+			replaceURL(c.Errors, server.URL)
+			replaceURL(c.Checks, server.URL)
+			assert.ElementsMatch(t, tt.expectedErrors, c.Errors, "errors mismatch")
+			assert.Empty(t, c.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, c.Checks, "checks mismatch")
+		})
+	}
+}
+
+func TestProbeAlloyWithoutReceivers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"localID":"otelcol.exporter.otlphttp.default","name":"otelcol.exporter.otlphttp","health":{"state":"unknown"}}]`))
+	}))
+	defer server.Close()
+	getenv := func(name string) string {
+		return map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"}[name]
+	}
+
+	reporter := utils.Reporter{}
+	c := reporter.Component("alloy")
+	probeAlloy(c, server.Client(), server.URL, getenv)
+
+	replaceURL(c.Errors, server.URL)
+	replaceURL(c.Warnings, server.URL)
+	assert.Equal(t, []string{"alloy: The SDK sends OTLP over http to port 4318, but Alloy at URL runs no otelcol.receiver.otlp component. Add one that receives OTLP over http"}, c.Errors)
+	assert.Equal(t, []string{"alloy: Component otelcol.exporter.otlphttp.default of Alloy at URL has not reported its health yet. Check again once Alloy has started"}, c.Warnings)
+	assert.Empty(t, c.Checks)
+}
+
+func TestProbeAlloyNotRunning(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	reporter := utils.Reporter{}
+	c := reporter.Component("alloy")
+	checkRunningAlloy(c, server.URL, func(string) string { return "" })
+
+	assert.Len(t, c.Errors, 1)
+	assert.Contains(t, c.Errors[0], "Could not get the components of Alloy at "+server.URL)
+}
+
+func replaceURL(messages []string, url string) {
+	for i := range messages {
+		messages[i] = strings.ReplaceAll(messages[i], url, "URL")
+	}
+}
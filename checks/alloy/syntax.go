@@ -0,0 +1,530 @@
+package alloy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// File is a parsed Alloy configuration file
+type File struct {
+	Body
+}
+
+// Body is the content of a file or a block: attributes and nested blocks in the order they are defined
+type Body struct {
+	Attributes []*Attribute
+	Blocks     []*Block
+}
+
+// Block is a block such as `otelcol.receiver.otlp "default" { ... }`. Components are blocks with a label.
+type Block struct {
+	// Name is the dotted name, e.g. otelcol.receiver.otlp
+	Name string
+	// Label is empty for blocks without a label, e.g. `output { ... }`
+	Label string
+	Body
+	Line int
+}
+
+// Attribute is an assignment such as `endpoint = sys.env("OTLP_ENDPOINT")`
+type Attribute struct {
+	Name  string
+	Value Expr
+	Line  int
+}
+
+// Expr is an expression of the Alloy syntax: *Literal, *Identifier, *Access, *Index, *Call, *Array, *Object, *Binary
+// or *Unary
+type Expr interface{}
+
+// Literal is a string, a number (float64), a bool or null (nil)
+type Literal struct {
+	Value any
+}
+
+// Identifier is a name, e.g. the first part of a reference such as otelcol.processor.batch.default.input
+type Identifier struct {
+	Name string
+}
+
+// Access is a field access such as `.input`
+type Access struct {
+	X    Expr
+	Name string
+}
+
+type Index struct {
+	X     Expr
+	Index Expr
+}
+
+// Call is a function call such as sys.env("GRAFANA_CLOUD_API_KEY")
+type Call struct {
+	Func Expr
+	Args []Expr
+}
+
+type Array struct {
+	Elements []Expr
+}
+
+type ObjectField struct {
+	Key   string
+	Value Expr
+}
+
+type Object struct {
+	Fields []ObjectField
+}
+
+type Binary struct {
+	Op   string
+	X, Y Expr
+}
+
+type Unary struct {
+	Op string
+	X  Expr
+}
+
+// Attribute returns the attribute with the name, or nil
+func (b Body) Attribute(name string) *Attribute {
+	for _, a := range b.Attributes {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+// Block returns the first nested block with the name, or nil
+func (b Body) Block(name string) *Block {
+	for _, block := range b.Blocks {
+		if block.Name == name {
+			return block
+		}
+	}
+	return nil
+}
+
+// ID returns the name and label of a component, e.g. otelcol.receiver.otlp.default
+func (b *Block) ID() string {
+	if b.Label == "" {
+		return b.Name
+	}
+	return b.Name + "." + b.Label
+}
+
+// ReferencePath returns the parts of a reference such as otelcol.processor.batch.default.input
+func ReferencePath(e Expr) ([]string, bool) {
+	switch e := e.(type) {
+	case *Identifier:
+		return []string{e.Name}, true
+	case *Access:
+		path, ok := ReferencePath(e.X)
+		if !ok {
+			return nil, false
+		}
+		return append(path, e.Name), true
+	}
+	return nil, false
+}
+
+// FunctionName returns the name of the function of a call, e.g. sys.env
+func FunctionName(c *Call) string {
+	path, _ := ReferencePath(c.Func)
+	return strings.Join(path, ".")
+}
+
+// Parse parses an Alloy configuration file
+func Parse(data []byte) (*File, error) {
+	tokens, err := lex(string(data))
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	body, err := p.body("")
+	if err != nil {
+		return nil, err
+	}
+	return &File{Body: body}, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenPunct
+)
+
+type token struct {
+	kind tokenKind
+	// text is the source of the token, or the unquoted value of a string
+	text      string
+	line, col int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of file"
+	case tokenString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("'%s'", t.text)
+}
+
+var punctuation = []string{"==", "!=", "<=", ">=", "&&", "||", "{", "}", "[", "]", "(", ")", ",", ".", "=", "<", ">", "+", "-", "*", "/", "%", "^", "!"}
+
+func lex(src string) ([]token, error) {
+	var tokens []token
+	line, col := 1, 1
+	i := 0
+	advance := func(n int) {
+		for _, r := range src[i : i+n] {
+			if r == '\n' {
+				line++
+				col = 1
+			} else {
+				col++
+			}
+		}
+		i += n
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			advance(1)
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			advance(end)
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("%d:%d: comment is not terminated", line, col)
+			}
+			advance(end + 4)
+		case c == '"':
+			end := i + 1
+			for end < len(src) && src[end] != '"' && src[end] != '\n' {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) || src[end] != '"' {
+				return nil, fmt.Errorf("%d:%d: string is not terminated", line, col)
+			}
+			value, err := strconv.Unquote(src[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("%d:%d: invalid string %s: %w", line, col, src[i:end+1], err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, line: line, col: col})
+			advance(end + 1 - i)
+		case c == '`':
+			end := strings.IndexByte(src[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("%d:%d: raw string is not terminated", line, col)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[i+1 : i+1+end], line: line, col: col})
+			advance(end + 2)
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(src) && (isDigit(src[end]) || src[end] == '.' || src[end] == 'e' || src[end] == 'E' ||
+				(src[end] == '+' || src[end] == '-') && (src[end-1] == 'e' || src[end-1] == 'E')) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:end], line: line, col: col})
+			advance(end - i)
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := i
+			for end < len(src) && (src[end] == '_' || isDigit(src[end]) || unicode.IsLetter(rune(src[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[i:end], line: line, col: col})
+			advance(end - i)
+		default:
+			found := false
+			for _, p := range punctuation {
+				if strings.HasPrefix(src[i:], p) {
+					tokens = append(tokens, token{kind: tokenPunct, text: p, line: line, col: col})
+					advance(len(p))
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%d:%d: unexpected character %q", line, col, c)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, line: line, col: col}), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isPunct(text string) bool {
+	t := p.peek()
+	return t.kind == tokenPunct && t.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if t := p.next(); t.kind != tokenPunct || t.text != text {
+		return p.errorf(t, "expected '%s', found %s", text, t)
+	}
+	return nil
+}
+
+func (p *parser) errorf(t token, format string, args ...any) error {
+	return fmt.Errorf("%d:%d: %s", t.line, t.col, fmt.Sprintf(format, args...))
+}
+
+// body parses attributes and blocks until the closing brace, or the end of the file for the top level
+func (p *parser) body(end string) (Body, error) {
+	var b Body
+	for {
+		t := p.peek()
+		if end == "" && t.kind == tokenEOF || end != "" && p.isPunct(end) {
+			p.next()
+			return b, nil
+		}
+		if t.kind != tokenIdent {
+			return b, p.errorf(t, "expected an attribute or a block, found %s", t)
+		}
+		p.next()
+		name := t.text
+		for p.isPunct(".") {
+			p.next()
+			part := p.next()
+			if part.kind != tokenIdent {
+				return b, p.errorf(part, "expected a name after '.', found %s", part)
+			}
+			name += "." + part.text
+		}
+
+		if p.isPunct("=") {
+			if strings.Contains(name, ".") {
+				return b, p.errorf(t, "attribute name %s must not contain '.'", name)
+			}
+			p.next()
+			value, err := p.expr()
+			if err != nil {
+				return b, err
+			}
+			b.Attributes = append(b.Attributes, &Attribute{Name: name, Value: value, Line: t.line})
+			continue
+		}
+
+		block := &Block{Name: name, Line: t.line}
+		if label := p.peek(); label.kind == tokenString {
+			p.next()
+			block.Label = label.text
+		}
+		if err := p.expect("{"); err != nil {
+			return b, err
+		}
+		inner, err := p.body("}")
+		if err != nil {
+			return b, err
+		}
+		block.Body = inner
+		b.Blocks = append(b.Blocks, block)
+	}
+}
+
+// binaryPrecedence lists the binary operators from the lowest to the highest precedence
+var binaryPrecedence = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+	{"^"},
+}
+
+func (p *parser) expr() (Expr, error) {
+	return p.binary(0)
+}
+
+func (p *parser) binary(level int) (Expr, error) {
+	if level == len(binaryPrecedence) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokenPunct || !slices.Contains(binaryPrecedence[level], t.text) {
+			return x, nil
+		}
+		p.next()
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: t.text, X: x, Y: y}
+	}
+}
+
+func (p *parser) unary() (Expr, error) {
+	if p.isPunct("!") || p.isPunct("-") {
+		op := p.next().text
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: op, X: x}, nil
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (Expr, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isPunct("."):
+			p.next()
+			name := p.next()
+			if name.kind != tokenIdent {
+				return nil, p.errorf(name, "expected a name after '.', found %s", name)
+			}
+			x = &Access{X: x, Name: name.text}
+		case p.isPunct("["):
+			p.next()
+			index, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &Index{X: x, Index: index}
+		case p.isPunct("("):
+			p.next()
+			args, err := p.list(")")
+			if err != nil {
+				return nil, err
+			}
+			x = &Call{Func: x, Args: args}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *parser) primary() (Expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return &Literal{Value: t.text}, nil
+	case tokenNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorf(t, "invalid number %s", t.text)
+		}
+		return &Literal{Value: n}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &Literal{Value: true}, nil
+		case "false":
+			return &Literal{Value: false}, nil
+		case "null":
+			return &Literal{Value: nil}, nil
+		}
+		return &Identifier{Name: t.text}, nil
+	case tokenPunct:
+		switch t.text {
+		case "(":
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		case "[":
+			elements, err := p.list("]")
+			if err != nil {
+				return nil, err
+			}
+			return &Array{Elements: elements}, nil
+		case "{":
+			return p.object()
+		}
+	}
+	return nil, p.errorf(t, "expected an expression, found %s", t)
+}
+
+// list parses comma separated expressions until the closing token, allowing a trailing comma
+func (p *parser) list(end string) ([]Expr, error) {
+	var elements []Expr
+	for !p.isPunct(end) {
+		e, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, e)
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return elements, p.expect(end)
+}
+
+func (p *parser) object() (Expr, error) {
+	o := &Object{}
+	for !p.isPunct("}") {
+		key := p.next()
+		if key.kind != tokenIdent && key.kind != tokenString {
+			return nil, p.errorf(key, "expected a field name, found %s", key)
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		value, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		o.Fields = append(o.Fields, ObjectField{Key: key.text, Value: value})
+		if !p.isPunct(",") {
+			break
+		}
+		p.next()
+	}
+	return o, p.expect("}")
+}
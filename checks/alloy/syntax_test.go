@@ -0,0 +1,118 @@
+package alloy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	src := `
+// receives from the applications
+otelcol.receiver.otlp "default" {
+  http { }
+  grpc {
+    endpoint = "0.0.0.0:4317"
+  }
+
+  output {
+    traces  = [otelcol.processor.batch.default.input]
+    metrics = [otelcol.processor.batch.default.input,]
+  }
+}
+
+/* credentials of
+   Grafana Cloud */
+otelcol.auth.basic "grafana" {
+  username = sys.env("GRAFANA_CLOUD_INSTANCE_ID")
+  password = ` + "`raw\\token`" + `
+}
+
+logging {
+  level = "info"
+}
+
+local.file "labels" {
+  filename = "/etc/labels.json"
+  is_secret = !false && 1 + 2 * 3 >= 7
+}
+
+prometheus.relabel "default" {
+  rule {
+    replace = json_decode(local.file.labels.content)["job"]
+    labels  = { "service.name" = "shop", env = -1.5e3 }
+  }
+}
+`
+	f, err := Parse([]byte(src))
+	require.NoError(t, err)
+	require.Len(t, f.Blocks, 5)
+	assert.Empty(t, f.Attributes)
+
+	receiver := f.Blocks[0]
+	assert.Equal(t, "otelcol.receiver.otlp", receiver.Name)
+	assert.Equal(t, "default", receiver.Label)
+	assert.Equal(t, "otelcol.receiver.otlp.default", receiver.ID())
+	assert.Equal(t, 3, receiver.Line)
+	assert.NotNil(t, receiver.Block("http"))
+	assert.Equal(t, &Literal{Value: "0.0.0.0:4317"}, receiver.Block("grpc").Attribute("endpoint").Value)
+
+	traces := receiver.Block("output").Attribute("traces").Value.(*Array)
+	path, ok := ReferencePath(traces.Elements[0])
+	require.True(t, ok)
+	assert.Equal(t, []string{"otelcol", "processor", "batch", "default", "input"}, path)
+	assert.Len(t, receiver.Block("output").Attribute("metrics").Value.(*Array).Elements, 1)
+
+	auth := f.Blocks[1]
+	call := auth.Attribute("username").Value.(*Call)
+	assert.Equal(t, "sys.env", FunctionName(call))
+	assert.Equal(t, []Expr{&Literal{Value: "GRAFANA_CLOUD_INSTANCE_ID"}}, call.Args)
+	assert.Equal(t, &Literal{Value: `raw\token`}, auth.Attribute("password").Value)
+
+	logging := f.Blocks[2]
+	assert.Equal(t, "logging", logging.ID())
+
+	file := f.Blocks[3]
+	assert.Equal(t, &Binary{
+		Op: "&&",
+		X:  &Unary{Op: "!", X: &Literal{Value: false}},
+		Y: &Binary{
+			Op: ">=",
+			X:  &Binary{Op: "+", X: &Literal{Value: 1.0}, Y: &Binary{Op: "*", X: &Literal{Value: 2.0}, Y: &Literal{Value: 3.0}}},
+			Y:  &Literal{Value: 7.0},
+		},
+	}, file.Attribute("is_secret").Value)
+
+	rule := f.Blocks[4].Block("rule")
+	replace := rule.Attribute("replace").Value.(*Index)
+	assert.Equal(t, &Literal{Value: "job"}, replace.Index)
+	assert.Equal(t, "json_decode", FunctionName(replace.X.(*Call)))
+	assert.Equal(t, &Object{Fields: []ObjectField{
+		{Key: "service.name", Value: &Literal{Value: "shop"}},
+		{Key: "env", Value: &Unary{Op: "-", X: &Literal{Value: 1500.0}}},
+	}}, rule.Attribute("labels").Value)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{src: "otelcol.receiver.otlp \"default\" {\n  http {}\n", expected: "3:1: expected an attribute or a block, found end of file"},
+		{src: "a.b = 1", expected: "1:1: attribute name a.b must not contain '.'"},
+		{src: "x = \"unterminated\n", expected: "1:5: string is not terminated"},
+		{src: "x = [1, 2", expected: "1:10: expected ']', found end of file"},
+		{src: "block {\n  x = \n}", expected: "3:1: expected an expression, found '}'"},
+		{src: "x = 1 @", expected: "1:7: unexpected character '@'"},
+		{src: "/* open", expected: "1:1: comment is not terminated"},
+		{src: "block \"label\" x", expected: "1:15: expected '{', found 'x'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			_, err := Parse([]byte(tt.src))
+			require.Error(t, err)
+			assert.Equal(t, tt.expected, err.Error())
+		})
+	}
+}
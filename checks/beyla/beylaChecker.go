@@ -1,7 +1,14 @@
 package beyla
 
 import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
 	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/target"
 	"github.com/grafana/otel-checker/checks/utils"
 )
 
@@ -37,8 +44,80 @@ var (
 	}
 )
 
-func CheckBeylaSetup(reporter *utils.ComponentReporter, language string) {
-	CheckEnvVars(reporter, language)
+// selectorKeys are the keys of an entry of discovery > services or discovery > instrument that select processes.
+// Keys starting with k8s_ select processes as well.
+var selectorKeys = []string{"open_ports", "exe_path", "cmd_args", "languages"}
+
+// unmatchedModes are the values of routes > unmatched
+var unmatchedModes = []string{"unset", "path", "wildcard", "heuristic"}
+
+// envSetting is a setting of the config file that Beyla also reads from an environment variable, which takes
+// precedence
+type envSetting struct {
+	path []string
+	env  string
+}
+
+// discoverySettings select the processes to instrument without a discovery section
+var discoverySettings = []envSetting{
+	{path: []string{"open_port"}, env: "BEYLA_OPEN_PORT"},
+	{path: []string{"executable_name"}, env: "BEYLA_EXECUTABLE_NAME"},
+	{path: []string{"auto_target_exe"}, env: "BEYLA_AUTO_TARGET_EXE"},
+}
+
+var envSettings = append(slices.Clone(discoverySettings), []envSetting{
+	{path: []string{"service_name"}, env: "BEYLA_SERVICE_NAME"},
+	{path: []string{"otel_traces_export", "endpoint"}, env: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"},
+	{path: []string{"otel_traces_export", "protocol"}, env: "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"},
+	{path: []string{"otel_metrics_export", "endpoint"}, env: "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT"},
+	{path: []string{"otel_metrics_export", "protocol"}, env: "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"},
+	{path: []string{"attributes", "kubernetes", "enable"}, env: "BEYLA_KUBE_METADATA_ENABLE"},
+	{path: []string{"attributes", "kubernetes", "cluster_name"}, env: "BEYLA_KUBE_CLUSTER_NAME"},
+}...)
+
+// exports are the OTLP exports of the config file and the environment variables of their endpoint and protocol
+var exports = []struct {
+	section     string
+	signal      string
+	endpointEnv string
+	protocolEnv string
+}{
+	{section: "otel_traces_export", signal: "traces", endpointEnv: "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", protocolEnv: "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"},
+	{section: "otel_metrics_export", signal: "metrics", endpointEnv: "OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", protocolEnv: "OTEL_EXPORTER_OTLP_METRICS_PROTOCOL"},
+}
+
+func CheckBeylaSetup(reporter *utils.ComponentReporter, commands utils.Commands) {
+	checkBeylaSetup(reporter, commands, os.Getenv)
+}
+
+func checkBeylaSetup(reporter *utils.ComponentReporter, commands utils.Commands, getenv func(string) string) {
+	path := ConfigPath(commands.BeylaConfigPath, getenv)
+	if path == "" {
+		CheckEnvVars(reporter, commands.Language)
+		checkProcesses(reporter, &Config{}, getenv)
+		return
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		reporter.AddError(utils.Capitalize(err.Error()))
+		CheckEnvVars(reporter, commands.Language)
+		checkProcesses(reporter, &Config{}, getenv)
+		return
+	}
+
+	// with a config file, the processes can be selected in discovery and the data sent with otel_*_export
+	openPort := OpenPort
+	openPort.Required = false
+	cloudVars := []env.EnvVar{GrafanaCloudSubmit, GrafanaCloudInstanceID, GrafanaCloudAPIKey}
+	if c.has("otel_traces_export") || c.has("otel_metrics_export") {
+		for i := range cloudVars {
+			cloudVars[i].Required = false
+		}
+	}
+	env.CheckEnvVars(reporter, commands.Language, append([]env.EnvVar{ServiceName, openPort}, cloudVars...)...)
+
+	checkConfig(reporter, c, commands.Target, getenv)
+	checkProcesses(reporter, c, getenv)
 }
 
 func CheckEnvVars(reporter *utils.ComponentReporter, language string) {
@@ -49,3 +128,274 @@ func CheckEnvVars(reporter *utils.ComponentReporter, language string) {
 		GrafanaCloudInstanceID,
 		GrafanaCloudAPIKey)
 }
+
+func checkConfig(reporter *utils.ComponentReporter, c *Config, profile target.Profile, getenv func(string) string) {
+	checkDiscovery(reporter, c, getenv)
+	checkRoutes(reporter, c)
+	checkExports(reporter, c, profile, getenv)
+	checkEnvConflicts(reporter, c, getenv)
+}
+
+// checkDiscovery checks that the processes to instrument are selected in discovery, by open_port or
+// executable_name, or by the environment variables
+func checkDiscovery(reporter *utils.ComponentReporter, c *Config, getenv func(string) string) {
+	file := c.file()
+	var selectedBy []string
+	for _, section := range []string{"services", "instrument"} {
+		entries := c.List("discovery", section)
+		selected := false
+		for i, entry := range entries {
+			m, _ := entry.(map[string]any)
+			location := entryLocation(section, i, m)
+			if hasSelector(m) {
+				selected = true
+				checkExePath(reporter, c, section, location, m)
+				continue
+			}
+			reporter.AddError(fmt.Sprintf("Value of %s on %s has no selector, so it doesn't select any process. Set open_ports, exe_path or a k8s_ attribute", location, file))
+		}
+		if selected {
+			selectedBy = append(selectedBy, "discovery > "+section)
+		}
+	}
+	for _, s := range discoverySettings {
+		if c.StringValue(s.path...) != "" {
+			selectedBy = append(selectedBy, strings.Join(s.path, " > "))
+		}
+		if getenv(s.env) != "" {
+			selectedBy = append(selectedBy, s.env)
+		}
+	}
+
+	if len(selectedBy) == 0 {
+		reporter.AddError(fmt.Sprintf("%s has no discovery selectors and BEYLA_OPEN_PORT and BEYLA_EXECUTABLE_NAME are not set, so Beyla instruments no process. Add discovery > services with open_ports or exe_path", file))
+		return
+	}
+	reporter.AddSuccessfulCheck(fmt.Sprintf("Beyla selects the processes to instrument by %s", strings.Join(selectedBy, ", ")))
+}
+
+// checkExePath checks that the exe_path of an entry of discovery is a regular expression in discovery > services or a
+// glob in discovery > instrument
+func checkExePath(reporter *utils.ComponentReporter, c *Config, section string, location string, entry map[string]any) {
+	v, ok := entry["exe_path"]
+	if !ok || v == nil {
+		return
+	}
+	value := fmt.Sprint(v)
+	if _, err := exePattern(section, value); err != nil {
+		kind := "regular expression"
+		if section == "instrument" {
+			kind = "glob"
+		}
+		reporter.AddError(fmt.Sprintf("Value of %s > exe_path on %s is set to '%s', which is not a valid %s: %v", location, c.file(), value, kind, err))
+	}
+}
+
+// exePattern compiles the exe_path of an entry of discovery, which is a regular expression in discovery > services
+// and a glob in discovery > instrument
+func exePattern(section string, value string) (*regexp.Regexp, error) {
+	if section == "instrument" {
+		return globPattern(value)
+	}
+	return regexp.Compile(value)
+}
+
+// globPattern converts a glob of Beyla to a regular expression matching the whole path. '*' matches any characters
+// including '/', '?' a single character, [...] a character class and {a,b} one of the alternatives.
+func globPattern(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			b.WriteString(".*")
+			for i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+			}
+		case '?':
+			b.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("missing closing ] of the character class at %d", i)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			braces++
+			b.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				return nil, fmt.Errorf("unexpected } at %d", i)
+			}
+			braces--
+			b.WriteString(")")
+		case ',':
+			if braces > 0 {
+				b.WriteString("|")
+			} else {
+				b.WriteString(",")
+			}
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+			}
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	if braces > 0 {
+		return nil, fmt.Errorf("missing closing }")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// entryLocation describes an entry of discovery > services or discovery > instrument by its name or index
+func entryLocation(section string, i int, entry map[string]any) string {
+	if name, ok := entry["name"]; ok {
+		return fmt.Sprintf("discovery > %s > %v", section, name)
+	}
+	return fmt.Sprintf("discovery > %s > %d", section, i)
+}
+
+func hasSelector(entry map[string]any) bool {
+	for k, v := range entry {
+		if v != nil && fmt.Sprint(v) != "" && (slices.Contains(selectorKeys, k) || strings.HasPrefix(k, "k8s_")) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRoutes checks routes > unmatched and the route patterns, which group the paths of requests into the routes
+// reported in the traces and metrics
+func checkRoutes(reporter *utils.ComponentReporter, c *Config) {
+	if !c.has("routes") {
+		return
+	}
+	file := c.file()
+	switch unmatched := c.StringValue("routes", "unmatched"); {
+	case unmatched == "":
+	case !slices.Contains(unmatchedModes, unmatched):
+		reporter.AddError(fmt.Sprintf("Value of routes > unmatched on %s is '%s'. Use one of %s", file, unmatched, strings.Join(unmatchedModes, ", ")))
+	case unmatched == "path":
+		reporter.AddWarning(fmt.Sprintf("Value of routes > unmatched on %s is path, so each path without a pattern is a route of its own, e.g. one per user id, which creates many metric series. Use heuristic or add the routes to routes > patterns", file))
+	}
+
+	valid := 0
+	for _, key := range []string{"patterns", "ignored_patterns"} {
+		var seen []string
+		for _, p := range c.List("routes", key) {
+			pattern := fmt.Sprint(p)
+			location := fmt.Sprintf("Route pattern '%s' in routes > %s on %s", pattern, key, file)
+			if slices.Contains(seen, pattern) {
+				reporter.AddWarning(location + " is defined twice")
+				continue
+			}
+			seen = append(seen, pattern)
+			if problem, isError := checkRoutePattern(pattern); problem != "" {
+				if isError {
+					reporter.AddError(location + " " + problem)
+				} else {
+					reporter.AddWarning(location + " " + problem)
+				}
+				continue
+			}
+			valid++
+		}
+	}
+	if valid > 0 {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("%d route patterns on %s are valid", valid, file))
+	}
+}
+
+// checkRoutePattern returns the problem of a route pattern such as /users/{id} or /users/:id, and whether it
+// prevents the pattern from matching
+func checkRoutePattern(pattern string) (problem string, isError bool) {
+	switch {
+	case !strings.HasPrefix(pattern, "/"):
+		return "doesn't start with '/', so it matches no path", true
+	case strings.ContainsAny(pattern, " \t"):
+		return "contains whitespace, so it matches no path", true
+	case strings.ContainsAny(pattern, "?#"):
+		return "contains a query or fragment, which is not part of the route. Remove it", false
+	case strings.ContainsAny(pattern, "()[]^$\\") || strings.Contains(pattern, ".*"):
+		return "looks like a regular expression, but Beyla matches placeholders such as /users/{id} or /users/:id", false
+	}
+
+	var placeholders []string
+	for _, segment := range strings.Split(pattern, "/") {
+		name := ""
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		case strings.Contains(segment, "{") || strings.Contains(segment, "}"):
+			if !strings.HasPrefix(segment, "{") || !strings.HasSuffix(segment, "}") || strings.Count(segment, "{") != 1 || strings.Count(segment, "}") != 1 {
+				return fmt.Sprintf("has an invalid placeholder '%s'. Use a whole path segment such as {id}", segment), true
+			}
+			name = segment[1 : len(segment)-1]
+		default:
+			continue
+		}
+		if name == "" {
+			return "has a placeholder without a name. Use a name such as {id} or :id", true
+		}
+		if slices.Contains(placeholders, name) {
+			return fmt.Sprintf("uses the placeholder %s twice", name), false
+		}
+		placeholders = append(placeholders, name)
+	}
+	return "", false
+}
+
+// checkExports checks the endpoint and protocol of the OTLP exports like the ones of the SDK. The environment
+// variables of a signal take precedence over the config file. OTEL_EXPORTER_OTLP_ENDPOINT and
+// OTEL_EXPORTER_OTLP_PROTOCOL are checked with the target. The endpoint of a signal may end with its path, e.g.
+// /v1/traces, which is left out when checking it against the target.
+func checkExports(reporter *utils.ComponentReporter, c *Config, profile target.Profile, getenv func(string) string) {
+	for _, e := range exports {
+		base := func(value string) string {
+			return strings.TrimSuffix(strings.TrimSuffix(value, "/"), "/v1/"+e.signal)
+		}
+		if value := getenv(e.endpointEnv); value != "" {
+			env.CheckEndpoint(reporter, e.endpointEnv, base(value), profile)
+		} else if value := c.StringValue(e.section, "endpoint"); value != "" {
+			env.CheckEndpoint(reporter, fmt.Sprintf("Value of %s > endpoint on %s", e.section, c.file()), base(value), profile)
+		}
+
+		if value := getenv(e.protocolEnv); value != "" {
+			env.CheckProtocol(reporter, e.protocolEnv, value, profile)
+		} else if value := c.StringValue(e.section, "protocol"); value != "" {
+			env.CheckProtocol(reporter, fmt.Sprintf("Value of %s > protocol on %s", e.section, c.file()), value, profile)
+		}
+	}
+}
+
+// checkEnvConflicts reports the settings that are set to different values in the config file and an environment
+// variable, and which of them Beyla uses
+func checkEnvConflicts(reporter *utils.ComponentReporter, c *Config, getenv func(string) string) {
+	file := c.file()
+	for _, s := range envSettings {
+		value, envValue := c.StringValue(s.path...), getenv(s.env)
+		if value == "" || envValue == "" || value == envValue {
+			continue
+		}
+		reporter.AddWarning(fmt.Sprintf("Value of %s on %s is '%s', but %s is set to '%s'. Beyla uses '%s', since the environment variable takes precedence over the config file", strings.Join(s.path, " > "), file, value, s.env, envValue, envValue))
+	}
+
+	common := getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	for _, e := range exports {
+		value := c.StringValue(e.section, "endpoint")
+		if common == "" || value == "" || getenv(e.endpointEnv) != "" || strings.TrimSuffix(value, "/v1/"+e.signal) == strings.TrimSuffix(common, "/") {
+			continue
+		}
+		reporter.AddWarning(fmt.Sprintf("OTEL_EXPORTER_OTLP_ENDPOINT is set to '%s', but Beyla sends %s to '%s' of %s > endpoint on %s, since the endpoint of a signal takes precedence", common, e.signal, value, e.section, file))
+	}
+}
@@ -7,6 +7,7 @@ import (
 )
 
 func TestCheckEnvVarsBeyla(t *testing.T) {
+	useProcRoot(t, nil)
 	tests := []utils.EnvVarTestCase{
 		{
 			Name: "beyla component with required env vars",
@@ -48,7 +49,7 @@ func TestCheckEnvVarsBeyla(t *testing.T) {
 		t.Run(tt.Name, func(t *testing.T) {
 			utils.RunEnvVarComponentTest(t, tt, "Beyla",
 				func(reporter utils.Reporter, c *utils.ComponentReporter, language string, components []string) {
-					CheckBeylaSetup(c, language)
+					CheckBeylaSetup(c, utils.Commands{Language: language})
 				})
 		})
 	}
@@ -0,0 +1,98 @@
+package beyla
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is a parsed Beyla config file. Values are kept as generic YAML values, like the collector config, since
+// Beyla accepts many settings and only some of them are checked.
+type Config struct {
+	// Path is the path of the file, used in the messages
+	Path   string
+	Values map[string]any
+}
+
+// ConfigPath returns the path of the config file of the -beyla-config-path flag, or of BEYLA_CONFIG_PATH, which
+// Beyla reads the config file from
+func ConfigPath(path string, getenv func(string) string) string {
+	if path != "" {
+		return path
+	}
+	return getenv("BEYLA_CONFIG_PATH")
+}
+
+// LoadConfig loads the Beyla config file at path
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not check file %s: %w", path, err)
+	}
+	c, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse file %s: %w", path, err)
+	}
+	c.Path = path
+	return c, nil
+}
+
+// ParseConfig parses a Beyla config file
+func ParseConfig(data []byte) (*Config, error) {
+	var values map[string]any
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+	return &Config{Values: values}, nil
+}
+
+// Value returns the value at the path of keys and whether the last key exists
+func (c *Config) Value(path ...string) (any, bool) {
+	var current any = c.Values
+	for _, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// StringValue returns the scalar at the path of keys as a string, e.g. "8080" for open_port: 8080
+func (c *Config) StringValue(path ...string) string {
+	v, ok := c.Value(path...)
+	if !ok || v == nil {
+		return ""
+	}
+	switch v.(type) {
+	case map[string]any, []any:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// List returns the list at the path of keys
+func (c *Config) List(path ...string) []any {
+	v, _ := c.Value(path...)
+	list, _ := v.([]any)
+	return list
+}
+
+func (c *Config) has(key string) bool {
+	_, ok := c.Value(key)
+	return ok
+}
+
+// file returns the name of the config file for the messages
+func (c *Config) file() string {
+	if c.Path == "" {
+		return "the Beyla config"
+	}
+	return filepath.Base(c.Path)
+}
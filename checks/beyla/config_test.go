@@ -0,0 +1,233 @@
+package beyla
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConfig(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           string
+		env              map[string]string
+		expectedChecks   []string
+		expectedWarnings []string
+		expectedErrors   []string
+	}{
+		{
+			name: "valid config",
+			config: `
+discovery:
+  services:
+    - name: checkout
+      open_ports: 8080
+routes:
+  unmatched: heuristic
+  patterns:
+    - /users/{id}
+    - /orders/:order_id/items
+otel_traces_export:
+  endpoint: https://otlp-gateway-prod-eu-west-2.grafana.net/otlp
+  protocol: http/protobuf
+`,
+			expectedChecks: []string{
+				"beyla: Beyla selects the processes to instrument by discovery > services",
+				"beyla: 2 route patterns on beyla-config.yml are valid",
+				"beyla: Value of otel_traces_export > endpoint on beyla-config.yml matches the format expected by target grafana-cloud",
+				"beyla: Value of otel_traces_export > protocol on beyla-config.yml is set to 'http/protobuf' as expected by target grafana-cloud",
+			},
+		},
+		{
+			name: "no selectors",
+			config: `
+discovery:
+  services:
+    - name: checkout
+      namespace: shop
+routes:
+  unmatched: path
+`,
+			expectedWarnings: []string{
+				"beyla: Value of routes > unmatched on beyla-config.yml is path, so each path without a pattern is a route of its own, e.g. one per user id, which creates many metric series. Use heuristic or add the routes to routes > patterns",
+			},
+			expectedErrors: []string{
+				"beyla: Value of discovery > services > checkout on beyla-config.yml has no selector, so it doesn't select any process. Set open_ports, exe_path or a k8s_ attribute",
+				"beyla: beyla-config.yml has no discovery selectors and BEYLA_OPEN_PORT and BEYLA_EXECUTABLE_NAME are not set, so Beyla instruments no process. Add discovery > services with open_ports or exe_path",
+			},
+		},
+		{
+			name: "selected by environment variable",
+			config: `
+routes:
+  unmatched: everything
+`,
+			env: map[string]string{"BEYLA_EXECUTABLE_NAME": "java"},
+			expectedChecks: []string{
+				"beyla: Beyla selects the processes to instrument by BEYLA_EXECUTABLE_NAME",
+			},
+			expectedErrors: []string{
+				"beyla: Value of routes > unmatched on beyla-config.yml is 'everything'. Use one of unset, path, wildcard, heuristic",
+			},
+		},
+		{
+			name: "invalid route patterns",
+			config: `
+executable_name: java
+routes:
+  patterns:
+    - users/{id}
+    - /users/{id}
+    - /users/{id}
+    - /users/{id}x
+    - /users/{}
+    - /users/.*
+    - /search?q={query}
+    - /a/{id}/b/{id}
+`,
+			expectedChecks: []string{
+				"beyla: Beyla selects the processes to instrument by executable_name",
+				"beyla: 1 route patterns on beyla-config.yml are valid",
+			},
+			expectedWarnings: []string{
+				"beyla: Route pattern '/users/{id}' in routes > patterns on beyla-config.yml is defined twice",
+				"beyla: Route pattern '/users/.*' in routes > patterns on beyla-config.yml looks like a regular expression, but Beyla matches placeholders such as /users/{id} or /users/:id",
+				"beyla: Route pattern '/search?q={query}' in routes > patterns on beyla-config.yml contains a query or fragment, which is not part of the route. Remove it",
+				"beyla: Route pattern '/a/{id}/b/{id}' in routes > patterns on beyla-config.yml uses the placeholder id twice",
+			},
+			expectedErrors: []string{
+				"beyla: Route pattern 'users/{id}' in routes > patterns on beyla-config.yml doesn't start with '/', so it matches no path",
+				"beyla: Route pattern '/users/{id}x' in routes > patterns on beyla-config.yml has an invalid placeholder '{id}x'. Use a whole path segment such as {id}",
+				"beyla: Route pattern '/users/{}' in routes > patterns on beyla-config.yml has a placeholder without a name. Use a name such as {id} or :id",
+			},
+		},
+		{
+			name: "exe_path is a regular expression in services and a glob in instrument",
+			config: `
+discovery:
+  services:
+    - exe_path: "*/node"
+    - exe_path: "/usr/bin/(node|deno)"
+  instrument:
+    - exe_path: "*/node"
+    - exe_path: "{/usr/bin/java,/opt/*/java"
+`,
+			expectedChecks: []string{
+				"beyla: Beyla selects the processes to instrument by discovery > services, discovery > instrument",
+			},
+			expectedErrors: []string{
+				"beyla: Value of discovery > services > 0 > exe_path on beyla-config.yml is set to '*/node', which is not a valid regular expression: error parsing regexp: missing argument to repetition operator: `*`",
+				"beyla: Value of discovery > instrument > 1 > exe_path on beyla-config.yml is set to '{/usr/bin/java,/opt/*/java', which is not a valid glob: missing closing }",
+			},
+		},
+		{
+			name: "environment variables take precedence",
+			config: `
+open_port: 8080
+otel_traces_export:
+  endpoint: http://localhost:4318
+otel_metrics_export:
+  endpoint: https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/metrics
+attributes:
+  kubernetes:
+    cluster_name: dev
+`,
+			env: map[string]string{
+				"BEYLA_OPEN_PORT":                    "9090",
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/traces",
+				"OTEL_EXPORTER_OTLP_ENDPOINT":        "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+				"BEYLA_KUBE_CLUSTER_NAME":            "prod",
+			},
+			expectedChecks: []string{
+				"beyla: Beyla selects the processes to instrument by open_port, BEYLA_OPEN_PORT",
+				"beyla: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT matches the format expected by target grafana-cloud",
+				"beyla: Value of otel_metrics_export > endpoint on beyla-config.yml matches the format expected by target grafana-cloud",
+			},
+			expectedWarnings: []string{
+				"beyla: Value of open_port on beyla-config.yml is '8080', but BEYLA_OPEN_PORT is set to '9090'. Beyla uses '9090', since the environment variable takes precedence over the config file",
+				"beyla: Value of otel_traces_export > endpoint on beyla-config.yml is 'http://localhost:4318', but OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set to 'https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/traces'. Beyla uses 'https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/traces', since the environment variable takes precedence over the config file",
+				"beyla: Value of attributes > kubernetes > cluster_name on beyla-config.yml is 'dev', but BEYLA_KUBE_CLUSTER_NAME is set to 'prod'. Beyla uses 'prod', since the environment variable takes precedence over the config file",
+				"beyla: OTEL_EXPORTER_OTLP_ENDPOINT is set to 'https://otlp-gateway-prod-us-east-0.grafana.net/otlp', but Beyla sends metrics to 'https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/metrics' of otel_metrics_export > endpoint on beyla-config.yml, since the endpoint of a signal takes precedence",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			c, err := ParseConfig([]byte(tt.config))
+			require.NoError(t, err)
+			c.Path = filepath.Join("config", "beyla-config.yml")
+
+			reporter := utils.Reporter{}
+			r := reporter.Component("beyla")
+			checkConfig(r, c, target.Default(), getenv)
+
+			assert.ElementsMatch(t, tt.expectedChecks, r.Checks, "checks mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, r.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedErrors, r.Errors, "errors mismatch")
+		})
+	}
+}
+
+func TestCheckBeylaSetupWithConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beyla-config.yml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+discovery:
+  services:
+    - exe_path: java
+otel_traces_export:
+  endpoint: https://otlp-gateway-prod-eu-west-2.grafana.net/otlp
+`), 0644))
+	for _, name := range []string{"BEYLA_SERVICE_NAME", "BEYLA_OPEN_PORT", "BEYLA_EXECUTABLE_NAME", "BEYLA_AUTO_TARGET_EXE", "OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "GRAFANA_CLOUD_SUBMIT", "GRAFANA_CLOUD_INSTANCE_ID", "GRAFANA_CLOUD_API_KEY"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("BEYLA_CONFIG_PATH", path)
+	useProcRoot(t, nil)
+
+	reporter := utils.Reporter{}
+	r := reporter.Component("Beyla")
+	CheckBeylaSetup(r, utils.Commands{Language: "java", Target: target.Default()})
+
+	assert.Empty(t, r.Errors, "BEYLA_OPEN_PORT and GRAFANA_CLOUD_* are not required with the config file")
+	assert.Contains(t, r.Checks, "Beyla: Beyla selects the processes to instrument by discovery > services")
+	assert.Contains(t, r.Checks, "Beyla: Value of otel_traces_export > endpoint on beyla-config.yml matches the format expected by target grafana-cloud")
+}
+
+func TestGlobPattern(t *testing.T) {
+	tests := []struct {
+		glob      string
+		matches   []string
+		unmatched []string
+	}{
+		{glob: "*/node", matches: []string{"/usr/bin/node", "/node"}, unmatched: []string{"/usr/bin/nodejs"}},
+		{glob: "/opt/**/java", matches: []string{"/opt/jdk/bin/java"}, unmatched: []string{"/usr/bin/java"}},
+		{glob: "/usr/bin/python3.?", matches: []string{"/usr/bin/python3.9"}, unmatched: []string{"/usr/bin/python3.12", "/usr/bin/python3x9"}},
+		{glob: "*/{node,deno}", matches: []string{"/usr/bin/node", "/usr/bin/deno"}, unmatched: []string{"/usr/bin/bun"}},
+		{glob: "*/ruby[0-9]", matches: []string{"/usr/bin/ruby3"}, unmatched: []string{"/usr/bin/ruby"}},
+		{glob: "*/app[!0-9]", matches: []string{"/srv/appx"}, unmatched: []string{"/srv/app1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.glob, func(t *testing.T) {
+			re, err := globPattern(tt.glob)
+			require.NoError(t, err)
+			for _, m := range tt.matches {
+				assert.True(t, re.MatchString(m), "%s matches %s", tt.glob, m)
+			}
+			for _, m := range tt.unmatched {
+				assert.False(t, re.MatchString(m), "%s doesn't match %s", tt.glob, m)
+			}
+		})
+	}
+
+	for _, glob := range []string{"[abc", "{a,b", "a}"} {
+		_, err := globPattern(glob)
+		assert.Error(t, err, glob)
+	}
+}
@@ -0,0 +1,335 @@
+package beyla
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// procRoot is where the processes of the host are read from. Beyla only runs on Linux, so there is nothing to scan
+// if it doesn't exist.
+var procRoot = "/proc"
+
+// manyProcesses is the number of processes a selector matches above which it probably matches more than intended
+const manyProcesses = 10
+
+// tcpListen is the state of a listening socket in /proc/net/tcp
+const tcpListen = "0A"
+
+// portRange is a range of ports of open_port, e.g. 8000-8999, or a single port
+type portRange struct {
+	from, to int
+}
+
+// processSelector selects the processes that listen on one of the ports and whose executable matches exe, like
+// open_port and executable_name or an entry of discovery. Beyla requires both if both are set.
+type processSelector struct {
+	// name describes where the selector is set, e.g. BEYLA_OPEN_PORT
+	name  string
+	ports []portRange
+	exe   *regexp.Regexp
+}
+
+func (s processSelector) matches(p process) bool {
+	if s.ports == nil && s.exe == nil {
+		return false
+	}
+	if s.ports != nil && !slices.ContainsFunc(p.ports, func(port int) bool {
+		return slices.ContainsFunc(s.ports, func(r portRange) bool { return port >= r.from && port <= r.to })
+	}) {
+		return false
+	}
+	return s.exe == nil || s.exe.MatchString(p.exe)
+}
+
+// process is a process of the host with the ports it listens on
+type process struct {
+	pid   int
+	exe   string
+	ports []int
+}
+
+func (p process) String() string {
+	return fmt.Sprintf("%d (%s)", p.pid, p.exe)
+}
+
+// parsePorts parses the syntax of open_port: ports and ranges of ports separated by ',', e.g. 80,443,8000-8999
+func parsePorts(value string) ([]portRange, error) {
+	var ranges []portRange
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		start, err := parsePort(from)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a port or a range of ports", part)
+		}
+		end, err := parsePort(to)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a port or a range of ports", part)
+		}
+		if end < start {
+			return nil, fmt.Errorf("range '%s' ends before it starts", part)
+		}
+		ranges = append(ranges, portRange{from: start, to: end})
+	}
+	return ranges, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %s", value)
+	}
+	return port, nil
+}
+
+// processSelectors returns the selectors of the environment variables, which take precedence over open_port and
+// executable_name of the config file, and of the entries of discovery. exe_path is a regular expression in
+// discovery > services and a glob in discovery > instrument. Invalid ports and regular expressions are reported.
+// checked is false if a selector uses settings that can't be matched against the processes of the host, such as k8s_
+// attributes.
+func processSelectors(reporter *utils.ComponentReporter, c *Config, getenv func(string) string) (selectors []processSelector, checked bool) {
+	checked = true
+	file := c.file()
+	var top processSelector
+	var names []string
+	ports, portsName := getenv("BEYLA_OPEN_PORT"), "BEYLA_OPEN_PORT"
+	if ports == "" && c.StringValue("open_port") != "" {
+		ports, portsName = c.StringValue("open_port"), fmt.Sprintf("Value of open_port on %s", file)
+	}
+	exe, exeName := getenv("BEYLA_EXECUTABLE_NAME"), "BEYLA_EXECUTABLE_NAME"
+	if exe == "" && c.StringValue("executable_name") != "" {
+		exe, exeName = c.StringValue("executable_name"), fmt.Sprintf("Value of executable_name on %s", file)
+	}
+	valid := true
+	if ports != "" {
+		top.ports, valid = checkPorts(reporter, portsName, ports)
+		names = append(names, strings.TrimPrefix(portsName, "Value of "))
+	}
+	if exe != "" {
+		var ok bool
+		top.exe, ok = checkExecutable(reporter, exeName, exe)
+		valid = valid && ok
+		names = append(names, strings.TrimPrefix(exeName, "Value of "))
+	}
+	if len(names) > 0 && valid {
+		top.name = strings.Join(names, " and ")
+		selectors = append(selectors, top)
+	}
+
+	for _, section := range []string{"services", "instrument"} {
+		for i, entry := range c.List("discovery", section) {
+			m, _ := entry.(map[string]any)
+			if !hasSelector(m) {
+				continue
+			}
+			location := entryLocation(section, i, m)
+			if !locallyCheckable(m) {
+				checked = false
+				continue
+			}
+			s := processSelector{name: fmt.Sprintf("%s on %s", location, file)}
+			valid := true
+			if v, ok := m["open_ports"]; ok && v != nil {
+				s.ports, valid = checkPorts(reporter, fmt.Sprintf("Value of %s > open_ports on %s", location, file), fmt.Sprint(v))
+			}
+			if v, ok := m["exe_path"]; ok && v != nil {
+				// an invalid exe_path is reported by checkDiscovery
+				exe, err := exePattern(section, fmt.Sprint(v))
+				s.exe, valid = exe, valid && err == nil
+			}
+			if valid {
+				selectors = append(selectors, s)
+			}
+		}
+	}
+	return selectors, checked
+}
+
+// locallyCheckable returns false if an entry of discovery also selects processes by settings that can't be matched
+// against the processes of the host, such as k8s_ attributes
+func locallyCheckable(entry map[string]any) bool {
+	for k, v := range entry {
+		if v != nil && (k == "cmd_args" || k == "languages" || strings.HasPrefix(k, "k8s_")) {
+			return false
+		}
+	}
+	return true
+}
+
+func checkPorts(reporter *utils.ComponentReporter, name string, value string) ([]portRange, bool) {
+	ranges, err := parsePorts(value)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("%s is set to '%s', which is not a valid list of ports: %v. Use ports and ranges separated by ',', e.g. 80,443,8000-8999", name, value, err))
+		return nil, false
+	}
+	return ranges, true
+}
+
+func checkExecutable(reporter *utils.ComponentReporter, name string, value string) (*regexp.Regexp, bool) {
+	re, err := regexp.Compile(value)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("%s is set to '%s', which is not a valid regular expression: %v", name, value, err))
+		return nil, false
+	}
+	return re, true
+}
+
+// checkProcesses reports the processes of the host each selector matches, and an error if Beyla would instrument
+// no process at all
+func checkProcesses(reporter *utils.ComponentReporter, c *Config, getenv func(string) string) {
+	selectors, checked := processSelectors(reporter, c, getenv)
+	if len(selectors) == 0 {
+		return
+	}
+	processes, unreadable, err := scanProcesses(procRoot)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		reporter.AddWarning(fmt.Sprintf("Could not read the processes of this host to check which ones Beyla instruments: %v", err))
+		return
+	}
+
+	var instrumented []int
+	var unmatched []string
+	for _, s := range selectors {
+		var matched []process
+		for _, p := range processes {
+			if s.matches(p) {
+				matched = append(matched, p)
+				if !slices.Contains(instrumented, p.pid) {
+					instrumented = append(instrumented, p.pid)
+				}
+			}
+		}
+		switch {
+		case len(matched) == 0:
+			unmatched = append(unmatched, s.name)
+		case len(matched) > manyProcesses:
+			reporter.AddWarning(fmt.Sprintf("%s selects %d processes on this host, e.g. %s. Beyla instruments all of them, so narrow it down if that is not intended", s.name, len(matched), joinProcesses(matched[:3])))
+		default:
+			reporter.AddSuccessfulCheck(fmt.Sprintf("%s selects these processes on this host: %s", s.name, joinProcesses(matched)))
+		}
+	}
+
+	switch {
+	case len(instrumented) > 0:
+		for _, name := range unmatched {
+			reporter.AddWarning(fmt.Sprintf("%s selects no process on this host", name))
+		}
+	case !checked:
+	case unreadable > 0:
+		reporter.AddWarning(fmt.Sprintf("%s selects no process on this host that otel-checker can read, and %d processes could not be read. Run otel-checker as root, like Beyla, to check them", strings.Join(unmatched, ", "), unreadable))
+	default:
+		reporter.AddError(fmt.Sprintf("%s selects no process on this host, so Beyla instruments nothing. Start the application or check the ports it listens on and the path of its executable", strings.Join(unmatched, ", ")))
+	}
+}
+
+func joinProcesses(processes []process) string {
+	names := make([]string, len(processes))
+	for i, p := range processes {
+		names[i] = p.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// scanProcesses reads the processes and their listening TCP ports from the proc file system at root. unreadable is
+// the number of processes whose executable or sockets could not be read, usually since they belong to other users.
+func scanProcesses(root string) (processes []process, unreadable int, err error) {
+	listening := map[string]int{}
+	found := false
+	for _, name := range []string{"tcp", "tcp6"} {
+		err := readListeningSockets(filepath.Join(root, "net", name), listening)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		found = true
+	}
+	if !found {
+		return nil, 0, os.ErrNotExist
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == os.Getpid() {
+			continue
+		}
+		exe, err := os.Readlink(filepath.Join(root, entry.Name(), "exe"))
+		if os.IsPermission(err) {
+			unreadable++
+			continue
+		}
+		if err != nil {
+			// kernel threads have no executable
+			continue
+		}
+		p := process{pid: pid, exe: strings.TrimSuffix(exe, " (deleted)")}
+		fds, err := os.ReadDir(filepath.Join(root, entry.Name(), "fd"))
+		if os.IsPermission(err) {
+			unreadable++
+			continue
+		}
+		for _, fd := range fds {
+			link, err := os.Readlink(filepath.Join(root, entry.Name(), "fd", fd.Name()))
+			if err != nil {
+				continue
+			}
+			inode, ok := strings.CutPrefix(link, "socket:[")
+			if !ok {
+				continue
+			}
+			if port, ok := listening[strings.TrimSuffix(inode, "]")]; ok && !slices.Contains(p.ports, port) {
+				p.ports = append(p.ports, port)
+			}
+		}
+		processes = append(processes, p)
+	}
+	slices.SortFunc(processes, func(a, b process) int { return a.pid - b.pid })
+	return processes, unreadable, nil
+}
+
+// readListeningSockets adds the inodes and ports of the listening sockets of a file such as /proc/net/tcp
+func readListeningSockets(path string, listening map[string]int) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != tcpListen {
+			continue
+		}
+		_, hexPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		port, err := strconv.ParseInt(hexPort, 16, 32)
+		if err != nil {
+			continue
+		}
+		listening[fields[9]] = int(port)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,228 @@
+package beyla
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const procNetTCP = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:1F91 00000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1002 1 0000000000000000 100 0 0 10 0
+   2: 0100007F:D431 0100007F:1F90 01 00000000:00000000 00:00000000 00000000  1000        0 1003 1 0000000000000000 100 0 0 10 0
+`
+
+const procNetTCP6 = `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:0BB8 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000  1000        0 1004 1 0000000000000000 100 0 0 10 0
+`
+
+// fakeProc is a process of a fake proc file system and the inodes of its sockets
+type fakeProc struct {
+	pid     int
+	exe     string
+	sockets []string
+}
+
+// useProcRoot makes the checks read the processes from a fake proc file system with the processes
+func useProcRoot(t *testing.T, processes []fakeProc) {
+	root := filepath.Join(t.TempDir(), "proc")
+	previous := procRoot
+	procRoot = root
+	t.Cleanup(func() { procRoot = previous })
+	if processes == nil {
+		return
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "tcp"), []byte(procNetTCP), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "net", "tcp6"), []byte(procNetTCP6), 0644))
+	for _, p := range processes {
+		dir := filepath.Join(root, strconv.Itoa(p.pid))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+		require.NoError(t, os.Symlink(p.exe, filepath.Join(dir, "exe")))
+		require.NoError(t, os.Symlink("/dev/null", filepath.Join(dir, "fd", "0")))
+		for i, inode := range p.sockets {
+			require.NoError(t, os.Symlink(fmt.Sprintf("socket:[%s]", inode), filepath.Join(dir, "fd", strconv.Itoa(i+3))))
+		}
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	tests := []struct {
+		value         string
+		expected      []portRange
+		expectedError string
+	}{
+		{value: "8080", expected: []portRange{{8080, 8080}}},
+		{value: "80, 443,8000-8999", expected: []portRange{{80, 80}, {443, 443}, {8000, 8999}}},
+		{value: "8080,", expectedError: "'' is not a port or a range of ports"},
+		{value: "http", expectedError: "'http' is not a port or a range of ports"},
+		{value: "70000", expectedError: "'70000' is not a port or a range of ports"},
+		{value: "8000-", expectedError: "'8000-' is not a port or a range of ports"},
+		{value: "9000-8000", expectedError: "range '9000-8000' ends before it starts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			ranges, err := parsePorts(tt.value)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, ranges)
+		})
+	}
+}
+
+func TestCheckProcesses(t *testing.T) {
+	processes := []fakeProc{
+		{pid: 100, exe: "/usr/lib/jvm/bin/java", sockets: []string{"1001"}},
+		{pid: 200, exe: "/usr/bin/node", sockets: []string{"1002", "1003"}},
+		{pid: 300, exe: "/usr/local/bin/checkout (deleted)", sockets: []string{"1004"}},
+		{pid: 400, exe: "/usr/bin/bash"},
+	}
+	many := []fakeProc{}
+	for pid := 1; pid <= 12; pid++ {
+		many = append(many, fakeProc{pid: pid, exe: "/usr/bin/python3"})
+	}
+
+	tests := []struct {
+		name             string
+		env              map[string]string
+		config           string
+		processes        []fakeProc
+		expectedChecks   []string
+		expectedWarnings []string
+		expectedErrors   []string
+	}{
+		{
+			name:      "open port and range",
+			env:       map[string]string{"BEYLA_OPEN_PORT": "8080,3000-3999"},
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: BEYLA_OPEN_PORT selects these processes on this host: 100 (/usr/lib/jvm/bin/java), 300 (/usr/local/bin/checkout)",
+			},
+		},
+		{
+			name:      "open port and executable name",
+			env:       map[string]string{"BEYLA_OPEN_PORT": "8000-8100", "BEYLA_EXECUTABLE_NAME": "node$"},
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: BEYLA_OPEN_PORT and BEYLA_EXECUTABLE_NAME selects these processes on this host: 200 (/usr/bin/node)",
+			},
+		},
+		{
+			name:      "executable name",
+			env:       map[string]string{"BEYLA_EXECUTABLE_NAME": "node$"},
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: BEYLA_EXECUTABLE_NAME selects these processes on this host: 200 (/usr/bin/node)",
+			},
+		},
+		{
+			name:      "nothing instrumented",
+			env:       map[string]string{"BEYLA_OPEN_PORT": "9090"},
+			processes: processes,
+			expectedErrors: []string{
+				"beyla: BEYLA_OPEN_PORT selects no process on this host, so Beyla instruments nothing. Start the application or check the ports it listens on and the path of its executable",
+			},
+		},
+		{
+			name:      "many processes",
+			env:       map[string]string{"BEYLA_EXECUTABLE_NAME": "python"},
+			processes: many,
+			expectedWarnings: []string{
+				"beyla: BEYLA_EXECUTABLE_NAME selects 12 processes on this host, e.g. 1 (/usr/bin/python3), 2 (/usr/bin/python3), 3 (/usr/bin/python3). Beyla instruments all of them, so narrow it down if that is not intended",
+			},
+		},
+		{
+			name: "invalid values",
+			env:  map[string]string{"BEYLA_OPEN_PORT": "80-http", "BEYLA_EXECUTABLE_NAME": "java("},
+			config: `
+discovery:
+  services:
+    - name: checkout
+      open_ports: 3000
+`,
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: discovery > services > checkout on beyla-config.yml selects these processes on this host: 300 (/usr/local/bin/checkout)",
+			},
+			expectedErrors: []string{
+				"beyla: BEYLA_OPEN_PORT is set to '80-http', which is not a valid list of ports: '80-http' is not a port or a range of ports. Use ports and ranges separated by ',', e.g. 80,443,8000-8999",
+				"beyla: BEYLA_EXECUTABLE_NAME is set to 'java(', which is not a valid regular expression: error parsing regexp: missing closing ): `java(`",
+			},
+		},
+		{
+			name: "config file",
+			config: `
+executable_name: bash
+discovery:
+  services:
+    - exe_path: ruby
+    - open_ports: 8081
+      k8s_namespace: shop
+`,
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: executable_name on beyla-config.yml selects these processes on this host: 400 (/usr/bin/bash)",
+			},
+			expectedWarnings: []string{
+				"beyla: discovery > services > 0 on beyla-config.yml selects no process on this host",
+			},
+		},
+		{
+			name: "glob of discovery > instrument",
+			config: `
+discovery:
+  instrument:
+    - exe_path: "*/node"
+    - exe_path: "/usr/{lib,local}/**"
+`,
+			processes: processes,
+			expectedChecks: []string{
+				"beyla: discovery > instrument > 0 on beyla-config.yml selects these processes on this host: 200 (/usr/bin/node)",
+				"beyla: discovery > instrument > 1 on beyla-config.yml selects these processes on this host: 100 (/usr/lib/jvm/bin/java), 300 (/usr/local/bin/checkout)",
+			},
+		},
+		{
+			name: "kubernetes selectors are not checked",
+			config: `
+discovery:
+  services:
+    - open_ports: 9090
+      k8s_namespace: shop
+`,
+			processes: processes,
+		},
+		{
+			name: "no proc file system",
+			env:  map[string]string{"BEYLA_OPEN_PORT": "9090"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			useProcRoot(t, tt.processes)
+			c, err := ParseConfig([]byte(tt.config))
+			require.NoError(t, err)
+			c.Path = "beyla-config.yml"
+
+			reporter := utils.Reporter{}
+			r := reporter.Component("beyla")
+			checkProcesses(r, c, getenv)
+
+			assert.ElementsMatch(t, tt.expectedChecks, r.Checks, "checks mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, r.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedErrors, r.Errors, "errors mismatch")
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package checks
 
 import (
+	"os"
+	"slices"
+
 	"github.com/grafana/otel-checker/checks/alloy"
 	"github.com/grafana/otel-checker/checks/beyla"
 	"github.com/grafana/otel-checker/checks/collector"
@@ -17,23 +20,28 @@ import (
 
 func RunAllChecks(commands utils.Commands) map[string][]string {
 	reporter := utils.Reporter{}
+	if !commands.ShowSecrets {
+		reporter.Redactor = utils.NewRedactor(env.SecretValues()...)
+	}
 
-	env.CheckCommon(reporter.Component("Common Environment Variables"), commands.Language)
+	env.CheckCommon(reporter.Component("Common Environment Variables"), commands)
+
+	// the SDK exporter is checked against the target if the SDK is checked or -target is passed. The grafana-cloud
+	// component checks the exporter of Grafana Cloud in more detail.
+	if (commands.TargetSet || slices.Contains(commands.Components, "sdk")) && !slices.Contains(commands.Components, "grafana-cloud") {
+		env.CheckTarget(reporter.Component("Target"), commands.Target)
+	}
 
 	for _, c := range commands.Components {
 		switch c {
 		case "sdk":
 			SDKSetup(reporter.Component("SDK"), commands)
 		case "beyla":
-			beyla.CheckBeylaSetup(reporter.Component("Beyla"), commands.Language)
+			beyla.CheckBeylaSetup(reporter.Component("Beyla"), commands)
 		case "alloy":
-			alloy.CheckAlloySetup(reporter.Component("Alloy"), commands.Language)
+			alloy.CheckAlloySetup(reporter.Component("Alloy"), commands)
 		case "collector":
-			collector.CheckCollectorSetup(
-				reporter.Component("Collector"),
-				commands.Language,
-				commands.CollectorConfigPath,
-			)
+			collector.CheckCollectorSetup(reporter.Component("Collector"), commands)
 		case "grafana-cloud":
 			grafana.CheckGrafanaSetup(reporter, reporter.Component("Grafana Cloud"), commands)
 		}
@@ -42,6 +50,20 @@ func RunAllChecks(commands utils.Commands) map[string][]string {
 	return reporter.PrintResults()
 }
 
+// ConvertCollector converts the collector config to an Alloy config and checks the result
+func ConvertCollector(commands utils.Commands) map[string][]string {
+	reporter := utils.Reporter{}
+	if !commands.ShowSecrets {
+		reporter.Redactor = utils.NewRedactor(env.SecretValues()...)
+	}
+	// the results go to stderr if the config is printed, so that the output can be redirected to a file
+	if commands.AlloyOutputPath == "" {
+		reporter.Out = os.Stderr
+	}
+	alloy.ConvertCollector(reporter.Component("Alloy"), commands, os.Stdout)
+	return reporter.PrintResults()
+}
+
 func SDKSetup(reporter *utils.ComponentReporter, commands utils.Commands) {
 	switch commands.Language {
 	case "dotnet":
@@ -0,0 +1,245 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// appObservabilityAttributes are the resource attributes Grafana Application Observability relies on, with what they
+// are used for
+var appObservabilityAttributes = []struct {
+	name    string
+	purpose string
+}{
+	{name: "service.name", purpose: "identify services"},
+	{name: "service.namespace", purpose: "group services"},
+	{name: "deployment.environment.name", purpose: "filter by environment"},
+}
+
+// recommendedDimensions are the dimensions Application Observability expects on span metrics and service graphs
+var recommendedDimensions = []string{"service.namespace", "service.version", "deployment.environment.name", "k8s.cluster.name"}
+
+var (
+	spanMetricsRule = processorRule{
+		docs:    "https://grafana.com/docs/grafana-cloud/monitor-applications/application-observability/setup/collector/opentelemetry-collector/",
+		snippet: "connectors: {spanmetrics: {namespace: traces.span.metrics, dimensions: [{name: service.namespace}, {name: service.version}, {name: deployment.environment.name}, {name: k8s.cluster.name}]}, servicegraph: {dimensions: [service.namespace, service.version, deployment.environment.name, k8s.cluster.name]}}",
+	}
+	resourceAttributesRule = processorRule{
+		docs: "https://grafana.com/docs/grafana-cloud/monitor-applications/application-observability/setup/resource-attributes/",
+	}
+)
+
+var (
+	transformDeleteKey      = regexp.MustCompile(`delete_key\(\s*(resource\.)?attributes\s*,\s*"([^"]+)"\s*\)`)
+	transformDeleteMatching = regexp.MustCompile(`delete_matching_keys\(\s*(resource\.)?attributes\s*,\s*"([^"]+)"\s*\)`)
+	transformKeepKeys       = regexp.MustCompile(`keep_keys\(\s*(resource\.)?attributes\s*,\s*\[([^\]]*)\]\s*\)`)
+)
+
+// checkApplicationObservability checks that a config that sends to Grafana Cloud generates span metrics and service
+// graphs for Application Observability, and that processors don't drop the resource attributes it relies on
+func checkApplicationObservability(reporter *utils.ComponentReporter, c *Config) {
+	if !slices.ContainsFunc(c.UsedExporters(), func(e Component) bool {
+		return strings.Contains(e.StringValue("endpoint"), "grafana.net")
+	}) {
+		return
+	}
+	checkSpanMetrics(reporter, c)
+	checkDroppedAttributes(reporter, c)
+}
+
+// checkSpanMetrics checks that a traces pipeline exports to a spanmetrics, servicegraph or grafanacloud connector and
+// that the connectors have the recommended dimensions
+func checkSpanMetrics(reporter *utils.ComponentReporter, c *Config) {
+	traces := c.PipelinesFor("traces")
+	if len(traces) == 0 {
+		return
+	}
+
+	var connectors []Component
+	for _, p := range traces {
+		for _, id := range p.Exporters {
+			cn, ok := c.Component(KindConnector, id)
+			if ok && slices.Contains([]string{"spanmetrics", "servicegraph", "grafanacloud"}, id.Type) && !slices.ContainsFunc(connectors, func(other Component) bool { return other.ID == id }) {
+				connectors = append(connectors, cn)
+			}
+		}
+	}
+	if len(connectors) == 0 {
+		reporter.AddWarning(spanMetricsRule.message(fmt.Sprintf("%s no spanmetrics, servicegraph or grafanacloud connector. Grafana Application Observability needs span metrics and service graphs, add the connectors or make sure Grafana Cloud generates them", pipelinesSubject(traces))))
+		return
+	}
+
+	for _, cn := range connectors {
+		var dimensions []string
+		switch cn.ID.Type {
+		case "spanmetrics":
+			dimensions = spanMetricsDimensions(cn)
+		case "servicegraph":
+			dimensions = stringList(valueOrEmpty(cn, "dimensions"))
+		default:
+			reporter.AddSuccessfulCheck(fmt.Sprintf("Connector %s on config.yaml generates the host info of Grafana Application Observability", cn.ID))
+			continue
+		}
+		var missing []string
+		for _, d := range recommendedDimensions {
+			if !slices.Contains(dimensions, d) {
+				missing = append(missing, d)
+			}
+		}
+		if len(missing) > 0 {
+			reporter.AddWarning(spanMetricsRule.message(fmt.Sprintf("Connector %s on config.yaml has no dimensions %s. Add them, so that Grafana Application Observability can group and filter the metrics", cn.ID, strings.Join(missing, ", "))))
+		} else {
+			reporter.AddSuccessfulCheck(fmt.Sprintf("Connector %s on config.yaml has the dimensions recommended for Grafana Application Observability", cn.ID))
+		}
+	}
+}
+
+// spanMetricsDimensions returns the names of the dimensions of a spanmetrics connector, which are configured as a list
+// of maps with a name
+func spanMetricsDimensions(cn Component) []string {
+	var names []string
+	list, _ := valueOrEmpty(cn, "dimensions").([]any)
+	for _, d := range list {
+		if m, ok := d.(map[string]any); ok {
+			names = append(names, fmt.Sprint(m["name"]))
+		}
+	}
+	return names
+}
+
+func stringList(v any) []string {
+	list, _ := v.([]any)
+	var result []string
+	for _, item := range list {
+		result = append(result, fmt.Sprint(item))
+	}
+	return result
+}
+
+// checkDroppedAttributes checks that the resource and transform processors used by the pipelines don't delete or hash
+// the resource attributes Application Observability relies on. The attributes processor only changes the attributes
+// of spans, data points and log records, so it can't drop them.
+func checkDroppedAttributes(reporter *utils.ComponentReporter, c *Config) {
+	var checked []ComponentID
+	for _, p := range c.Pipelines {
+		for _, id := range p.Processors {
+			processor, ok := c.Component(KindProcessor, id)
+			if !ok || slices.Contains(checked, id) {
+				continue
+			}
+			checked = append(checked, id)
+
+			var drops map[string]string
+			switch id.Type {
+			case "resource":
+				drops = attributeActionDrops(processor)
+			case "transform":
+				drops = transformDrops(processor)
+			}
+			for _, attribute := range appObservabilityAttributes {
+				how, dropped := drops[attribute.name]
+				if !dropped {
+					continue
+				}
+				message := resourceAttributesRule.message(fmt.Sprintf("Processor %s on config.yaml %s attribute %s, which Grafana Application Observability needs to %s. Remove it from the processor", id, how, attribute.name, attribute.purpose))
+				if attribute.name == "service.name" {
+					reporter.AddError(message)
+				} else {
+					reporter.AddWarning(message)
+				}
+			}
+		}
+	}
+}
+
+// attributeActionDrops returns the attributes the actions of a resource processor delete or hash, with the action
+// that drops them
+func attributeActionDrops(processor Component) map[string]string {
+	drops := map[string]string{}
+	actions, _ := valueOrEmpty(processor, "actions").([]any)
+	for _, a := range actions {
+		m, _ := a.(map[string]any)
+		action := Component{Config: m}
+		var how string
+		switch action.StringValue("action") {
+		case "delete":
+			how = "deletes"
+		case "hash":
+			how = "hashes"
+		default:
+			continue
+		}
+		if key := action.StringValue("key"); key != "" {
+			drops[key] = how
+		}
+		if pattern, err := regexp.Compile(action.StringValue("pattern")); action.StringValue("pattern") != "" && err == nil {
+			for _, attribute := range appObservabilityAttributes {
+				if pattern.MatchString(attribute.name) {
+					drops[attribute.name] = how
+				}
+			}
+		}
+	}
+	return drops
+}
+
+// transformDrops returns the resource attributes the OTTL statements of a transform processor delete. Statements are
+// either a list of strings or a list of groups with a context, where attributes refers to the resource attributes in
+// the resource context.
+func transformDrops(processor Component) map[string]string {
+	drops := map[string]string{}
+	for _, key := range []string{"trace_statements", "metric_statements", "log_statements"} {
+		groups, _ := valueOrEmpty(processor, key).([]any)
+		for _, g := range groups {
+			switch group := g.(type) {
+			case string:
+				addTransformDrops(drops, group, false)
+			case map[string]any:
+				inResource := group["context"] == "resource"
+				for _, statement := range stringList(group["statements"]) {
+					addTransformDrops(drops, statement, inResource)
+				}
+			}
+		}
+	}
+	return drops
+}
+
+func addTransformDrops(drops map[string]string, statement string, inResource bool) {
+	onResource := func(prefix string) bool { return prefix != "" || inResource }
+
+	for _, m := range transformDeleteKey.FindAllStringSubmatch(statement, -1) {
+		if onResource(m[1]) {
+			drops[m[2]] = "deletes"
+		}
+	}
+	for _, m := range transformDeleteMatching.FindAllStringSubmatch(statement, -1) {
+		pattern, err := regexp.Compile(m[2])
+		if !onResource(m[1]) || err != nil {
+			continue
+		}
+		for _, attribute := range appObservabilityAttributes {
+			if pattern.MatchString(attribute.name) {
+				drops[attribute.name] = "deletes"
+			}
+		}
+	}
+	for _, m := range transformKeepKeys.FindAllStringSubmatch(statement, -1) {
+		if !onResource(m[1]) {
+			continue
+		}
+		var kept []string
+		for _, key := range strings.Split(m[2], ",") {
+			kept = append(kept, strings.Trim(strings.TrimSpace(key), `"`))
+		}
+		for _, attribute := range appObservabilityAttributes {
+			if !slices.Contains(kept, attribute.name) {
+				drops[attribute.name] = "deletes"
+			}
+		}
+	}
+}
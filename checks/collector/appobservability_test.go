@@ -0,0 +1,165 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckApplicationObservability(t *testing.T) {
+	tests := []struct {
+		name             string
+		configYAML       string
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name: "not sent to Grafana Cloud",
+			configYAML: `
+processors:
+  resource:
+    actions:
+      - key: service.name
+        action: delete
+exporters:
+  otlphttp:
+    endpoint: http://tempo:4318
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [resource]
+      exporters: [otlphttp]
+`,
+		},
+		{
+			name: "connectors with the recommended dimensions",
+			configYAML: `
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+connectors:
+  spanmetrics:
+    dimensions:
+      - name: service.namespace
+      - name: service.version
+      - name: deployment.environment.name
+      - name: k8s.cluster.name
+  servicegraph:
+    dimensions: [service.namespace, deployment.environment.name]
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp, spanmetrics, servicegraph]
+    metrics:
+      receivers: [spanmetrics, servicegraph]
+      exporters: [otlphttp]
+`,
+			expectedWarnings: []string{
+				spanMetricsRule.message("collector: Connector servicegraph on config.yaml has no dimensions service.version, k8s.cluster.name. Add them, so that Grafana Application Observability can group and filter the metrics"),
+			},
+			expectedChecks: []string{
+				"collector: Connector spanmetrics on config.yaml has the dimensions recommended for Grafana Application Observability",
+			},
+		},
+		{
+			name: "no connectors",
+			configYAML: `
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+    traces/sampled:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+			expectedWarnings: []string{
+				spanMetricsRule.message("collector: Pipelines traces, traces/sampled on config.yaml have no spanmetrics, servicegraph or grafanacloud connector. Grafana Application Observability needs span metrics and service graphs, add the connectors or make sure Grafana Cloud generates them"),
+			},
+		},
+		{
+			name: "processors dropping resource attributes",
+			configYAML: `
+processors:
+  resource:
+    actions:
+      - key: service.name
+        action: delete
+      - key: service.version
+        action: delete
+  resource/hash:
+    actions:
+      - pattern: ^service\.namespace$
+        action: hash
+  attributes/span:
+    actions:
+      - key: service.name
+        action: delete
+  transform/environment:
+    trace_statements:
+      - context: resource
+        statements:
+          - delete_key(attributes, "deployment.environment.name")
+      - context: span
+        statements:
+          - delete_key(attributes, "service.name")
+  transform/keep:
+    log_statements:
+      - keep_keys(resource.attributes, ["service.name", "service.namespace"])
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+connectors:
+  grafanacloud:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [resource, resource/hash, attributes/span, transform/environment]
+      exporters: [otlphttp, grafanacloud]
+    metrics:
+      receivers: [grafanacloud]
+      processors: [resource]
+      exporters: [otlphttp]
+    logs:
+      receivers: [otlp]
+      processors: [transform/keep]
+      exporters: [otlphttp]
+`,
+			expectedErrors: []string{
+				resourceAttributesRule.message("collector: Processor resource on config.yaml deletes attribute service.name, which Grafana Application Observability needs to identify services. Remove it from the processor"),
+			},
+			expectedWarnings: []string{
+				resourceAttributesRule.message("collector: Processor resource/hash on config.yaml hashes attribute service.namespace, which Grafana Application Observability needs to group services. Remove it from the processor"),
+				resourceAttributesRule.message("collector: Processor transform/environment on config.yaml deletes attribute deployment.environment.name, which Grafana Application Observability needs to filter by environment. Remove it from the processor"),
+				resourceAttributesRule.message("collector: Processor transform/keep on config.yaml deletes attribute deployment.environment.name, which Grafana Application Observability needs to filter by environment. Remove it from the processor"),
+			},
+			expectedChecks: []string{
+				"collector: Connector grafanacloud on config.yaml generates the host info of Grafana Application Observability",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseConfig([]byte(tt.configYAML))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			c := reporter.Component("collector")
+			checkApplicationObservability(c, config)
+
+			assert.ElementsMatch(t, tt.expectedErrors, c.Errors)
+			assert.ElementsMatch(t, tt.expectedWarnings, c.Warnings)
+			assert.ElementsMatch(t, tt.expectedChecks, c.Checks)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package collector
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// grafanaExporters are the exporters that can send to Grafana Cloud
+var grafanaExporters = []string{"otlphttp", "otlp", "prometheusremotewrite", "loki"}
+
+var instanceIDPattern = regexp.MustCompile(`^[0-9]+$`)
+
+// checkAuthentication checks that the authenticators of the exporters are defined and enabled, that basicauth has
+// credentials and that exporters sending to Grafana Cloud authenticate
+func checkAuthentication(reporter *utils.ComponentReporter, c *Config) {
+	for _, e := range c.UsedExporters() {
+		authenticator := e.StringValue("auth", "authenticator")
+		toGrafanaCloud := slices.Contains(grafanaExporters, e.ID.Type) && strings.Contains(e.StringValue("endpoint"), "grafana.net")
+
+		if authenticator == "" {
+			_, hasAuth := e.Value("auth")
+			if toGrafanaCloud && !hasAuth && authorizationHeader(e) == "" {
+				reporter.AddError(fmt.Sprintf("Exporter %s on config.yaml sends to Grafana Cloud without authentication. Add the basicauth extension with the instance id as username and an access policy token as password, and set it in auth > authenticator", e.ID))
+			}
+			continue
+		}
+
+		id, err := ParseComponentID(authenticator)
+		if err != nil {
+			reporter.AddError(fmt.Sprintf("Value of exporters > %s > auth > authenticator on config.yaml is invalid: %s", e.ID, err))
+			continue
+		}
+		extension, ok := c.Component(KindExtension, id)
+		if !ok {
+			reporter.AddError(fmt.Sprintf("Exporter %s on config.yaml uses authenticator %s, which is not defined. Add it under extensions", e.ID, id))
+			continue
+		}
+		if !slices.Contains(c.ServiceExtensions, id) {
+			reporter.AddError(fmt.Sprintf("Exporter %s on config.yaml uses authenticator %s, which is not listed in service > extensions. The collector fails to start until it is added", e.ID, id))
+		}
+		if id.Type == "basicauth" {
+			checkBasicAuth(reporter, c, extension, toGrafanaCloud)
+		}
+	}
+}
+
+// checkBasicAuth checks the client credentials of a basicauth extension. For Grafana Cloud, the username is the
+// numeric instance id.
+func checkBasicAuth(reporter *utils.ComponentReporter, c *Config, extension Component, toGrafanaCloud bool) {
+	if _, ok := extension.Value("client_auth"); !ok {
+		reporter.AddError(fmt.Sprintf("Extension %s on config.yaml has no client_auth, so it can't authenticate exporters. Set client_auth > username and client_auth > password", extension.ID))
+		return
+	}
+
+	path := fmt.Sprintf("extensions > %s > client_auth", extension.ID)
+	username := fmt.Sprint(valueOrEmpty(extension, "client_auth", "username"))
+	switch {
+	case c.isUnresolved(path + " > username"):
+		// reported as unresolved reference
+	case username == "":
+		reporter.AddError(fmt.Sprintf("Value of %s > username on config.yaml is not set", path))
+	case toGrafanaCloud && !instanceIDPattern.MatchString(username):
+		reporter.AddError(fmt.Sprintf("Value of %s > username on config.yaml is not a Grafana Cloud instance id. Use the numeric instance id of your stack", path))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of %s > username on config.yaml is set", path))
+	}
+
+	password := fmt.Sprint(valueOrEmpty(extension, "client_auth", "password"))
+	switch {
+	case c.isUnresolved(path + " > password"):
+		// reported as unresolved reference
+	case password == "":
+		reporter.AddError(fmt.Sprintf("Value of %s > password on config.yaml is not set. Set it directly or through ${env:GRAFANA_CLOUD_API_KEY}", path))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of %s > password on config.yaml is set", path))
+	}
+}
+
+// authorizationHeader returns the Authorization header an exporter sets in headers. Header names are case-insensitive,
+// so authorization is found as well.
+func authorizationHeader(e Component) string {
+	headers, _ := valueOrEmpty(e, "headers").(map[string]any)
+	for k, v := range headers {
+		if s, ok := v.(string); ok && strings.EqualFold(k, "Authorization") {
+			return s
+		}
+	}
+	return ""
+}
+
+// basicAuthHeader returns the Authorization header of the basicauth extension the exporter authenticates with
+func (c *Config) basicAuthHeader(e Component) string {
+	id, err := ParseComponentID(e.StringValue("auth", "authenticator"))
+	if err != nil || id.Type != "basicauth" {
+		return ""
+	}
+	extension, ok := c.Component(KindExtension, id)
+	if !ok {
+		return ""
+	}
+	username := fmt.Sprint(valueOrEmpty(extension, "client_auth", "username"))
+	password := fmt.Sprint(valueOrEmpty(extension, "client_auth", "password"))
+	if username == "" || password == "" {
+		return ""
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+// secrets returns the credentials set in the config: the Authorization headers of the exporters, and the passwords and
+// tokens of the authentication extensions
+func (c *Config) secrets() []string {
+	var secrets []string
+	for _, e := range c.Exporters {
+		if authorization := authorizationHeader(e); authorization != "" {
+			secrets = append(secrets, authorization)
+		}
+	}
+	for _, e := range c.Extensions {
+		for _, path := range [][]string{{"client_auth", "password"}, {"token"}} {
+			if secret := fmt.Sprint(valueOrEmpty(e, path...)); secret != "" {
+				secrets = append(secrets, secret)
+			}
+		}
+	}
+	return secrets
+}
+
+// valueOrEmpty returns the value at the path, or "" if it is not set. Numbers are kept, since an instance id
+// is parsed as number.
+func valueOrEmpty(c Component, path ...string) any {
+	v, ok := c.Value(path...)
+	if !ok || v == nil {
+		return ""
+	}
+	return v
+}
+
+func (c *Config) isUnresolved(path string) bool {
+	return slices.ContainsFunc(c.Unresolved, func(r Reference) bool { return r.Path == path })
+}
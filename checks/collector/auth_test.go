@@ -0,0 +1,225 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckAuthentication(t *testing.T) {
+	tests := []struct {
+		name           string
+		configYAML     string
+		env            map[string]string
+		expectedErrors []string
+		expectedChecks []string
+	}{
+		{
+			name: "basicauth for Grafana Cloud",
+			configYAML: `
+extensions:
+  basicauth/grafana:
+    client_auth:
+      username: "123456"
+      password: ${env:GRAFANA_CLOUD_API_KEY}
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    auth:
+      authenticator: basicauth/grafana
+service:
+  extensions: [basicauth/grafana]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+			env: map[string]string{"GRAFANA_CLOUD_API_KEY": "glc_token"},
+			expectedChecks: []string{
+				"collector: Value of extensions > basicauth/grafana > client_auth > username on config.yaml is set",
+				"collector: Value of extensions > basicauth/grafana > client_auth > password on config.yaml is set",
+			},
+		},
+		{
+			name: "password from an unset environment variable is reported as unresolved reference",
+			configYAML: `
+extensions:
+  basicauth:
+    client_auth:
+      username: ${env:GRAFANA_CLOUD_INSTANCE_ID}
+      password: ${env:GRAFANA_CLOUD_API_KEY}
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    auth:
+      authenticator: basicauth
+service:
+  extensions: [basicauth]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+			env: map[string]string{"GRAFANA_CLOUD_INSTANCE_ID": "123456"},
+			expectedChecks: []string{
+				"collector: Value of extensions > basicauth > client_auth > username on config.yaml is set",
+			},
+		},
+		{
+			name: "invalid basicauth",
+			configYAML: `
+extensions:
+  basicauth/grafana:
+    client_auth:
+      username: admin
+  basicauth/server:
+    htpasswd:
+      inline: user:password
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    auth:
+      authenticator: basicauth/grafana
+  otlp/tempo:
+    endpoint: tempo:4317
+    auth:
+      authenticator: basicauth/server
+service:
+  extensions: [basicauth/grafana, basicauth/server]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp, otlp/tempo]
+`,
+			expectedErrors: []string{
+				"collector: Value of extensions > basicauth/grafana > client_auth > username on config.yaml is not a Grafana Cloud instance id. Use the numeric instance id of your stack",
+				"collector: Value of extensions > basicauth/grafana > client_auth > password on config.yaml is not set. Set it directly or through ${env:GRAFANA_CLOUD_API_KEY}",
+				"collector: Extension basicauth/server on config.yaml has no client_auth, so it can't authenticate exporters. Set client_auth > username and client_auth > password",
+			},
+		},
+		{
+			name: "undefined and disabled authenticators",
+			configYAML: `
+extensions:
+  bearertokenauth:
+    token: abc
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    auth:
+      authenticator: basicauth
+  otlphttp/self-hosted:
+    endpoint: https://otlp.example.com
+    auth:
+      authenticator: bearertokenauth
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp, otlphttp/self-hosted]
+`,
+			expectedErrors: []string{
+				"collector: Exporter otlphttp on config.yaml uses authenticator basicauth, which is not defined. Add it under extensions",
+				"collector: Exporter otlphttp/self-hosted on config.yaml uses authenticator bearertokenauth, which is not listed in service > extensions. The collector fails to start until it is added",
+			},
+		},
+		{
+			name: "Grafana Cloud without authentication",
+			configYAML: `
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+  otlphttp/headers:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    headers:
+      Authorization: Basic MTIzOmFiYw==
+  otlphttp/lowercase:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+    headers:
+      authorization: Basic MTIzOmFiYw==
+  prometheusremotewrite:
+    endpoint: https://prometheus-prod-13-prod-us-east-0.grafana.net/api/prom/push
+  otlphttp/local:
+    endpoint: http://localhost:4318
+service:
+  pipelines:
+    metrics:
+      receivers: [otlp]
+      exporters: [otlphttp, otlphttp/headers, otlphttp/lowercase, prometheusremotewrite, otlphttp/local]
+`,
+			expectedErrors: []string{
+				"collector: Exporter otlphttp on config.yaml sends to Grafana Cloud without authentication. Add the basicauth extension with the instance id as username and an access policy token as password, and set it in auth > authenticator",
+				"collector: Exporter prometheusremotewrite on config.yaml sends to Grafana Cloud without authentication. Add the basicauth extension with the instance id as username and an access policy token as password, and set it in auth > authenticator",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := providers{
+				lookupEnv: func(name string) (string, bool) {
+					v, ok := tt.env[name]
+					return v, ok
+				},
+				readFile: func(string) ([]byte, error) {
+					return []byte(tt.configYAML), nil
+				},
+			}
+			c, err := p.load([]string{"config.yaml"})
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			componentReporter := reporter.Component("collector")
+			checkAuthentication(componentReporter, c)
+
+			assert.ElementsMatch(t, tt.expectedErrors, componentReporter.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, componentReporter.Checks, "checks mismatch")
+		})
+	}
+}
+
+func TestBasicAuthHeader(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+extensions:
+  basicauth/grafana:
+    client_auth:
+      username: 123
+      password: abc
+exporters:
+  otlphttp/basicauth:
+    auth:
+      authenticator: basicauth/grafana
+  otlphttp/bearer:
+    auth:
+      authenticator: bearertokenauth
+`))
+	require.NoError(t, err)
+
+	basicauth, _ := c.Component(KindExporter, ComponentID{Type: "otlphttp", Name: "basicauth"})
+	assert.Equal(t, "Basic MTIzOmFiYw==", c.basicAuthHeader(basicauth))
+	bearer, _ := c.Component(KindExporter, ComponentID{Type: "otlphttp", Name: "bearer"})
+	assert.Empty(t, c.basicAuthHeader(bearer))
+}
+
+func TestAuthorizationHeader(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+exporters:
+  otlphttp/upper:
+    headers:
+      Authorization: Basic MTIzOmFiYw==
+  otlphttp/lower:
+    headers:
+      authorization: Basic MTIzOmFiYw==
+  otlphttp/none:
+    headers:
+      X-Scope-OrgID: tenant
+`))
+	require.NoError(t, err)
+
+	for name, expected := range map[string]string{"upper": "Basic MTIzOmFiYw==", "lower": "Basic MTIzOmFiYw==", "none": ""} {
+		e, _ := c.Component(KindExporter, ComponentID{Type: "otlphttp", Name: name})
+		assert.Equal(t, expected, authorizationHeader(e), name)
+	}
+}
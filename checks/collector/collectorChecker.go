@@ -2,117 +2,160 @@ package collector
 
 import (
 	"fmt"
+	"github.com/grafana/otel-checker/checks/target"
 	"github.com/grafana/otel-checker/checks/utils"
 	"os"
-	"regexp"
-	"slices"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
-func CheckCollectorSetup(reporter *utils.ComponentReporter, language string, configPath string) {
-	checkCollectorConfig(reporter, configPath)
+func CheckCollectorSetup(reporter *utils.ComponentReporter, commands utils.Commands) {
+	if commands.Fix || commands.DryRun {
+		FixCollectorConfig(reporter, commands.CollectorConfigPath, commands.DryRun, os.Stdout)
+	}
+	c := checkCollectorConfig(reporter, commands.CollectorConfigPath, commands.Target, detectVersion(commands.CollectorVersion))
+	if commands.CollectorURL != "" {
+		checkRunningCollector(reporter, commands.CollectorURL, c)
+	}
 }
 
-type configFile struct {
-	Receivers struct {
-		Otlp struct {
-			Protocols struct {
-				Grpc *string `yaml:"grpc"`
-				Http *string `yaml:"http"`
-			} `yaml:"protocols"`
-		} `yaml:"otlp"`
-	} `yaml:"receivers"`
-	Exporters struct {
-		Otlphttp struct {
-			Endpoint string                 `yaml:"endpoint"`
-			Auth     map[string]interface{} `yaml:"auth"`
-		} `yaml:"otlphttp"`
-	} `yaml:"exporters"`
-	Service struct {
-		Pipelines struct {
-			Traces struct {
-				Receivers  []string `yaml:"receivers"`
-				Processors []string `yaml:"processors"`
-				Exporters  []string `yaml:"exporters"`
-			} `yaml:"traces"`
-			Logs struct {
-				Receivers  []string `yaml:"receivers"`
-				Processors []string `yaml:"processors"`
-				Exporters  []string `yaml:"exporters"`
-			} `yaml:"logs"`
-			Metrics struct {
-				Receivers  []string `yaml:"receivers"`
-				Processors []string `yaml:"processors"`
-				Exporters  []string `yaml:"exporters"`
-			} `yaml:"metrics"`
-		} `yaml:"pipelines"`
-	} `yaml:"service"`
+// ExporterTarget is an endpoint an exporter of the collector config sends data to
+type ExporterTarget struct {
+	// Name describes the exporter, e.g. "exporter > otlphttp/grafana"
+	Name          string
+	Endpoint      string
+	Authorization string
 }
 
-func checkCollectorConfig(reporter *utils.ComponentReporter, configPath string) {
-	filePath := configPath + "config.yaml"
-	yamlFile, err := os.ReadFile(filePath)
+// ExporterTargets returns the endpoints the otlphttp exporters used by the pipelines of the collector config in
+// configPath send data to
+func ExporterTargets(configPath string) ([]ExporterTarget, error) {
+	c, err := LoadConfigs(configPath)
 	if err != nil {
-		reporter.AddError(fmt.Sprintf("Could not check file %s: %s", filePath, err))
-	} else {
-		var c configFile
-		err = yaml.Unmarshal([]byte(yamlFile), &c)
-		if err != nil {
-			reporter.AddError(fmt.Sprintf("Could not parse file %s: %s", filePath, err))
-			return
+		return nil, err
+	}
+	var targets []ExporterTarget
+	for _, e := range c.UsedExporters() {
+		endpoint := e.StringValue("endpoint")
+		if e.ID.Type != "otlphttp" || endpoint == "" {
+			continue
 		}
-
-		if c.Receivers.Otlp.Protocols.Http == nil {
-			reporter.AddWarning("The value of receivers > otlp > protocols > http is nil. Make sure the key exists on your config.yaml")
+		authorization := authorizationHeader(e)
+		if authorization == "" {
+			authorization = c.basicAuthHeader(e)
 		}
+		targets = append(targets, ExporterTarget{
+			Name:          fmt.Sprintf("exporter > %s", e.ID),
+			Endpoint:      endpoint,
+			Authorization: authorization,
+		})
+	}
+	return targets, nil
+}
 
-		match, _ := regexp.MatchString("https:\\/\\/.+\\.grafana\\.net\\/otlp", c.Exporters.Otlphttp.Endpoint)
-		if match {
-			reporter.AddSuccessfulCheck("Value of exporter > otlphttp > endpoint on config.yaml set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp")
-		} else {
-			if strings.Contains(c.Exporters.Otlphttp.Endpoint, "localhost") {
-				reporter.AddWarning("Value of exporter > otlphttp > endpoint on config.yaml is set to localhost. Update to a Grafana endpoint similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp to be able to send telemetry to your Grafana Cloud instance")
-			} else {
-				reporter.AddError("Value of exporter > otlphttp > endpoint on config.yaml is not set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp")
-			}
-		}
+// checkCollectorConfig checks the collector config and returns it, or nil if it can't be loaded
+func checkCollectorConfig(reporter *utils.ComponentReporter, configPath string, profile target.Profile, version CollectorVersion) *Config {
+	c, err := LoadConfigs(configPath)
+	if err != nil {
+		reporter.AddError(utils.Capitalize(err.Error()))
+		return nil
+	}
+	for _, r := range c.Unresolved {
+		reporter.AddWarning(fmt.Sprintf("%s in %s on config.yaml can't be resolved: %s. Make sure it is available to the collector", r.Ref, r.Path, r.Reason))
+	}
 
-		// Traces
-		if slices.Contains(c.Service.Pipelines.Traces.Exporters, "otlphttp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > traces > exporters on config.yaml contains otlphttp")
-		} else {
-			reporter.AddWarning("Value of service > pipelines > traces > exporters on config.yaml does not contain otlphttp")
-		}
-		if slices.Contains(c.Service.Pipelines.Traces.Receivers, "otlp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > traces > receivers on config.yaml contains otlp")
-		} else {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > traces > receivers on config.yaml does not contain otlp")
-		}
+	checkPipelineGraph(reporter, c)
+	checkProcessors(reporter, c, onKubernetes(c, os.Getenv))
+	checkAuthentication(reporter, c)
+	checkApplicationObservability(reporter, c)
+	checkReceiverEndpoints(reporter, c, detectHost(), os.Getenv)
+	checkVersions(reporter, c, version)
+	checkOTLPReceivers(reporter, c)
+
+	exporters := usedOfType(c.UsedExporters(), "otlphttp")
+	if len(exporters) == 0 {
+		checkExporterEndpoint(reporter, ComponentID{Type: "otlphttp"}, "", profile)
+	}
+	for _, e := range exporters {
+		checkExporterEndpoint(reporter, e.ID, e.StringValue("endpoint"), profile)
+	}
+
+	for _, signal := range []string{"traces", "logs", "metrics"} {
+		checkPipelines(reporter, c, signal)
+	}
+	return c
+}
 
-		// Logs
-		if slices.Contains(c.Service.Pipelines.Logs.Exporters, "otlphttp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > logs > exporters on config.yaml contains otlphttp")
+// checkOTLPReceivers checks that the otlp receivers used by the pipelines accept OTLP over HTTP
+func checkOTLPReceivers(reporter *utils.ComponentReporter, c *Config) {
+	receivers := usedOfType(c.UsedReceivers(), "otlp")
+	if len(receivers) == 0 {
+		if r, ok := c.Component(KindReceiver, ComponentID{Type: "otlp"}); ok {
+			receivers = []Component{r}
 		} else {
-			reporter.AddWarning("Value of service > pipelines > logs > exporters on config.yaml does not contain otlphttp")
+			receivers = []Component{{ID: ComponentID{Type: "otlp"}}}
 		}
-		if slices.Contains(c.Service.Pipelines.Logs.Receivers, "otlp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > logs > receivers on config.yaml contains otlp")
-		} else {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > logs > receivers on config.yaml does not contain otlp")
+	}
+	for _, r := range receivers {
+		if _, ok := r.Value("protocols", "http"); !ok {
+			reporter.AddWarning(fmt.Sprintf("The value of receivers > %s > protocols > http is nil. Make sure the key exists on your config.yaml", r.ID))
 		}
+	}
+}
 
-		// Metrics
-		if slices.Contains(c.Service.Pipelines.Metrics.Exporters, "otlphttp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > metrics > exporters on config.yaml contains otlphttp")
-		} else {
-			reporter.AddWarning("Value of service > pipelines > metrics > exporters on config.yaml does not contain otlphttp")
+// checkPipelines checks that the pipelines of the signal receive from an otlp receiver and export to an otlphttp
+// exporter. Any of the pipelines of the signal, e.g. traces or traces/backend, can contain them.
+func checkPipelines(reporter *utils.ComponentReporter, c *Config, signal string) {
+	pipelines := c.PipelinesFor(signal)
+	described := pipelineIDs(pipelines)
+	if described == "" {
+		described = signal
+	}
+
+	if p, id, ok := findInPipelines(pipelines, func(p Pipeline) []ComponentID { return p.Exporters }, "otlphttp"); ok {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of service > pipelines > %s > exporters on config.yaml contains %s", p.ID, id))
+	} else {
+		reporter.AddWarning(fmt.Sprintf("Value of service > pipelines > %s > exporters on config.yaml does not contain otlphttp", described))
+	}
+	if p, id, ok := findInPipelines(pipelines, func(p Pipeline) []ComponentID { return p.Receivers }, "otlp"); ok {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of service > pipelines > %s > receivers on config.yaml contains %s", p.ID, id))
+	} else {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of service > pipelines > %s > receivers on config.yaml does not contain otlp", described))
+	}
+}
+
+func findInPipelines(pipelines []Pipeline, ids func(Pipeline) []ComponentID, componentType string) (Pipeline, ComponentID, bool) {
+	for _, p := range pipelines {
+		for _, id := range ids(p) {
+			if id.Type == componentType {
+				return p, id, true
+			}
 		}
-		if slices.Contains(c.Service.Pipelines.Metrics.Receivers, "otlp") {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > metrics > receivers on config.yaml contains otlp")
-		} else {
-			reporter.AddSuccessfulCheck("Value of service > pipelines > metrics > receivers on config.yaml does not contain otlp")
+	}
+	return Pipeline{}, ComponentID{}, false
+}
+
+func usedOfType(components []Component, componentType string) []Component {
+	var result []Component
+	for _, c := range components {
+		if c.ID.Type == componentType {
+			result = append(result, c)
 		}
 	}
+	return result
+}
+
+// checkExporterEndpoint checks the endpoint of an otlphttp exporter against the target. The exporters are not
+// checked against a local target, since the collector is the local target itself.
+func checkExporterEndpoint(reporter *utils.ComponentReporter, id ComponentID, endpoint string, profile target.Profile) {
+	if profile.Local {
+		return
+	}
+	switch {
+	case profile.Warns(target.WarnLocalhost) && strings.Contains(endpoint, "localhost"):
+		reporter.AddWarning(fmt.Sprintf("Value of exporter > %s > endpoint on config.yaml is set to localhost. Update to an endpoint similar to %s to be able to send telemetry to %s", id, profile.EndpointExample, profile.Backend))
+	case endpoint != "" && profile.MatchesEndpoint(endpoint):
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Value of exporter > %s > endpoint on config.yaml set in the format similar to %s", id, profile.EndpointExample))
+	default:
+		reporter.AddError(fmt.Sprintf("Value of exporter > %s > endpoint on config.yaml is not set in the format similar to %s", id, profile.EndpointExample))
+	}
 }
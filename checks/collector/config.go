@@ -0,0 +1,262 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of components in a collector config
+const (
+	KindReceiver  = "receivers"
+	KindProcessor = "processors"
+	KindExporter  = "exporters"
+	KindConnector = "connectors"
+	KindExtension = "extensions"
+)
+
+// Signals are the types of pipelines in a collector config
+var Signals = []string{"traces", "metrics", "logs"}
+
+// ComponentID identifies a component instance in the form type[/name], e.g. "otlphttp/grafana"
+type ComponentID struct {
+	Type string
+	Name string
+}
+
+// ParseComponentID parses a component id in the form type[/name]
+func ParseComponentID(s string) (ComponentID, error) {
+	typ, name, hasName := strings.Cut(strings.TrimSpace(s), "/")
+	if typ == "" {
+		return ComponentID{}, fmt.Errorf("'%s' has no type", s)
+	}
+	if hasName && name == "" {
+		return ComponentID{}, fmt.Errorf("'%s' has an empty name after '/'", s)
+	}
+	return ComponentID{Type: typ, Name: name}, nil
+}
+
+func (id ComponentID) String() string {
+	if id.Name == "" {
+		return id.Type
+	}
+	return id.Type + "/" + id.Name
+}
+
+// Component is a configured instance of a receiver, processor, exporter, connector or extension
+type Component struct {
+	ID ComponentID
+	// Config is the configuration of the component, nil if the component has no configuration
+	Config map[string]any
+}
+
+// Pipeline is a pipeline of the service, e.g. "traces/backend"
+type Pipeline struct {
+	// ID has the signal as type, e.g. traces
+	ID         ComponentID
+	Receivers  []ComponentID
+	Processors []ComponentID
+	Exporters  []ComponentID
+}
+
+// Signal returns the type of telemetry of the pipeline: traces, metrics or logs
+func (p Pipeline) Signal() string {
+	return p.ID.Type
+}
+
+// Config is a generic model of a collector config, where every component is referenced by its id
+type Config struct {
+	Receivers  []Component
+	Processors []Component
+	Exporters  []Component
+	Connectors []Component
+	Extensions []Component
+	// ServiceExtensions are the extensions enabled in service > extensions
+	ServiceExtensions []ComponentID
+	Pipelines         []Pipeline
+	// Telemetry is the config in service > telemetry
+	Telemetry map[string]any
+	// Unresolved are the ${...} references that could not be expanded
+	Unresolved []Reference
+}
+
+type rawConfig struct {
+	Receivers  map[string]map[string]any `yaml:"receivers"`
+	Processors map[string]map[string]any `yaml:"processors"`
+	Exporters  map[string]map[string]any `yaml:"exporters"`
+	Connectors map[string]map[string]any `yaml:"connectors"`
+	Extensions map[string]map[string]any `yaml:"extensions"`
+	Service    struct {
+		Extensions []string       `yaml:"extensions"`
+		Telemetry  map[string]any `yaml:"telemetry"`
+		Pipelines  map[string]struct {
+			Receivers  []string `yaml:"receivers"`
+			Processors []string `yaml:"processors"`
+			Exporters  []string `yaml:"exporters"`
+		} `yaml:"pipelines"`
+	} `yaml:"service"`
+}
+
+// ParseConfig parses a collector config. Components and pipelines are sorted by id.
+func ParseConfig(data []byte) (*Config, error) {
+	var raw rawConfig
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	c := &Config{Telemetry: raw.Service.Telemetry}
+	var err error
+	if c.Receivers, err = parseComponents(KindReceiver, raw.Receivers); err != nil {
+		return nil, err
+	}
+	if c.Processors, err = parseComponents(KindProcessor, raw.Processors); err != nil {
+		return nil, err
+	}
+	if c.Exporters, err = parseComponents(KindExporter, raw.Exporters); err != nil {
+		return nil, err
+	}
+	if c.Connectors, err = parseComponents(KindConnector, raw.Connectors); err != nil {
+		return nil, err
+	}
+	if c.Extensions, err = parseComponents(KindExtension, raw.Extensions); err != nil {
+		return nil, err
+	}
+	if c.ServiceExtensions, err = parseIDs("service > extensions", raw.Service.Extensions); err != nil {
+		return nil, err
+	}
+
+	for name, p := range raw.Service.Pipelines {
+		id, err := ParseComponentID(name)
+		if err != nil {
+			return nil, fmt.Errorf("service > pipelines: %w", err)
+		}
+		pipeline := Pipeline{ID: id}
+		if pipeline.Receivers, err = parseIDs(fmt.Sprintf("service > pipelines > %s > receivers", id), p.Receivers); err != nil {
+			return nil, err
+		}
+		if pipeline.Processors, err = parseIDs(fmt.Sprintf("service > pipelines > %s > processors", id), p.Processors); err != nil {
+			return nil, err
+		}
+		if pipeline.Exporters, err = parseIDs(fmt.Sprintf("service > pipelines > %s > exporters", id), p.Exporters); err != nil {
+			return nil, err
+		}
+		c.Pipelines = append(c.Pipelines, pipeline)
+	}
+	slices.SortFunc(c.Pipelines, func(a, b Pipeline) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return c, nil
+}
+
+func parseComponents(kind string, raw map[string]map[string]any) ([]Component, error) {
+	var components []Component
+	for name, config := range raw {
+		id, err := ParseComponentID(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", kind, err)
+		}
+		components = append(components, Component{ID: id, Config: config})
+	}
+	slices.SortFunc(components, func(a, b Component) int {
+		return strings.Compare(a.ID.String(), b.ID.String())
+	})
+	return components, nil
+}
+
+func parseIDs(path string, names []string) ([]ComponentID, error) {
+	var ids []ComponentID
+	for _, name := range names {
+		id, err := ParseComponentID(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Component returns the component of the given kind with the id
+func (c *Config) Component(kind string, id ComponentID) (Component, bool) {
+	for _, component := range c.components(kind) {
+		if component.ID == id {
+			return component, true
+		}
+	}
+	return Component{}, false
+}
+
+func (c *Config) components(kind string) []Component {
+	switch kind {
+	case KindReceiver:
+		return c.Receivers
+	case KindProcessor:
+		return c.Processors
+	case KindExporter:
+		return c.Exporters
+	case KindConnector:
+		return c.Connectors
+	case KindExtension:
+		return c.Extensions
+	}
+	return nil
+}
+
+// PipelinesFor returns the pipelines of the signal, e.g. traces and traces/backend for traces
+func (c *Config) PipelinesFor(signal string) []Pipeline {
+	var pipelines []Pipeline
+	for _, p := range c.Pipelines {
+		if p.Signal() == signal {
+			pipelines = append(pipelines, p)
+		}
+	}
+	return pipelines
+}
+
+// UsedExporters returns the exporters referenced by any pipeline that are defined in exporters, in the order of
+// the exporters
+func (c *Config) UsedExporters() []Component {
+	var used []Component
+	for _, e := range c.Exporters {
+		if slices.ContainsFunc(c.Pipelines, func(p Pipeline) bool { return slices.Contains(p.Exporters, e.ID) }) {
+			used = append(used, e)
+		}
+	}
+	return used
+}
+
+// UsedReceivers returns the receivers referenced by any pipeline that are defined in receivers, in the order of
+// the receivers
+func (c *Config) UsedReceivers() []Component {
+	var used []Component
+	for _, r := range c.Receivers {
+		if slices.ContainsFunc(c.Pipelines, func(p Pipeline) bool { return slices.Contains(p.Receivers, r.ID) }) {
+			used = append(used, r)
+		}
+	}
+	return used
+}
+
+// StringValue returns the string at the path of keys in the config of the component
+func (c Component) StringValue(path ...string) string {
+	v, _ := c.Value(path...)
+	s, _ := v.(string)
+	return s
+}
+
+// Value returns the value at the path of keys in the config of the component and whether the last key exists
+func (c Component) Value(path ...string) (any, bool) {
+	var current any = c.Config
+	for _, key := range path {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
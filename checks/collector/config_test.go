@@ -0,0 +1,93 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseComponentID(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    ComponentID
+		expectedErr string
+	}{
+		{input: "otlphttp", expected: ComponentID{Type: "otlphttp"}},
+		{input: "otlphttp/grafana", expected: ComponentID{Type: "otlphttp", Name: "grafana"}},
+		{input: "traces/backend/eu", expected: ComponentID{Type: "traces", Name: "backend/eu"}},
+		{input: "/grafana", expectedErr: "'/grafana' has no type"},
+		{input: "otlphttp/", expectedErr: "'otlphttp/' has an empty name after '/'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, err := ParseComponentID(tt.input)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, id)
+			assert.Equal(t, tt.input, id.String())
+		})
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: 0.0.0.0:4318
+exporters:
+  otlphttp/grafana:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+  otlp/tempo:
+    endpoint: tempo:4317
+  otlp/unused:
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces/backend:
+      receivers: [otlp]
+      exporters: [otlphttp/grafana, otlp/tempo]
+    metrics:
+      receivers: [otlp]
+      exporters: [otlphttp/grafana]
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []ComponentID{{Type: "health_check"}}, c.ServiceExtensions)
+	assert.Equal(t, []Pipeline{
+		{
+			ID:        ComponentID{Type: "metrics"},
+			Receivers: []ComponentID{{Type: "otlp"}},
+			Exporters: []ComponentID{{Type: "otlphttp", Name: "grafana"}},
+		},
+		{
+			ID:        ComponentID{Type: "traces", Name: "backend"},
+			Receivers: []ComponentID{{Type: "otlp"}},
+			Exporters: []ComponentID{{Type: "otlphttp", Name: "grafana"}, {Type: "otlp", Name: "tempo"}},
+		},
+	}, c.Pipelines)
+	assert.Len(t, c.PipelinesFor("traces"), 1)
+	assert.Empty(t, c.PipelinesFor("logs"))
+
+	var used []string
+	for _, e := range c.UsedExporters() {
+		used = append(used, e.ID.String())
+	}
+	assert.Equal(t, []string{"otlp/tempo", "otlphttp/grafana"}, used)
+
+	receiver, ok := c.Component(KindReceiver, ComponentID{Type: "otlp"})
+	require.True(t, ok)
+	assert.Equal(t, "0.0.0.0:4318", receiver.StringValue("protocols", "http", "endpoint"))
+	_, ok = receiver.Value("protocols", "grpc")
+	assert.False(t, ok)
+
+	_, err = ParseConfig([]byte("exporters:\n  otlphttp/:\n"))
+	assert.EqualError(t, err, "exporters: 'otlphttp/' has an empty name after '/'")
+}
@@ -0,0 +1,381 @@
+package collector
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// processorDefaults is the config a fix uses for processors it adds
+var processorDefaults = map[string]string{
+	"memory_limiter": "check_interval: 1s\nlimit_percentage: 80\nspike_limit_percentage: 25",
+	"batch":          "",
+}
+
+// FixCollectorConfig applies the fixes for findings with an obvious fix to the collector config at configPath and
+// prints a unified diff of the changes to out. The original file is kept as <file>.bak. With dryRun, only the diff is
+// printed.
+func FixCollectorConfig(reporter *utils.ComponentReporter, configPath string, dryRun bool, out io.Writer) {
+	uris := ConfigURIs(configPath)
+	path, isFile := localPath(uris[0])
+	if len(uris) > 1 || !isFile {
+		reporter.AddWarning(fmt.Sprintf("Fixing needs a single config file, but -collector-config-path is %s. Run the fix for each file", configPath))
+		return
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not fix file %s: %s", path, err))
+		return
+	}
+	c, err := LoadConfigs(path)
+	if err != nil {
+		// reported by the checks of the config
+		return
+	}
+	fixed, changes, err := fixConfig(original, c)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not fix file %s: %s", path, err))
+		return
+	}
+	if len(changes) == 0 {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Nothing to fix in %s", path))
+		return
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(string(original), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(string(fixed), "\n")),
+		FromFile: path,
+		ToFile:   path,
+		Context:  3,
+	})
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not fix file %s: %s", path, err))
+		return
+	}
+	// the context lines of the diff may contain credentials of the config
+	for _, secret := range c.secrets() {
+		reporter.AddSecret(secret)
+	}
+	_, _ = fmt.Fprint(out, reporter.Redact(diff))
+	if dryRun {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not fix file %s: %s", path, err))
+		return
+	}
+	backup, err := writeBackup(path, original, info.Mode().Perm())
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Could not write backup of %s: %s", path, err))
+		return
+	}
+	if err := os.WriteFile(path, fixed, info.Mode().Perm()); err != nil {
+		reporter.AddError(fmt.Sprintf("Could not fix file %s: %s", path, err))
+		return
+	}
+	for _, change := range changes {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Fixed %s: %s. The original file is kept as %s", path, change, backup))
+	}
+}
+
+// writeBackup writes the original config to <file>.bak, or to <file>.bak.1, <file>.bak.2 and so on if the backup of
+// an earlier fix exists, so that it is never overwritten. It returns the path of the backup.
+func writeBackup(path string, original []byte, perm os.FileMode) (string, error) {
+	for i := 0; ; i++ {
+		backup := path + ".bak"
+		if i > 0 {
+			backup = fmt.Sprintf("%s.bak.%d", path, i)
+		}
+		f, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(original); err != nil {
+			_ = f.Close()
+			return "", err
+		}
+		return backup, f.Close()
+	}
+}
+
+// localPath returns the path of a config URI that refers to a local file
+func localPath(uri string) (string, bool) {
+	scheme, rest, hasScheme := strings.Cut(uri, ":")
+	switch {
+	case !hasScheme || !SchemePattern.MatchString(scheme) || len(scheme) == 1:
+		return uri, true
+	case scheme == "file":
+		return rest, true
+	default:
+		return "", false
+	}
+}
+
+// fixConfig edits the YAML of the config through yaml.Node, so that comments and the order of keys are kept. It
+// returns the fixed YAML and a description of each change.
+func fixConfig(data []byte, c *Config) ([]byte, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return data, nil, nil
+	}
+	root := doc.Content[0]
+
+	var changes []string
+	changes = append(changes, fixOTLPHTTPReceivers(root, c)...)
+	changes = append(changes, fixProcessors(root, c)...)
+	changes = append(changes, fixOTLPHTTPExporters(root, c)...)
+	if len(changes) == 0 {
+		return data, nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(detectIndent(data))
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+	return keepOriginalLines(data, buf.Bytes()), changes, nil
+}
+
+// detectIndent returns the indentation of the config, which is the smallest indentation of a line
+func detectIndent(data []byte) int {
+	indent := 0
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if n := len(line) - len(trimmed); n > 0 && (indent == 0 || n < indent) {
+			indent = n
+		}
+	}
+	return max(indent, 2)
+}
+
+// keepOriginalLines replaces the lines of the encoded config that only differ from the original in whitespace, such
+// as the indentation of lists and the spacing of comments, by the original lines, so that the diff only shows the
+// lines a fix changed
+func keepOriginalLines(original []byte, encoded []byte) []byte {
+	text := string(original)
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	a := strings.SplitAfter(text, "\n")
+	b := strings.SplitAfter(string(encoded), "\n")
+	normalize := func(lines []string) []string {
+		result := make([]string, len(lines))
+		for i, line := range lines {
+			result[i] = strings.Join(strings.Fields(line), "")
+		}
+		return result
+	}
+
+	indent := func(line string) int {
+		return len(line) - len(strings.TrimLeft(line, " "))
+	}
+
+	// changed lines are shifted like the line before them, e.g. the items of a list that is not indented
+	var result strings.Builder
+	shift := 0
+	for _, op := range difflib.NewMatcher(normalize(a), normalize(b)).GetOpCodes() {
+		if op.Tag == 'e' {
+			result.WriteString(strings.Join(a[op.I1:op.I2], ""))
+			shift = indent(a[op.I2-1]) - indent(b[op.J2-1])
+			continue
+		}
+		for _, line := range b[op.J1:op.J2] {
+			switch {
+			case strings.TrimSpace(line) == "":
+			case shift > 0:
+				line = strings.Repeat(" ", shift) + line
+			case shift < 0 && indent(line) >= -shift:
+				line = line[-shift:]
+			}
+			result.WriteString(line)
+		}
+	}
+	return []byte(result.String())
+}
+
+// fixOTLPHTTPReceivers enables the http protocol of the otlp receivers used by the pipelines
+func fixOTLPHTTPReceivers(root *yaml.Node, c *Config) []string {
+	var changes []string
+	for _, r := range usedOfType(c.UsedReceivers(), "otlp") {
+		if _, ok := r.Value("protocols", "http"); ok {
+			continue
+		}
+		receiver := ensureMapping(ensureMapping(root, KindReceiver, ""), r.ID.String(), "")
+		setMappingValue(ensureMapping(receiver, "protocols", ""), "http", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+		changes = append(changes, fmt.Sprintf("enabled receivers > %s > protocols > http", r.ID))
+	}
+	return changes
+}
+
+// fixProcessors adds memory_limiter as first and batch as last processor to the pipelines that receive data from
+// outside the collector, and defines the processors if needed
+func fixProcessors(root *yaml.Node, c *Config) []string {
+	var changes []string
+	for _, typ := range []string{"memory_limiter", "batch"} {
+		id := ComponentID{Type: typ}
+		if defined := usedOfType(c.Processors, typ); len(defined) > 0 {
+			id = defined[0].ID
+		}
+		var added []string
+		for _, p := range externalPipelines(c) {
+			if slices.Contains(processorTypes(p), typ) {
+				continue
+			}
+			pipeline := mappingValue(mappingValue(mappingValue(root, "service"), "pipelines"), p.ID.String())
+			if pipeline == nil || pipeline.Kind != yaml.MappingNode {
+				continue
+			}
+			processors := ensureSequence(pipeline, KindProcessor, "receivers")
+			item := &yaml.Node{Kind: yaml.ScalarNode, Value: id.String()}
+			if typ == "memory_limiter" {
+				processors.Content = append([]*yaml.Node{item}, processors.Content...)
+			} else {
+				processors.Content = append(processors.Content, item)
+			}
+			added = append(added, p.ID.String())
+		}
+		if len(added) == 0 {
+			continue
+		}
+		if _, ok := c.Component(KindProcessor, id); !ok {
+			var config yaml.Node
+			if err := yaml.Unmarshal([]byte(processorDefaults[typ]), &config); err == nil && len(config.Content) > 0 {
+				setMappingValue(ensureMapping(root, KindProcessor, KindExporter), id.String(), config.Content[0])
+			} else {
+				setMappingValue(ensureMapping(root, KindProcessor, KindExporter), id.String(), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"})
+			}
+			changes = append(changes, fmt.Sprintf("defined processors > %s", id))
+		}
+		changes = append(changes, fmt.Sprintf("added %s to the processors of service > pipelines > %s", id, strings.Join(added, ", ")))
+	}
+	return changes
+}
+
+// fixOTLPHTTPExporters adds an otlphttp exporter that is already defined to the first pipeline of each signal whose
+// pipelines don't export with otlphttp
+func fixOTLPHTTPExporters(root *yaml.Node, c *Config) []string {
+	exporters := usedOfType(c.UsedExporters(), "otlphttp")
+	if len(exporters) == 0 {
+		exporters = usedOfType(c.Exporters, "otlphttp")
+	}
+	if len(exporters) == 0 {
+		return nil
+	}
+	id := exporters[0].ID
+
+	var changes []string
+	for _, signal := range Signals {
+		pipelines := c.PipelinesFor(signal)
+		if len(pipelines) == 0 {
+			continue
+		}
+		if _, _, ok := findInPipelines(pipelines, func(p Pipeline) []ComponentID { return p.Exporters }, "otlphttp"); ok {
+			continue
+		}
+		pipeline := mappingValue(mappingValue(mappingValue(root, "service"), "pipelines"), pipelines[0].ID.String())
+		if pipeline == nil || pipeline.Kind != yaml.MappingNode {
+			continue
+		}
+		list := ensureSequence(pipeline, KindExporter, "")
+		list.Content = append(list.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: id.String()})
+		changes = append(changes, fmt.Sprintf("added %s to service > pipelines > %s > exporters", id, pipelines[0].ID))
+	}
+	return changes
+}
+
+// mappingValue returns the value of the key in a mapping node, or nil
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of the key in a mapping node, adding the key at the end if it doesn't exist
+func setMappingValue(m *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content[i+1] = value
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// insertMappingValue adds the key to a mapping node before the key before, or at the end if before doesn't exist
+func insertMappingValue(m *yaml.Node, key string, value *yaml.Node, before string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == before {
+			m.Content = slices.Insert(m.Content, i, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+			return
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// ensureMapping returns the mapping at the key of a mapping node. A missing key is added before the key before, and
+// an empty value such as `http:` or `grpc: ""` is replaced by an empty mapping.
+func ensureMapping(m *yaml.Node, key string, before string) *yaml.Node {
+	v := mappingValue(m, key)
+	if v != nil && v.Kind == yaml.MappingNode {
+		return v
+	}
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if v != nil {
+		mapping.LineComment = v.LineComment
+		setMappingValue(m, key, mapping)
+	} else {
+		insertMappingValue(m, key, mapping, before)
+	}
+	return mapping
+}
+
+// ensureSequence returns the sequence at the key of a mapping node. A missing key is added as flow sequence after the
+// key after, or at the end if after doesn't exist.
+func ensureSequence(m *yaml.Node, key string, after string) *yaml.Node {
+	if v := mappingValue(m, key); v != nil && v.Kind == yaml.SequenceNode {
+		return v
+	}
+	sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	if mappingValue(m, key) != nil {
+		setMappingValue(m, key, sequence)
+		return sequence
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == after {
+			m.Content = slices.Insert(m.Content, i+2, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, sequence)
+			return sequence
+		}
+	}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, sequence)
+	return sequence
+}
@@ -0,0 +1,396 @@
+package collector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixConfig(t *testing.T) {
+	tests := []struct {
+		name            string
+		configYAML      string
+		expectedYAML    string
+		expectedChanges []string
+	}{
+		{
+			name: "nothing to fix",
+			configYAML: `receivers:
+  otlp:
+    protocols:
+      http:
+processors:
+  memory_limiter:
+  batch:
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp]
+`,
+			expectedYAML: `receivers:
+  otlp:
+    protocols:
+      http:
+processors:
+  memory_limiter:
+  batch:
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp]
+`,
+		},
+		{
+			name: "comments and order are kept",
+			configYAML: `# receives from the applications
+receivers:
+  otlp:
+    protocols:
+      grpc: # used by the Go services
+exporters:
+  debug:
+  # Grafana Cloud
+  otlphttp:
+    endpoint: ${env:GRAFANA_CLOUD_OTLP_ENDPOINT}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+    logs:
+      receivers: [otlp]
+      processors: [memory_limiter/logs]
+      exporters:
+        - debug
+`,
+			expectedYAML: `# receives from the applications
+receivers:
+  otlp:
+    protocols:
+      grpc: # used by the Go services
+      http:
+processors:
+  memory_limiter:
+    check_interval: 1s
+    limit_percentage: 80
+    spike_limit_percentage: 25
+  batch:
+exporters:
+  debug:
+  # Grafana Cloud
+  otlphttp:
+    endpoint: ${env:GRAFANA_CLOUD_OTLP_ENDPOINT}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp]
+    logs:
+      receivers: [otlp]
+      processors: [memory_limiter/logs, batch]
+      exporters:
+        - debug
+        - otlphttp
+`,
+			expectedChanges: []string{
+				"enabled receivers > otlp > protocols > http",
+				"defined processors > memory_limiter",
+				"added memory_limiter to the processors of service > pipelines > traces",
+				"defined processors > batch",
+				"added batch to the processors of service > pipelines > logs, traces",
+				"added otlphttp to service > pipelines > logs > exporters",
+			},
+		},
+		{
+			name: "processors that are already defined are used",
+			configYAML: `processors:
+  batch/large:
+    send_batch_size: 10000
+exporters:
+  otlphttp/grafana:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+connectors:
+  spanmetrics:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: []
+      exporters: [otlphttp/grafana, spanmetrics]
+    metrics:
+      receivers: [spanmetrics]
+      exporters: [otlphttp/grafana]
+`,
+			expectedYAML: `processors:
+  batch/large:
+    send_batch_size: 10000
+  memory_limiter:
+    check_interval: 1s
+    limit_percentage: 80
+    spike_limit_percentage: 25
+exporters:
+  otlphttp/grafana:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+connectors:
+  spanmetrics:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, batch/large]
+      exporters: [otlphttp/grafana, spanmetrics]
+    metrics:
+      receivers: [spanmetrics]
+      exporters: [otlphttp/grafana]
+`,
+			expectedChanges: []string{
+				"defined processors > memory_limiter",
+				"added memory_limiter to the processors of service > pipelines > traces",
+				"added batch/large to the processors of service > pipelines > traces",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(tt.configYAML))
+			require.NoError(t, err)
+
+			fixed, changes, err := fixConfig([]byte(tt.configYAML), c)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedYAML, string(fixed))
+			assert.Equal(t, tt.expectedChanges, changes)
+		})
+	}
+}
+
+func TestFixCollectorConfig(t *testing.T) {
+	configYAML := `receivers:
+  otlp:
+    protocols:
+      http:
+exporters:
+  otlphttp:
+    endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter]
+      exporters: [otlphttp]
+`
+	expectedDiff := `--- DIR/config.yaml
++++ DIR/config.yaml
+@@ -2,6 +2,8 @@
+   otlp:
+     protocols:
+       http:
++processors:
++  batch:
+ exporters:
+   otlphttp:
+     endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+@@ -9,5 +11,5 @@
+   pipelines:
+     traces:
+       receivers: [otlp]
+-      processors: [memory_limiter]
++      processors: [memory_limiter, batch]
+       exporters: [otlphttp]
+`
+
+	t.Run("dry run", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(configYAML), 0644))
+
+		reporter := utils.Reporter{}
+		c := reporter.Component("collector")
+		var out bytes.Buffer
+		FixCollectorConfig(c, dir+"/", true, &out)
+
+		assert.Equal(t, expectedDiff, replaceDir(out.String(), dir))
+		assert.Empty(t, c.Checks)
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, configYAML, string(data))
+		assert.NoFileExists(t, path+".bak")
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(configYAML), 0644))
+
+		reporter := utils.Reporter{}
+		c := reporter.Component("collector")
+		var out bytes.Buffer
+		FixCollectorConfig(c, dir+"/", false, &out)
+
+		assert.Equal(t, expectedDiff, replaceDir(out.String(), dir))
+		assert.Equal(t, []string{
+			"collector: Fixed DIR/config.yaml: defined processors > batch. The original file is kept as DIR/config.yaml.bak",
+			"collector: Fixed DIR/config.yaml: added batch to the processors of service > pipelines > traces. The original file is kept as DIR/config.yaml.bak",
+		}, replaceDirs(c.Checks, dir))
+		backup, err := os.ReadFile(path + ".bak")
+		require.NoError(t, err)
+		assert.Equal(t, configYAML, string(backup))
+
+		// the fixed config has nothing left to fix
+		reporter = utils.Reporter{}
+		c = reporter.Component("collector")
+		out.Reset()
+		FixCollectorConfig(c, dir+"/", false, &out)
+		assert.Empty(t, out.String())
+		assert.Equal(t, []string{"collector: Nothing to fix in DIR/config.yaml"}, replaceDirs(c.Checks, dir))
+	})
+
+	t.Run("file path of the flag", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(configYAML), 0644))
+		commands := utils.ParseArguments([]string{"-language=go", "-components=collector", "-collector-config-path=" + path, "-fix", "-dry-run"})
+
+		reporter := utils.Reporter{}
+		c := reporter.Component("collector")
+		var out bytes.Buffer
+		FixCollectorConfig(c, commands.CollectorConfigPath, commands.DryRun, &out)
+
+		assert.Equal(t, expectedDiff, replaceDir(out.String(), dir))
+		assert.Empty(t, c.Errors)
+	})
+
+	t.Run("existing backup", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(configYAML), 0644))
+		require.NoError(t, os.WriteFile(path+".bak", []byte("# the real original\n"), 0644))
+
+		reporter := utils.Reporter{}
+		c := reporter.Component("collector")
+		FixCollectorConfig(c, path, false, &bytes.Buffer{})
+
+		assert.Contains(t, replaceDirs(c.Checks, dir), "collector: Fixed DIR/config.yaml: defined processors > batch. The original file is kept as DIR/config.yaml.bak.1")
+		backup, err := os.ReadFile(path + ".bak")
+		require.NoError(t, err)
+		assert.Equal(t, "# the real original\n", string(backup), "an existing backup is not overwritten")
+		backup, err = os.ReadFile(path + ".bak.1")
+		require.NoError(t, err)
+		assert.Equal(t, configYAML, string(backup))
+	})
+
+	t.Run("secrets are masked in the diff", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(`extensions:
+  basicauth:
+    client_auth:
+      username: 123
+      password: glc_secret-password
+exporters:
+  otlphttp: {headers: {Authorization: Basic MTIzOmFiYw==}, endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp}
+service:
+  extensions: [basicauth]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter]
+      exporters: [otlphttp]
+`), 0644))
+
+		reporter := utils.Reporter{Redactor: utils.NewRedactor()}
+		c := reporter.Component("collector")
+		var out bytes.Buffer
+		FixCollectorConfig(c, path, true, &out)
+
+		assert.Contains(t, out.String(), "+      processors: [memory_limiter, batch]")
+		assert.Contains(t, out.String(), "password: ****")
+		assert.Contains(t, out.String(), "{Authorization: ****}")
+		assert.NotContains(t, out.String(), "glc_secret-password")
+		assert.NotContains(t, out.String(), "MTIzOmFiYw==")
+	})
+
+	t.Run("several configs", func(t *testing.T) {
+		reporter := utils.Reporter{}
+		c := reporter.Component("collector")
+		FixCollectorConfig(c, "base.yaml,env:COLLECTOR_CONFIG", false, &bytes.Buffer{})
+		assert.Equal(t, []string{
+			"collector: Fixing needs a single config file, but -collector-config-path is base.yaml,env:COLLECTOR_CONFIG. Run the fix for each file",
+		}, c.Warnings)
+	})
+}
+
+func TestFixConfigKeepsFormatting(t *testing.T) {
+	configYAML := `receivers:
+    otlp:
+        protocols:
+            http:   # the SDKs send with http/protobuf
+exporters:
+    otlphttp:
+        endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+processors:
+    memory_limiter:
+        limit_percentage: 80
+service:
+    pipelines:
+        traces:
+            receivers: [otlp]
+            processors:
+            - memory_limiter
+            exporters: [otlphttp]`
+	expectedYAML := `receivers:
+    otlp:
+        protocols:
+            http:   # the SDKs send with http/protobuf
+exporters:
+    otlphttp:
+        endpoint: https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+processors:
+    memory_limiter:
+        limit_percentage: 80
+    batch:
+service:
+    pipelines:
+        traces:
+            receivers: [otlp]
+            processors:
+            - memory_limiter
+            - batch
+            exporters: [otlphttp]
+`
+	c, err := ParseConfig([]byte(configYAML))
+	require.NoError(t, err)
+	fixed, changes, err := fixConfig([]byte(configYAML), c)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, expectedYAML, string(fixed))
+}
+
+func replaceDir(s string, dir string) string {
+	return strings.ReplaceAll(s, dir, "DIR")
+}
+
+func replaceDirs(messages []string, dir string) []string {
+	var result []string
+	for _, m := range messages {
+		result = append(result, replaceDir(m, dir))
+	}
+	return result
+}
@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// componentSignals are the signals supported by well-known receivers, processors and exporters that do not support
+// all signals. Components not listed are assumed to support all signals.
+var componentSignals = map[string]map[string][]string{
+	KindReceiver: {
+		"filelog":         {"logs"},
+		"hostmetrics":     {"metrics"},
+		"jaeger":          {"traces"},
+		"journald":        {"logs"},
+		"k8s_cluster":     {"metrics", "logs"},
+		"k8sobjects":      {"logs"},
+		"kubeletstats":    {"metrics"},
+		"loki":            {"logs"},
+		"prometheus":      {"metrics"},
+		"statsd":          {"metrics"},
+		"syslog":          {"logs"},
+		"windowseventlog": {"logs"},
+		"zipkin":          {"traces"},
+	},
+	KindProcessor: {
+		"cumulativetodelta":     {"metrics"},
+		"deltatocumulative":     {"metrics"},
+		"metricstransform":      {"metrics"},
+		"probabilistic_sampler": {"traces", "logs"},
+		"span":                  {"traces"},
+		"tail_sampling":         {"traces"},
+	},
+	KindExporter: {
+		"loki":                  {"logs"},
+		"prometheus":            {"metrics"},
+		"prometheusremotewrite": {"metrics"},
+		"zipkin":                {"traces"},
+	},
+}
+
+// connectorSignals maps the signal a well-known connector consumes, as the exporter of a pipeline, to the signals it
+// produces, as the receiver of another pipeline. Connectors not listed are assumed to connect all signals.
+var connectorSignals = map[string]map[string][]string{
+	"count":           {"traces": {"metrics"}, "metrics": {"metrics"}, "logs": {"metrics"}},
+	"exceptions":      {"traces": {"metrics", "logs"}},
+	"failover":        {"traces": {"traces"}, "metrics": {"metrics"}, "logs": {"logs"}},
+	"forward":         {"traces": {"traces"}, "metrics": {"metrics"}, "logs": {"logs"}},
+	"grafanacloud":    {"traces": {"metrics"}},
+	"roundrobin":      {"traces": {"traces"}, "metrics": {"metrics"}, "logs": {"logs"}},
+	"routing":         {"traces": {"traces"}, "metrics": {"metrics"}, "logs": {"logs"}},
+	"servicegraph":    {"traces": {"metrics"}},
+	"signaltometrics": {"traces": {"metrics"}, "metrics": {"metrics"}, "logs": {"metrics"}},
+	"spanmetrics":     {"traces": {"metrics"}},
+}
+
+// checkPipelineGraph validates the service graph similar to `otelcol validate`: all components referenced by the
+// pipelines are defined, defined components are used, pipelines are complete, components support the signal of the
+// pipelines they are used in and connectors connect two pipelines.
+func checkPipelineGraph(reporter *utils.ComponentReporter, c *Config) {
+	if len(c.Pipelines) == 0 {
+		reporter.AddError("service > pipelines on config.yaml has no pipelines. The collector needs at least one pipeline to start")
+		return
+	}
+
+	for _, p := range c.Pipelines {
+		checkPipeline(reporter, c, p)
+	}
+	checkUnused(reporter, c)
+	checkConnectors(reporter, c)
+
+	for _, id := range c.ServiceExtensions {
+		if _, ok := c.Component(KindExtension, id); !ok {
+			reporter.AddError(fmt.Sprintf("service > extensions on config.yaml references extension %s, which is not defined. Add it under extensions", id))
+		}
+	}
+}
+
+func checkPipeline(reporter *utils.ComponentReporter, c *Config, p Pipeline) {
+	signal := p.Signal()
+	if !slices.Contains(Signals, signal) {
+		reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml has the unknown signal %s. The name of a pipeline must start with %s", p.ID, signal, strings.Join(Signals, ", ")))
+		return
+	}
+	if len(p.Receivers) == 0 {
+		reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml has no receivers. Add at least one receiver, so that it receives data", p.ID))
+	}
+	if len(p.Exporters) == 0 {
+		reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml has no exporters. Add at least one exporter, so that data is sent somewhere", p.ID))
+	}
+
+	for _, id := range p.Receivers {
+		checkPipelineComponent(reporter, c, p, KindReceiver, "receiver", id)
+	}
+	for _, id := range p.Processors {
+		checkPipelineComponent(reporter, c, p, KindProcessor, "processor", id)
+	}
+	for _, id := range p.Exporters {
+		checkPipelineComponent(reporter, c, p, KindExporter, "exporter", id)
+	}
+}
+
+// checkPipelineComponent checks that a component used by a pipeline is defined and supports the signal of the
+// pipeline. Receivers and exporters can also be connectors.
+func checkPipelineComponent(reporter *utils.ComponentReporter, c *Config, p Pipeline, kind string, role string, id ComponentID) {
+	if _, ok := c.Component(kind, id); !ok {
+		if _, isConnector := c.Component(KindConnector, id); isConnector && kind != KindProcessor {
+			return
+		}
+		reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml uses %s %s, which is not defined. Add it under %s", p.ID, role, id, kind))
+		return
+	}
+	supported, known := componentSignals[kind][id.Type]
+	if known && !slices.Contains(supported, p.Signal()) {
+		reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml uses %s %s, which does not support %s. It supports %s", p.ID, role, id, p.Signal(), strings.Join(supported, ", ")))
+	}
+}
+
+// checkUnused reports components that are defined but not used by any pipeline, and extensions that are not enabled
+// in service > extensions
+func checkUnused(reporter *utils.ComponentReporter, c *Config) {
+	used := func(ids func(Pipeline) []ComponentID, id ComponentID) bool {
+		return slices.ContainsFunc(c.Pipelines, func(p Pipeline) bool { return slices.Contains(ids(p), id) })
+	}
+	receivers := func(p Pipeline) []ComponentID { return p.Receivers }
+	processors := func(p Pipeline) []ComponentID { return p.Processors }
+	exporters := func(p Pipeline) []ComponentID { return p.Exporters }
+
+	for _, r := range c.Receivers {
+		if !used(receivers, r.ID) {
+			reporter.AddWarning(fmt.Sprintf("Receiver %s is defined on config.yaml, but not used in any pipeline. The collector ignores it", r.ID))
+		}
+	}
+	for _, pr := range c.Processors {
+		if !used(processors, pr.ID) {
+			reporter.AddWarning(fmt.Sprintf("Processor %s is defined on config.yaml, but not used in any pipeline. The collector ignores it", pr.ID))
+		}
+	}
+	for _, e := range c.Exporters {
+		if !used(exporters, e.ID) {
+			reporter.AddWarning(fmt.Sprintf("Exporter %s is defined on config.yaml, but not used in any pipeline. The collector ignores it", e.ID))
+		}
+	}
+	for _, cn := range c.Connectors {
+		if !used(exporters, cn.ID) && !used(receivers, cn.ID) {
+			reporter.AddWarning(fmt.Sprintf("Connector %s is defined on config.yaml, but not used in any pipeline. The collector ignores it", cn.ID))
+		}
+	}
+	for _, e := range c.Extensions {
+		if !slices.Contains(c.ServiceExtensions, e.ID) {
+			reporter.AddWarning(fmt.Sprintf("Extension %s is defined on config.yaml, but not listed in service > extensions. The collector ignores it", e.ID))
+		}
+	}
+}
+
+// checkConnectors checks that every connector is used as the exporter of one pipeline and as the receiver of another,
+// and that it can convert the signal of the first pipeline into the signal of the second one
+func checkConnectors(reporter *utils.ComponentReporter, c *Config) {
+	for _, cn := range c.Connectors {
+		var inputs, outputs []Pipeline
+		for _, p := range c.Pipelines {
+			if !slices.Contains(Signals, p.Signal()) {
+				continue
+			}
+			if slices.Contains(p.Exporters, cn.ID) {
+				inputs = append(inputs, p)
+			}
+			if slices.Contains(p.Receivers, cn.ID) {
+				outputs = append(outputs, p)
+			}
+		}
+		switch {
+		case len(inputs) == 0 && len(outputs) == 0:
+			// reported as unused
+			continue
+		case len(outputs) == 0:
+			reporter.AddError(fmt.Sprintf("Connector %s is used as exporter in %s, but not as receiver in any pipeline on config.yaml. Add it to the receivers of the pipeline that should get its data", cn.ID, pipelineIDs(inputs)))
+			continue
+		case len(inputs) == 0:
+			reporter.AddError(fmt.Sprintf("Connector %s is used as receiver in %s, but not as exporter in any pipeline on config.yaml. Add it to the exporters of the pipeline that should send it data", cn.ID, pipelineIDs(outputs)))
+			continue
+		}
+
+		supported, known := connectorSignals[cn.ID.Type]
+		if !known {
+			continue
+		}
+		for _, in := range inputs {
+			if _, ok := supported[in.Signal()]; !ok {
+				reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml uses connector %s as exporter, but it does not consume %s", in.ID, cn.ID, in.Signal()))
+			}
+		}
+		for _, out := range outputs {
+			if !slices.ContainsFunc(inputs, func(in Pipeline) bool { return slices.Contains(supported[in.Signal()], out.Signal()) }) {
+				reporter.AddError(fmt.Sprintf("Pipeline %s on config.yaml uses connector %s as receiver, but it does not produce %s from the signals of %s", out.ID, cn.ID, out.Signal(), pipelineIDs(inputs)))
+			}
+		}
+	}
+}
+
+func pipelineIDs(pipelines []Pipeline) string {
+	var ids []string
+	for _, p := range pipelines {
+		ids = append(ids, p.ID.String())
+	}
+	return strings.Join(ids, ", ")
+}
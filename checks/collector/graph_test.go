@@ -0,0 +1,180 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPipelineGraph(t *testing.T) {
+	tests := []struct {
+		name             string
+		configYAML       string
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			name: "valid graph with connector",
+			configYAML: `
+receivers:
+  otlp:
+exporters:
+  otlphttp/grafana:
+connectors:
+  spanmetrics:
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp/grafana, spanmetrics]
+    metrics:
+      receivers: [otlp, spanmetrics]
+      exporters: [otlphttp/grafana]
+`,
+		},
+		{
+			name: "no pipelines",
+			configYAML: `
+receivers:
+  otlp:
+`,
+			expectedErrors: []string{
+				"collector: service > pipelines on config.yaml has no pipelines. The collector needs at least one pipeline to start",
+			},
+		},
+		{
+			name: "undefined and unused components",
+			configYAML: `
+receivers:
+  otlp:
+  zipkin:
+processors:
+  batch:
+exporters:
+  otlphttp:
+extensions:
+  pprof:
+service:
+  extensions: [health_check]
+  pipelines:
+    traces:
+      receivers: [otlp, jaeger]
+      processors: [memory_limiter]
+      exporters: [otlphttp/grafana]
+`,
+			expectedErrors: []string{
+				"collector: Pipeline traces on config.yaml uses receiver jaeger, which is not defined. Add it under receivers",
+				"collector: Pipeline traces on config.yaml uses processor memory_limiter, which is not defined. Add it under processors",
+				"collector: Pipeline traces on config.yaml uses exporter otlphttp/grafana, which is not defined. Add it under exporters",
+				"collector: service > extensions on config.yaml references extension health_check, which is not defined. Add it under extensions",
+			},
+			expectedWarnings: []string{
+				"collector: Receiver zipkin is defined on config.yaml, but not used in any pipeline. The collector ignores it",
+				"collector: Processor batch is defined on config.yaml, but not used in any pipeline. The collector ignores it",
+				"collector: Exporter otlphttp is defined on config.yaml, but not used in any pipeline. The collector ignores it",
+				"collector: Extension pprof is defined on config.yaml, but not listed in service > extensions. The collector ignores it",
+			},
+		},
+		{
+			name: "empty pipelines and unknown signal",
+			configYAML: `
+receivers:
+  otlp:
+exporters:
+  otlphttp:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+    logs:
+      exporters: [otlphttp]
+    spans:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+			expectedErrors: []string{
+				"collector: Pipeline logs on config.yaml has no receivers. Add at least one receiver, so that it receives data",
+				"collector: Pipeline traces on config.yaml has no exporters. Add at least one exporter, so that data is sent somewhere",
+				"collector: Pipeline spans on config.yaml has the unknown signal spans. The name of a pipeline must start with traces, metrics, logs",
+			},
+		},
+		{
+			name: "signal mismatch",
+			configYAML: `
+receivers:
+  prometheus:
+  filelog:
+processors:
+  tail_sampling:
+exporters:
+  loki:
+  prometheusremotewrite:
+service:
+  pipelines:
+    metrics:
+      receivers: [prometheus]
+      processors: [tail_sampling]
+      exporters: [loki]
+    logs:
+      receivers: [filelog]
+      exporters: [prometheusremotewrite]
+`,
+			expectedErrors: []string{
+				"collector: Pipeline metrics on config.yaml uses processor tail_sampling, which does not support metrics. It supports traces",
+				"collector: Pipeline metrics on config.yaml uses exporter loki, which does not support metrics. It supports logs",
+				"collector: Pipeline logs on config.yaml uses exporter prometheusremotewrite, which does not support logs. It supports metrics",
+			},
+		},
+		{
+			name: "connectors used on one side only or with the wrong signal",
+			configYAML: `
+receivers:
+  otlp:
+exporters:
+  otlphttp:
+connectors:
+  spanmetrics:
+  servicegraph:
+  forward:
+  count:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [spanmetrics, count]
+    logs:
+      receivers: [otlp, servicegraph, count]
+      exporters: [otlphttp, forward]
+    metrics:
+      receivers: [otlp]
+      exporters: [otlphttp, servicegraph]
+`,
+			expectedErrors: []string{
+				"collector: Connector spanmetrics is used as exporter in traces, but not as receiver in any pipeline on config.yaml. Add it to the receivers of the pipeline that should get its data",
+				"collector: Connector forward is used as exporter in logs, but not as receiver in any pipeline on config.yaml. Add it to the receivers of the pipeline that should get its data",
+				"collector: Pipeline metrics on config.yaml uses connector servicegraph as exporter, but it does not consume metrics",
+				"collector: Pipeline logs on config.yaml uses connector servicegraph as receiver, but it does not produce logs from the signals of metrics",
+				"collector: Pipeline logs on config.yaml uses connector count as receiver, but it does not produce logs from the signals of traces",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(tt.configYAML))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			componentReporter := reporter.Component("collector")
+			checkPipelineGraph(componentReporter, c)
+
+			assert.ElementsMatch(t, tt.expectedErrors, componentReporter.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, componentReporter.Warnings, "warnings mismatch")
+		})
+	}
+}
@@ -0,0 +1,358 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/network"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+const (
+	defaultHealthCheckPort = "13133"
+	defaultTelemetryPort   = "8888"
+	// queueFullRatio is the fill level of a sending queue from which the queue is reported as near capacity
+	queueFullRatio = 0.8
+)
+
+// probeEndpoints are the URLs of a running collector that are probed
+type probeEndpoints struct {
+	// Health is the URL of the health_check extension, empty if the extension is not enabled
+	Health string
+	// Metrics is the URL of the Prometheus endpoint of the internal telemetry, empty if it is disabled
+	Metrics string
+}
+
+// collectorEndpoints returns the URLs of the health_check extension and the internal telemetry on the host of
+// collectorURL. The ports and paths are taken from the config, or the defaults of the collector if the config is nil.
+func collectorEndpoints(reporter *utils.ComponentReporter, collectorURL string, c *Config) (probeEndpoints, error) {
+	if !strings.Contains(collectorURL, "://") {
+		collectorURL = "http://" + collectorURL
+	}
+	u, err := url.Parse(collectorURL)
+	if err != nil || u.Hostname() == "" {
+		return probeEndpoints{}, fmt.Errorf("value of -collector-url %s is not a valid URL. Use the host of the collector, e.g. http://localhost", collectorURL)
+	}
+	endpointURL := func(port, path string) string {
+		return (&url.URL{Scheme: u.Scheme, Host: net.JoinHostPort(u.Hostname(), port), Path: path}).String()
+	}
+
+	var endpoints probeEndpoints
+	healthPort, healthPath := defaultHealthCheckPort, "/"
+	healthEnabled := true
+	if c != nil {
+		healthEnabled = false
+		for _, id := range c.ServiceExtensions {
+			e, ok := c.Component(KindExtension, id)
+			if !ok || id.Type != "health_check" {
+				continue
+			}
+			healthEnabled = true
+			healthPort = portOf(e.StringValue("endpoint"), healthPort)
+			if path := e.StringValue("path"); path != "" {
+				healthPath = path
+			}
+			break
+		}
+	}
+	if healthEnabled {
+		endpoints.Health = endpointURL(healthPort, healthPath)
+	} else {
+		reporter.AddWarning("Extension health_check is not enabled on config.yaml, so the health of the collector can't be checked. Add it under extensions and service > extensions")
+	}
+
+	metricsPort, metricsEnabled := telemetryPort(c)
+	if metricsEnabled {
+		endpoints.Metrics = endpointURL(metricsPort, "/metrics")
+	} else {
+		reporter.AddWarning("Value of service > telemetry > metrics > level on config.yaml is none, so failed and refused data can't be checked. Set it to basic or normal")
+	}
+	return endpoints, nil
+}
+
+// telemetryPort returns the port of the Prometheus endpoint of the internal telemetry and whether it is enabled. The
+// port is configured in metrics > readers since collector v0.111.0 and in metrics > address before.
+func telemetryPort(c *Config) (string, bool) {
+	if c == nil {
+		return defaultTelemetryPort, true
+	}
+	telemetry := Component{Config: c.Telemetry}
+	if telemetry.StringValue("metrics", "level") == "none" {
+		return "", false
+	}
+	readers, _ := telemetry.Value("metrics", "readers")
+	list, _ := readers.([]any)
+	for _, r := range list {
+		m, _ := r.(map[string]any)
+		reader := Component{Config: m}
+		if port, ok := reader.Value("pull", "exporter", "prometheus", "port"); ok {
+			return fmt.Sprint(port), true
+		}
+	}
+	return portOf(telemetry.StringValue("metrics", "address"), defaultTelemetryPort), true
+}
+
+// portOf returns the port of an endpoint such as 0.0.0.0:13133, or the default port
+func portOf(endpoint string, defaultPort string) string {
+	if _, port, err := net.SplitHostPort(endpoint); err == nil && port != "" {
+		return port
+	}
+	return defaultPort
+}
+
+// checkRunningCollector probes the collector on the host of collectorURL. The config is used to find the ports and
+// can be nil.
+func checkRunningCollector(reporter *utils.ComponentReporter, collectorURL string, c *Config) {
+	endpoints, err := collectorEndpoints(reporter, collectorURL, c)
+	if err != nil {
+		reporter.AddError(utils.Capitalize(err.Error()))
+		return
+	}
+	probeCollector(reporter, network.NewClient(nil, 5*time.Second), endpoints)
+}
+
+func probeCollector(reporter *utils.ComponentReporter, client *http.Client, endpoints probeEndpoints) {
+	if endpoints.Health != "" {
+		checkHealth(reporter, client, endpoints.Health)
+	}
+	if endpoints.Metrics != "" {
+		checkInternalMetrics(reporter, client, endpoints.Metrics)
+	}
+}
+
+// checkHealth checks the status of the health_check extension, which responds with 200 when the collector is ready
+// and 503 otherwise
+func checkHealth(reporter *utils.ComponentReporter, client *http.Client, healthURL string) {
+	resp, err := client.Get(healthURL)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Health check of the collector at %s failed: %s. Make sure the collector is running and the health_check extension is reachable", healthURL, err))
+		return
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Collector at %s is healthy", healthURL))
+	case http.StatusServiceUnavailable:
+		reporter.AddError(fmt.Sprintf("Collector at %s is not healthy. Check the logs of the collector for the reason", healthURL))
+	default:
+		reporter.AddError(fmt.Sprintf("Health check of the collector at %s returned %s. Make sure the URL points to the health_check extension", healthURL, resp.Status))
+	}
+}
+
+// sample is a sample of a metric in the Prometheus text format
+type sample struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// parseMetrics parses the Prometheus text format. Comments and lines that can't be parsed are skipped.
+func parseMetrics(r io.Reader) ([]sample, error) {
+	var samples []sample
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		s := sample{Labels: map[string]string{}}
+		rest := line
+		if i := strings.IndexAny(line, "{ "); i >= 0 && line[i] == '{' {
+			end := strings.LastIndex(line, "}")
+			if end < i {
+				continue
+			}
+			s.Name = line[:i]
+			s.Labels = parseLabels(line[i+1 : end])
+			rest = line[end+1:]
+		} else {
+			name, value, ok := strings.Cut(line, " ")
+			if !ok {
+				continue
+			}
+			s.Name, rest = name, value
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			continue
+		}
+		s.Value = value
+		samples = append(samples, s)
+	}
+	return samples, scanner.Err()
+}
+
+func parseLabels(s string) map[string]string {
+	labels := map[string]string{}
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		rest = strings.TrimPrefix(rest, `"`)
+		var value strings.Builder
+		i := 0
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+			}
+			value.WriteByte(rest[i])
+		}
+		labels[strings.TrimSpace(name)] = value.String()
+		s = strings.TrimPrefix(strings.TrimSpace(rest[min(i+1, len(rest)):]), ",")
+	}
+	return labels
+}
+
+// failureMetric is a counter of the internal telemetry that counts data the collector lost or refused
+type failureMetric struct {
+	// prefix of the metric name, the suffix is the signal and _total since collector v0.106.0
+	prefix string
+	// label is the label with the component id
+	label string
+	// message is the finding for a component, with the component, the number of items and the signal
+	message string
+	// warning reports the finding as warning instead of error
+	warning bool
+}
+
+var failureMetrics = []failureMetric{
+	{
+		prefix:  "otelcol_exporter_send_failed_",
+		label:   "exporter",
+		message: "Exporter %s failed to send %s %s since the collector started. Check the logs of the collector for the reason, e.g. rejected credentials or an unreachable endpoint",
+	},
+	{
+		prefix:  "otelcol_receiver_refused_",
+		label:   "receiver",
+		message: "Receiver %s refused %s %s since the collector started. The data is lost unless the sender retries. Check the logs of the collector for the reason",
+	},
+	{
+		prefix:  "otelcol_exporter_enqueue_failed_",
+		label:   "exporter",
+		message: "Exporter %s dropped %s %s since the collector started, because the sending queue was full. Increase sending_queue > queue_size or the throughput of the backend",
+	},
+	{
+		prefix:  "otelcol_processor_refused_",
+		label:   "processor",
+		message: "Processor %s refused %s %s since the collector started, because the collector was close to its memory limit. Give the collector more memory or scale it out",
+		warning: true,
+	},
+}
+
+// signalItems are the suffixes of the failure metrics, with how the items are called in the findings
+var signalItems = map[string]string{
+	"spans":         "spans",
+	"metric_points": "metric points",
+	"log_records":   "log records",
+}
+
+// checkInternalMetrics scrapes the internal telemetry of the collector and reports lost and refused data and full
+// sending queues
+func checkInternalMetrics(reporter *utils.ComponentReporter, client *http.Client, metricsURL string) {
+	resp, err := client.Get(metricsURL)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Internal telemetry of the collector at %s can't be scraped: %s. Make sure the collector is running and service > telemetry > metrics is reachable", metricsURL, err))
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		reporter.AddError(fmt.Sprintf("Internal telemetry of the collector at %s returned %s", metricsURL, resp.Status))
+		return
+	}
+	samples, err := parseMetrics(resp.Body)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Internal telemetry of the collector at %s can't be read: %s", metricsURL, err))
+		return
+	}
+	if !slices.ContainsFunc(samples, func(s sample) bool { return strings.HasPrefix(s.Name, "otelcol_") }) {
+		reporter.AddWarning(fmt.Sprintf("%s has no otelcol_ metrics. Make sure it is the internal telemetry of the collector", metricsURL))
+		return
+	}
+
+	findings := 0
+	for _, metric := range failureMetrics {
+		for _, f := range sumFailures(samples, metric) {
+			findings++
+			message := fmt.Sprintf(metric.message, f.component, strconv.FormatFloat(f.value, 'f', -1, 64), signalItems[f.signal])
+			if metric.warning {
+				reporter.AddWarning(message)
+			} else {
+				reporter.AddError(message)
+			}
+		}
+	}
+	findings += checkQueues(reporter, samples)
+
+	if findings == 0 {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Internal telemetry of the collector at %s reports no failed or refused data", metricsURL))
+	}
+}
+
+type failure struct {
+	component string
+	signal    string
+	value     float64
+}
+
+// sumFailures sums the samples of a failure metric per component and signal, e.g. over the transports of a receiver.
+// Memory limiter refusals are the only processor refusals reported.
+func sumFailures(samples []sample, metric failureMetric) []failure {
+	sums := map[[2]string]float64{}
+	for _, s := range samples {
+		signal, ok := strings.CutPrefix(strings.TrimSuffix(s.Name, "_total"), metric.prefix)
+		if !ok || signalItems[signal] == "" || s.Value <= 0 {
+			continue
+		}
+		component := s.Labels[metric.label]
+		if metric.label == "processor" && !strings.HasPrefix(component, "memory_limiter") {
+			continue
+		}
+		sums[[2]string{component, signal}] += s.Value
+	}
+	var failures []failure
+	for key, value := range sums {
+		failures = append(failures, failure{component: key[0], signal: key[1], value: value})
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].component != failures[j].component {
+			return failures[i].component < failures[j].component
+		}
+		return failures[i].signal < failures[j].signal
+	})
+	return failures
+}
+
+// checkQueues reports sending queues that are filled to queueFullRatio of their capacity and returns the number of
+// findings
+func checkQueues(reporter *utils.ComponentReporter, samples []sample) int {
+	capacity := map[string]float64{}
+	for _, s := range samples {
+		if s.Name == "otelcol_exporter_queue_capacity" {
+			capacity[s.Labels["exporter"]] = s.Value
+		}
+	}
+	findings := 0
+	for _, s := range samples {
+		exporter := s.Labels["exporter"]
+		if s.Name != "otelcol_exporter_queue_size" || capacity[exporter] <= 0 || s.Value < queueFullRatio*capacity[exporter] {
+			continue
+		}
+		findings++
+		reporter.AddWarning(fmt.Sprintf("Sending queue of exporter %s is near capacity (%s of %s batches). Data is dropped when it is full. Check that the backend is reachable and keeps up, or increase sending_queue > queue_size", exporter, strconv.FormatFloat(s.Value, 'f', -1, 64), strconv.FormatFloat(capacity[exporter], 'f', -1, 64)))
+	}
+	return findings
+}
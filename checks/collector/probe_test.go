@@ -0,0 +1,210 @@
+package collector
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const healthyMetrics = `# HELP otelcol_exporter_sent_spans_total Number of spans successfully sent to destination.
+# TYPE otelcol_exporter_sent_spans_total counter
+otelcol_exporter_sent_spans_total{exporter="otlphttp/grafana",service_instance_id="a1",service_name="otelcol-contrib"} 1520
+otelcol_exporter_send_failed_spans_total{exporter="otlphttp/grafana",service_instance_id="a1",service_name="otelcol-contrib"} 0
+otelcol_exporter_queue_capacity{data_type="traces",exporter="otlphttp/grafana"} 1000
+otelcol_exporter_queue_size{data_type="traces",exporter="otlphttp/grafana"} 3
+otelcol_receiver_accepted_spans_total{receiver="otlp",transport="grpc"} 1520
+otelcol_receiver_refused_spans_total{receiver="otlp",transport="grpc"} 0
+`
+
+const failingMetrics = `# HELP otelcol_exporter_send_failed_spans Number of spans in failed attempts to send to destination.
+# TYPE otelcol_exporter_send_failed_spans counter
+otelcol_exporter_send_failed_spans{exporter="otlphttp/grafana",service_name="otelcol-contrib"} 42
+otelcol_exporter_send_failed_log_records{exporter="otlphttp/grafana",service_name="otelcol-contrib"} 7
+otelcol_exporter_enqueue_failed_metric_points{exporter="otlphttp/grafana"} 100
+otelcol_exporter_queue_capacity{data_type="traces",exporter="otlphttp/grafana"} 1000
+otelcol_exporter_queue_size{data_type="traces",exporter="otlphttp/grafana"} 950
+otelcol_receiver_refused_spans{receiver="otlp",transport="grpc"} 10
+otelcol_receiver_refused_spans{receiver="otlp",transport="http"} 5
+otelcol_processor_refused_spans{processor="memory_limiter"} 15
+otelcol_processor_dropped_spans{processor="filter"} 3
+`
+
+func TestProbeCollector(t *testing.T) {
+	tests := []struct {
+		name             string
+		healthStatus     int
+		metrics          string
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name:         "healthy collector",
+			healthStatus: http.StatusOK,
+			metrics:      healthyMetrics,
+			expectedChecks: []string{
+				"collector: Collector at URL/ is healthy",
+				"collector: Internal telemetry of the collector at URL/metrics reports no failed or refused data",
+			},
+		},
+		{
+			name:         "collector losing data",
+			healthStatus: http.StatusServiceUnavailable,
+			metrics:      failingMetrics,
+			expectedErrors: []string{
+				"collector: Collector at URL/ is not healthy. Check the logs of the collector for the reason",
+				"collector: Exporter otlphttp/grafana failed to send 7 log records since the collector started. Check the logs of the collector for the reason, e.g. rejected credentials or an unreachable endpoint",
+				"collector: Exporter otlphttp/grafana failed to send 42 spans since the collector started. Check the logs of the collector for the reason, e.g. rejected credentials or an unreachable endpoint",
+				"collector: Receiver otlp refused 15 spans since the collector started. The data is lost unless the sender retries. Check the logs of the collector for the reason",
+				"collector: Exporter otlphttp/grafana dropped 100 metric points since the collector started, because the sending queue was full. Increase sending_queue > queue_size or the throughput of the backend",
+			},
+			expectedWarnings: []string{
+				"collector: Processor memory_limiter refused 15 spans since the collector started, because the collector was close to its memory limit. Give the collector more memory or scale it out",
+				"collector: Sending queue of exporter otlphttp/grafana is near capacity (950 of 1000 batches). Data is dropped when it is full. Check that the backend is reachable and keeps up, or increase sending_queue > queue_size",
+			},
+		},
+		{
+			name:         "not the telemetry of a collector",
+			healthStatus: http.StatusOK,
+			metrics:      "go_goroutines 12\n",
+			expectedChecks: []string{
+				"collector: Collector at URL/ is healthy",
+			},
+			expectedWarnings: []string{
+				"collector: URL/metrics has no otelcol_ metrics. Make sure it is the internal telemetry of the collector",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/metrics" {
+					_, _ = w.Write([]byte(tt.metrics))
+					return
+				}
+				w.WriteHeader(tt.healthStatus)
+			}))
+			defer server.Close()
+
+			reporter := utils.Reporter{}
+			c := reporter.Component("collector")
+			probeCollector(c, server.Client(), probeEndpoints{Health: server.URL + "/", Metrics: server.URL + "/metrics"})
+
+			replaceURL := func(messages []string) []string {
+				var result []string
+				for _, m := range messages {
+					result = append(result, strings.ReplaceAll(m, server.URL, "URL"))
+				}
+				return result
+			}
+			assert.ElementsMatch(t, tt.expectedErrors, replaceURL(c.Errors))
+			assert.ElementsMatch(t, tt.expectedWarnings, replaceURL(c.Warnings))
+			assert.ElementsMatch(t, tt.expectedChecks, replaceURL(c.Checks))
+		})
+	}
+}
+
+func TestProbeCollectorUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	reporter := utils.Reporter{}
+	c := reporter.Component("collector")
+	probeCollector(c, http.DefaultClient, probeEndpoints{Health: url + "/", Metrics: url + "/metrics"})
+
+	require.Len(t, c.Errors, 2)
+	assert.Contains(t, c.Errors[0], "collector: Health check of the collector at "+url+"/ failed")
+	assert.Contains(t, c.Errors[1], "collector: Internal telemetry of the collector at "+url+"/metrics can't be scraped")
+}
+
+func TestCollectorEndpoints(t *testing.T) {
+	tests := []struct {
+		name             string
+		collectorURL     string
+		configYAML       string
+		expected         probeEndpoints
+		expectedWarnings []string
+	}{
+		{
+			name:         "defaults without config",
+			collectorURL: "localhost",
+			expected:     probeEndpoints{Health: "http://localhost:13133/", Metrics: "http://localhost:8888/metrics"},
+		},
+		{
+			name:         "ports from the config",
+			collectorURL: "http://otel-collector:4318",
+			configYAML: `
+extensions:
+  health_check:
+    endpoint: 0.0.0.0:13134
+    path: /health/status
+service:
+  extensions: [health_check]
+  telemetry:
+    metrics:
+      readers:
+        - pull:
+            exporter:
+              prometheus:
+                host: 0.0.0.0
+                port: 9464
+`,
+			expected: probeEndpoints{Health: "http://otel-collector:13134/health/status", Metrics: "http://otel-collector:9464/metrics"},
+		},
+		{
+			name:         "address of older collectors",
+			collectorURL: "http://otel-collector",
+			configYAML: `
+extensions:
+  health_check:
+service:
+  extensions: [health_check]
+  telemetry:
+    metrics:
+      address: 0.0.0.0:8889
+`,
+			expected: probeEndpoints{Health: "http://otel-collector:13133/", Metrics: "http://otel-collector:8889/metrics"},
+		},
+		{
+			name:         "health_check and telemetry disabled",
+			collectorURL: "http://otel-collector",
+			configYAML: `
+extensions:
+  health_check:
+service:
+  telemetry:
+    metrics:
+      level: none
+`,
+			expectedWarnings: []string{
+				"collector: Extension health_check is not enabled on config.yaml, so the health of the collector can't be checked. Add it under extensions and service > extensions",
+				"collector: Value of service > telemetry > metrics > level on config.yaml is none, so failed and refused data can't be checked. Set it to basic or normal",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config *Config
+			if tt.configYAML != "" {
+				var err error
+				config, err = ParseConfig([]byte(tt.configYAML))
+				require.NoError(t, err)
+			}
+
+			reporter := utils.Reporter{}
+			c := reporter.Component("collector")
+			endpoints, err := collectorEndpoints(c, tt.collectorURL, config)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, endpoints)
+			assert.ElementsMatch(t, tt.expectedWarnings, c.Warnings)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// processorRule is a best practice for the processors of a pipeline, with a link to the documentation and, where
+// useful, an example configuration in YAML flow style, so that the finding stays on one line
+type processorRule struct {
+	docs    string
+	snippet string
+}
+
+func (r processorRule) message(text string) string {
+	if r.snippet == "" {
+		return fmt.Sprintf("%s. See %s", text, r.docs)
+	}
+	return fmt.Sprintf("%s. Example: %s. See %s", text, r.snippet, r.docs)
+}
+
+var (
+	memoryLimiterRule = processorRule{
+		docs:    "https://github.com/open-telemetry/opentelemetry-collector/tree/main/processor/memorylimiterprocessor",
+		snippet: "processors: {memory_limiter: {check_interval: 1s, limit_percentage: 80, spike_limit_percentage: 25}}",
+	}
+	batchRule = processorRule{
+		docs:    "https://github.com/open-telemetry/opentelemetry-collector/tree/main/processor/batchprocessor",
+		snippet: "processors: {batch: {}}",
+	}
+	k8sAttributesRule = processorRule{
+		docs:    "https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/processor/k8sattributesprocessor",
+		snippet: "processors: {k8sattributes: {extract: {metadata: [k8s.namespace.name, k8s.pod.name, k8s.deployment.name, k8s.node.name]}}}",
+	}
+	resourceDetectionRule = processorRule{
+		docs:    "https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/processor/resourcedetectionprocessor",
+		snippet: "processors: {resourcedetection: {detectors: [env, k8snode]}}",
+	}
+	tailSamplingRule = processorRule{
+		docs:    "https://github.com/open-telemetry/opentelemetry-collector-contrib/tree/main/exporter/loadbalancingexporter",
+		snippet: "exporters: {loadbalancing: {routing_key: traceID, protocol: {otlp: {tls: {insecure: true}}}, resolver: {dns: {hostname: otel-sampler-headless}}}}",
+	}
+)
+
+// processorsBeforeBatch are processors that should run before batch, because they drop data, need the context of the
+// incoming request or protect the collector
+var processorsBeforeBatch = []string{
+	"memory_limiter", "k8sattributes", "resourcedetection", "filter", "probabilistic_sampler", "tail_sampling", "groupbytrace",
+}
+
+// kubernetesComponents are components that are only used when the collector runs on Kubernetes
+var kubernetesComponents = []string{"k8s_cluster", "k8s_events", "k8sobjects", "kubeletstats", "k8s_observer", "k8sattributes"}
+
+// checkProcessors checks the processors of the pipelines that receive data from outside the collector against best
+// practices. Pipelines that only receive from connectors already got their data processed by another pipeline.
+func checkProcessors(reporter *utils.ComponentReporter, c *Config, onKubernetes bool) {
+	pipelines := externalPipelines(c)
+
+	var noMemoryLimiter, memoryLimiterNotFirst, noBatch, batchTooEarly, noK8sAttributes, noResourceDetection []Pipeline
+	for _, p := range pipelines {
+		types := processorTypes(p)
+		switch i := slices.Index(types, "memory_limiter"); {
+		case i < 0:
+			noMemoryLimiter = append(noMemoryLimiter, p)
+		case i > 0:
+			memoryLimiterNotFirst = append(memoryLimiterNotFirst, p)
+		}
+		if i := slices.Index(types, "batch"); i < 0 {
+			noBatch = append(noBatch, p)
+		} else if slices.ContainsFunc(types[i+1:], func(t string) bool { return slices.Contains(processorsBeforeBatch, t) }) {
+			batchTooEarly = append(batchTooEarly, p)
+		}
+		if onKubernetes && !slices.Contains(types, "k8sattributes") {
+			noK8sAttributes = append(noK8sAttributes, p)
+		}
+		if onKubernetes && !slices.Contains(types, "resourcedetection") {
+			noResourceDetection = append(noResourceDetection, p)
+		}
+	}
+
+	if len(noMemoryLimiter) > 0 {
+		reporter.AddWarning(memoryLimiterRule.message(fmt.Sprintf("%s no memory_limiter processor. Add it as the first processor, so that the collector refuses data instead of running out of memory", pipelinesSubject(noMemoryLimiter))))
+	}
+	if len(memoryLimiterNotFirst) > 0 {
+		reporter.AddWarning(memoryLimiterRule.message(fmt.Sprintf("%s processors before memory_limiter. Move memory_limiter to the beginning of the processors, so that it can refuse data before other processors use memory", pipelinesSubject(memoryLimiterNotFirst))))
+	}
+	if len(noBatch) > 0 {
+		reporter.AddWarning(batchRule.message(fmt.Sprintf("%s no batch processor. Add it to the end of the processors to compress data better and reduce the number of requests", pipelinesSubject(noBatch))))
+	}
+	if len(batchTooEarly) > 0 {
+		reporter.AddWarning(batchRule.message(fmt.Sprintf("%s processors after batch that should run before it (%s). Move batch to the end of the processors", pipelinesSubject(batchTooEarly), strings.Join(processorsBeforeBatch, ", "))))
+	}
+	if len(noK8sAttributes) > 0 {
+		reporter.AddWarning(k8sAttributesRule.message(fmt.Sprintf("%s no k8sattributes processor. It is recommended on Kubernetes to add the pod, namespace and workload to the telemetry", pipelinesSubject(noK8sAttributes))))
+	}
+	if len(noResourceDetection) > 0 {
+		reporter.AddWarning(resourceDetectionRule.message(fmt.Sprintf("%s no resourcedetection processor. It is recommended on Kubernetes to add the node and cluster to the telemetry", pipelinesSubject(noResourceDetection))))
+	}
+
+	checkTailSampling(reporter, c)
+}
+
+// externalPipelines returns the pipelines that receive data from outside the collector, i.e. from at least one
+// receiver that is not a connector
+func externalPipelines(c *Config) []Pipeline {
+	var pipelines []Pipeline
+	for _, p := range c.Pipelines {
+		if !slices.Contains(Signals, p.Signal()) {
+			continue
+		}
+		if slices.ContainsFunc(p.Receivers, func(id ComponentID) bool {
+			_, isConnector := c.Component(KindConnector, id)
+			return !isConnector
+		}) {
+			pipelines = append(pipelines, p)
+		}
+	}
+	return pipelines
+}
+
+// checkTailSampling checks that tail sampling gets all spans of a trace, which needs a loadbalancing exporter that
+// routes by trace id in front of the collectors that sample
+func checkTailSampling(reporter *utils.ComponentReporter, c *Config) {
+	var sampling []Pipeline
+	for _, p := range c.Pipelines {
+		if slices.Contains(processorTypes(p), "tail_sampling") {
+			sampling = append(sampling, p)
+		}
+	}
+	if len(sampling) == 0 {
+		return
+	}
+
+	for _, p := range sampling {
+		for _, id := range p.Exporters {
+			if id.Type == "loadbalancing" {
+				reporter.AddWarning(tailSamplingRule.message(fmt.Sprintf("Pipeline %s on config.yaml samples with tail_sampling before exporter %s load-balances the traces. Sample in the pipeline that receives the load-balanced traces instead", p.ID, id)))
+			}
+		}
+	}
+
+	found := false
+	for _, e := range c.UsedExporters() {
+		if e.ID.Type != "loadbalancing" {
+			continue
+		}
+		found = true
+		if key := e.StringValue("routing_key"); key != "" && key != "traceID" {
+			reporter.AddWarning(tailSamplingRule.message(fmt.Sprintf("Exporter %s on config.yaml routes by %s, but tail_sampling needs all spans of a trace in the same collector. Set routing_key to traceID", e.ID, key)))
+		}
+	}
+	if found {
+		return
+	}
+	reporter.AddWarning(tailSamplingRule.message(fmt.Sprintf("%s a tail_sampling processor, but no loadbalancing exporter routes by trace id. Unless the collector runs as a single instance, spans of a trace end up in different collectors and are sampled independently", pipelinesSubject(sampling))))
+}
+
+// onKubernetes returns true if the collector runs on Kubernetes: either otel-checker runs in a pod or the config
+// uses Kubernetes components
+func onKubernetes(c *Config, getenv func(string) string) bool {
+	if getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return true
+	}
+	for _, components := range [][]Component{c.Receivers, c.Processors, c.Extensions} {
+		if slices.ContainsFunc(components, func(component Component) bool {
+			return slices.Contains(kubernetesComponents, component.ID.Type)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+func processorTypes(p Pipeline) []string {
+	var types []string
+	for _, id := range p.Processors {
+		types = append(types, id.Type)
+	}
+	return types
+}
+
+// pipelinesSubject returns the start of a sentence about the pipelines, e.g. "Pipelines traces, logs on config.yaml have"
+func pipelinesSubject(pipelines []Pipeline) string {
+	if len(pipelines) == 1 {
+		return fmt.Sprintf("Pipeline %s on config.yaml has", pipelines[0].ID)
+	}
+	return fmt.Sprintf("Pipelines %s on config.yaml have", pipelineIDs(pipelines))
+}
@@ -0,0 +1,192 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckProcessors(t *testing.T) {
+	tests := []struct {
+		name             string
+		configYAML       string
+		onKubernetes     bool
+		expectedWarnings []string
+	}{
+		{
+			name: "recommended order",
+			configYAML: `
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, resourcedetection, k8sattributes, transform, batch]
+      exporters: [otlphttp]
+`,
+			onKubernetes: true,
+		},
+		{
+			name: "missing and misplaced processors",
+			configYAML: `
+connectors:
+  spanmetrics:
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch, memory_limiter]
+      exporters: [otlphttp]
+    metrics:
+      receivers: [otlp]
+      exporters: [otlphttp]
+    metrics/spanmetrics:
+      receivers: [spanmetrics]
+      exporters: [otlphttp]
+`,
+			expectedWarnings: []string{
+				"Pipeline metrics on config.yaml has no memory_limiter processor",
+				"Pipeline traces on config.yaml has processors before memory_limiter",
+				"Pipeline metrics on config.yaml has no batch processor",
+				"Pipeline traces on config.yaml has processors after batch that should run before it (memory_limiter, k8sattributes, resourcedetection, filter, probabilistic_sampler, tail_sampling, groupbytrace)",
+			},
+		},
+		{
+			name: "kubernetes",
+			configYAML: `
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, k8sattributes, batch]
+      exporters: [otlphttp]
+    logs:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [otlphttp]
+`,
+			onKubernetes: true,
+			expectedWarnings: []string{
+				"Pipeline logs on config.yaml has no k8sattributes processor",
+				"Pipelines logs, traces on config.yaml have no resourcedetection processor",
+			},
+		},
+		{
+			name: "tail sampling without load balancing",
+			configYAML: `
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, tail_sampling, batch]
+      exporters: [otlphttp]
+`,
+			expectedWarnings: []string{
+				"Pipeline traces on config.yaml has a tail_sampling processor, but no loadbalancing exporter routes by trace id",
+			},
+		},
+		{
+			name: "tail sampling behind load balancing by trace id",
+			configYAML: `
+exporters:
+  loadbalancing:
+service:
+  pipelines:
+    traces/loadbalancing:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [loadbalancing]
+    traces:
+      receivers: [otlp/loadbalanced]
+      processors: [memory_limiter, tail_sampling, batch]
+      exporters: [otlphttp]
+`,
+		},
+		{
+			name: "tail sampling before load balancing by service",
+			configYAML: `
+exporters:
+  loadbalancing:
+    routing_key: service
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [memory_limiter, tail_sampling, batch]
+      exporters: [loadbalancing]
+`,
+			expectedWarnings: []string{
+				"Pipeline traces on config.yaml samples with tail_sampling before exporter loadbalancing load-balances the traces",
+				"Exporter loadbalancing on config.yaml routes by service, but tail_sampling needs all spans of a trace in the same collector",
+			},
+		},
+		{
+			name: "second load balancing exporter by service",
+			configYAML: `
+exporters:
+  loadbalancing:
+    routing_key: traceID
+  loadbalancing/service:
+    routing_key: service
+service:
+  pipelines:
+    traces/loadbalancing:
+      receivers: [otlp]
+      processors: [memory_limiter, batch]
+      exporters: [loadbalancing, loadbalancing/service]
+    traces:
+      receivers: [otlp/loadbalanced]
+      processors: [memory_limiter, tail_sampling, batch]
+      exporters: [otlphttp]
+`,
+			expectedWarnings: []string{
+				"Exporter loadbalancing/service on config.yaml routes by service, but tail_sampling needs all spans of a trace in the same collector",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(tt.configYAML))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			componentReporter := reporter.Component("collector")
+			checkProcessors(componentReporter, c, tt.onKubernetes)
+
+			require.Len(t, componentReporter.Warnings, len(tt.expectedWarnings), "warnings: %v", componentReporter.Warnings)
+			for i, expected := range tt.expectedWarnings {
+				assert.True(t, strings.HasPrefix(componentReporter.Warnings[i], "collector: "+expected), "warning %q should start with %q", componentReporter.Warnings[i], expected)
+				assert.Contains(t, componentReporter.Warnings[i], ". See https://github.com/open-telemetry/")
+			}
+		})
+	}
+}
+
+func TestProcessorRuleMessage(t *testing.T) {
+	assert.Equal(t,
+		"Pipeline traces on config.yaml has no batch processor. Example: processors: {batch: {}}. See https://github.com/open-telemetry/opentelemetry-collector/tree/main/processor/batchprocessor",
+		batchRule.message("Pipeline traces on config.yaml has no batch processor"))
+	assert.Equal(t,
+		"Processor resource on config.yaml deletes attribute service.name. See https://grafana.com/docs/grafana-cloud/monitor-applications/application-observability/setup/resource-attributes/",
+		resourceAttributesRule.message("Processor resource on config.yaml deletes attribute service.name"))
+}
+
+func TestOnKubernetes(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	c, err := ParseConfig([]byte("receivers:\n  otlp:\n"))
+	require.NoError(t, err)
+	assert.False(t, onKubernetes(c, noEnv))
+	assert.True(t, onKubernetes(c, func(name string) string {
+		if name == "KUBERNETES_SERVICE_HOST" {
+			return "10.0.0.1"
+		}
+		return ""
+	}))
+
+	c, err = ParseConfig([]byte("receivers:\n  kubeletstats:\n"))
+	require.NoError(t, err)
+	assert.True(t, onKubernetes(c, noEnv))
+}
@@ -0,0 +1,206 @@
+package collector
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// listenerSpec describes a server a receiver or extension starts
+type listenerSpec struct {
+	// path to the config of the server in the component, e.g. protocols > grpc. The server is only started if the
+	// path exists, except for an empty path.
+	path []string
+	// port is the default port, the default host is localhost since collector v0.104.0
+	port    string
+	network string
+	// otlp is the OTLP transport of the server: grpc or http
+	otlp string
+}
+
+var receiverListeners = map[string][]listenerSpec{
+	"otlp": {
+		{path: []string{"protocols", "grpc"}, port: "4317", otlp: "grpc"},
+		{path: []string{"protocols", "http"}, port: "4318", otlp: "http"},
+	},
+	"jaeger": {
+		{path: []string{"protocols", "grpc"}, port: "14250"},
+		{path: []string{"protocols", "thrift_http"}, port: "14268"},
+		{path: []string{"protocols", "thrift_compact"}, port: "6831", network: "udp"},
+		{path: []string{"protocols", "thrift_binary"}, port: "6832", network: "udp"},
+	},
+	"loki": {
+		{path: []string{"protocols", "grpc"}, port: "3600"},
+		{path: []string{"protocols", "http"}, port: "3500"},
+	},
+	"zipkin":        {{port: "9411"}},
+	"opencensus":    {{port: "55678"}},
+	"statsd":        {{port: "8125", network: "udp"}},
+	"fluentforward": {{port: "8006"}},
+	"splunk_hec":    {{port: "8088"}},
+	"influxdb":      {{port: "8086"}},
+	"datadog":       {{port: "8126"}},
+}
+
+var extensionListeners = map[string]listenerSpec{
+	"health_check": {port: "13133"},
+	"pprof":        {port: "1777"},
+	"zpages":       {port: "55679"},
+}
+
+// listener is a server started by a receiver or an extension
+type listener struct {
+	// Path is the location in the config, e.g. receivers > otlp > protocols > grpc
+	Path string
+	Host string
+	Port string
+	// Default is set if the endpoint is not configured
+	Default bool
+	Network string
+	TLS     bool
+	OTLP    string
+}
+
+func (l listener) address() string {
+	return net.JoinHostPort(l.Host, l.Port)
+}
+
+func (l listener) loopback() bool {
+	if l.Host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(l.Host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (l listener) allInterfaces() bool {
+	return l.Host == "" || l.Host == "0.0.0.0" || l.Host == "::"
+}
+
+// overlaps returns true if both listeners can't be started at the same time
+func (l listener) overlaps(other listener) bool {
+	if l.Network != other.Network || l.Port != other.Port {
+		return false
+	}
+	return l.allInterfaces() || other.allInterfaces() || l.Host == other.Host || (l.loopback() && other.loopback())
+}
+
+// hostInfo describes where the collector runs, as far as otel-checker can tell
+type hostInfo struct {
+	// container is set when running in a container, where localhost is not reachable from other containers
+	container bool
+	// exposed is set when the host has a public IP address
+	exposed bool
+}
+
+// detectHost assumes that the collector runs on the host otel-checker runs on
+var detectHost = func() hostInfo {
+	var h hostInfo
+	h.container = os.Getenv("KUBERNETES_SERVICE_HOST") != "" || utils.FileExists("/.dockerenv") || utils.FileExists("/run/.containerenv")
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return h
+	}
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && n.IP.IsGlobalUnicast() && !n.IP.IsPrivate() {
+			h.exposed = true
+		}
+	}
+	return h
+}
+
+// listeners returns the servers of the receivers used by pipelines and of the enabled extensions
+func listeners(c *Config) []listener {
+	var result []listener
+	for _, r := range c.UsedReceivers() {
+		for _, spec := range receiverListeners[r.ID.Type] {
+			if l, ok := newListener(r, KindReceiver, spec); ok {
+				result = append(result, l)
+			}
+		}
+	}
+	for _, id := range c.ServiceExtensions {
+		e, defined := c.Component(KindExtension, id)
+		spec, ok := extensionListeners[id.Type]
+		if !defined || !ok {
+			continue
+		}
+		if l, ok := newListener(e, KindExtension, spec); ok {
+			result = append(result, l)
+		}
+	}
+	return result
+}
+
+func newListener(c Component, kind string, spec listenerSpec) (listener, bool) {
+	if len(spec.path) > 0 {
+		if _, ok := c.Value(spec.path...); !ok {
+			return listener{}, false
+		}
+	}
+	l := listener{
+		Path:    strings.Join(append([]string{kind, c.ID.String()}, spec.path...), " > "),
+		Host:    "localhost",
+		Port:    spec.port,
+		Network: spec.network,
+		OTLP:    spec.otlp,
+	}
+	if l.Network == "" {
+		l.Network = "tcp"
+	}
+	_, l.TLS = c.Value(append(slices.Clone(spec.path), "tls")...)
+	if s := fmt.Sprint(valueOrEmpty(c, append(slices.Clone(spec.path), "endpoint")...)); s != "" {
+		if host, port, err := net.SplitHostPort(s); err == nil {
+			l.Host, l.Port = host, port
+		}
+	} else {
+		l.Default = true
+	}
+	return l, true
+}
+
+// checkReceiverEndpoints checks the addresses the receivers and extensions listen on for port conflicts and for
+// addresses that are unreachable or too reachable, and that the SDK sends to a receiver that is configured
+func checkReceiverEndpoints(reporter *utils.ComponentReporter, c *Config, host hostInfo, getenv func(string) string) {
+	ls := listeners(c)
+
+	for i, l := range ls {
+		for _, other := range ls[i+1:] {
+			if l.overlaps(other) {
+				reporter.AddError(fmt.Sprintf("%s and %s on config.yaml both listen on port %s/%s. Change the endpoint of one of them, the collector fails to start otherwise", l.Path, other.Path, l.Port, l.Network))
+			}
+		}
+	}
+
+	for _, l := range ls {
+		if !strings.HasPrefix(l.Path, KindReceiver) {
+			continue
+		}
+		switch {
+		case host.container && l.loopback() && l.Default:
+			reporter.AddWarning(fmt.Sprintf("%s on config.yaml has no endpoint and listens on %s, which other containers can't reach. Set the endpoint to 0.0.0.0:%s", l.Path, l.address(), l.Port))
+		case host.container && l.loopback():
+			reporter.AddWarning(fmt.Sprintf("%s on config.yaml listens on %s, which other containers can't reach. Set the endpoint to 0.0.0.0:%s", l.Path, l.address(), l.Port))
+		case host.exposed && l.allInterfaces() && !l.TLS:
+			reporter.AddWarning(fmt.Sprintf("%s on config.yaml listens on %s without TLS on a host with a public IP address. Configure tls or listen on a private address, so that only your applications can send data", l.Path, l.address()))
+		}
+	}
+
+	checkSDKEndpoint(reporter, ls, getenv)
+}
+
+// checkSDKEndpoint checks that the port and protocol of the OTLP exporter of the SDK match an otlp receiver
+func checkSDKEndpoint(reporter *utils.ComponentReporter, ls []listener, getenv func(string) string) {
+	var receivers []env.OTLPReceiver
+	for _, l := range ls {
+		if l.OTLP != "" {
+			receivers = append(receivers, env.OTLPReceiver{Name: l.Path + " on config.yaml", Transport: l.OTLP, Port: l.Port})
+		}
+	}
+	env.CheckSDKReceivers(reporter, receivers, "otlp receiver on config.yaml", "", getenv)
+}
@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"os"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// the checks must not depend on the host the tests run on and the collector installed on it
+	detectHost = func() hostInfo { return hostInfo{} }
+	detectVersion = func(string) CollectorVersion { return CollectorVersion{} }
+	os.Exit(m.Run())
+}
+
+func TestCheckReceiverEndpoints(t *testing.T) {
+	tests := []struct {
+		name             string
+		configYAML       string
+		host             hostInfo
+		env              map[string]string
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name: "port collisions",
+			configYAML: `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+        endpoint: 0.0.0.0:13133
+  jaeger:
+    protocols:
+      thrift_compact:
+        endpoint: 0.0.0.0:4317
+  zipkin:
+    endpoint: 127.0.0.1:1777
+extensions:
+  health_check:
+  pprof:
+    endpoint: localhost:1777
+  zpages:
+service:
+  extensions: [health_check, pprof]
+  pipelines:
+    traces:
+      receivers: [otlp, jaeger, zipkin]
+      exporters: [otlphttp]
+`,
+			expectedErrors: []string{
+				"collector: receivers > otlp > protocols > http and extensions > health_check on config.yaml both listen on port 13133/tcp. Change the endpoint of one of them, the collector fails to start otherwise",
+				"collector: receivers > zipkin and extensions > pprof on config.yaml both listen on port 1777/tcp. Change the endpoint of one of them, the collector fails to start otherwise",
+			},
+		},
+		{
+			name: "localhost in a container",
+			configYAML: `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+      http:
+        endpoint: 127.0.0.1:4318
+  zipkin:
+    endpoint: 0.0.0.0:9411
+service:
+  pipelines:
+    traces:
+      receivers: [otlp, zipkin]
+      exporters: [otlphttp]
+`,
+			host: hostInfo{container: true},
+			expectedWarnings: []string{
+				"collector: receivers > otlp > protocols > grpc on config.yaml has no endpoint and listens on localhost:4317, which other containers can't reach. Set the endpoint to 0.0.0.0:4317",
+				"collector: receivers > otlp > protocols > http on config.yaml listens on 127.0.0.1:4318, which other containers can't reach. Set the endpoint to 0.0.0.0:4318",
+			},
+		},
+		{
+			name: "all interfaces without TLS on an exposed host",
+			configYAML: `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+        tls:
+          cert_file: /certs/cert.pem
+          key_file: /certs/key.pem
+      http:
+        endpoint: ":4318"
+  otlp/unused:
+    protocols:
+      http:
+        endpoint: 0.0.0.0:4319
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+			host: hostInfo{exposed: true},
+			expectedWarnings: []string{
+				"collector: receivers > otlp > protocols > http on config.yaml listens on :4318 without TLS on a host with a public IP address. Configure tls or listen on a private address, so that only your applications can send data",
+			},
+		},
+		{
+			name:       "SDK sends to the http receiver",
+			configYAML: otlpReceiverConfig,
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://collector:4318",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+			},
+			expectedChecks: []string{
+				"collector: The SDK sends OTLP over http to port 4318, where receivers > otlp > protocols > http on config.yaml receives it",
+			},
+		},
+		{
+			name:       "SDK sends grpc to the default port",
+			configYAML: otlpReceiverConfig,
+			env:        map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"},
+			expectedChecks: []string{
+				"collector: The SDK sends OTLP over grpc to port 4317, where receivers > otlp > protocols > grpc on config.yaml receives it",
+			},
+		},
+		{
+			name:       "SDK sends grpc to the http port",
+			configYAML: otlpReceiverConfig,
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+			},
+			expectedErrors: []string{
+				"collector: The SDK sends OTLP over grpc to port 4318, but receivers > otlp > protocols > http on config.yaml receives OTLP over http on this port. Set OTEL_EXPORTER_OTLP_PROTOCOL to http/protobuf or send to the port that receives OTLP over grpc",
+			},
+		},
+		{
+			name:       "SDK sends to a port without receiver",
+			configYAML: otlpReceiverConfig,
+			env:        map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "https://collector.example.com"},
+			expectedErrors: []string{
+				"collector: The SDK sends OTLP over http to port 443, but no otlp receiver on config.yaml listens on it. Configured ports: 4317 (grpc), 4318 (http)",
+			},
+		},
+		{
+			name:       "SDK sends to Grafana Cloud",
+			configYAML: otlpReceiverConfig,
+			env:        map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net/otlp"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(tt.configYAML))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			componentReporter := reporter.Component("collector")
+			checkReceiverEndpoints(componentReporter, c, tt.host, func(name string) string { return tt.env[name] })
+
+			assert.ElementsMatch(t, tt.expectedErrors, componentReporter.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, componentReporter.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, componentReporter.Checks, "checks mismatch")
+		})
+	}
+}
+
+const otlpReceiverConfig = `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+      http:
+        endpoint: 0.0.0.0:4318
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`
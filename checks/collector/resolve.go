@@ -0,0 +1,275 @@
+package collector
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/network"
+	"gopkg.in/yaml.v3"
+)
+
+// Reference is a ${...} reference in the collector config that could not be resolved
+type Reference struct {
+	// Path is the location of the reference in the config, e.g. "exporters > otlphttp > headers > Authorization"
+	Path string
+	// Ref is the reference, e.g. "${env:GRAFANA_CLOUD_TOKEN}"
+	Ref    string
+	Reason string
+}
+
+// providers load the config URIs and resolve the ${...} references like the providers of the collector
+type providers struct {
+	lookupEnv func(string) (string, bool)
+	readFile  func(string) ([]byte, error)
+	client    *http.Client
+}
+
+var defaultProviders = providers{
+	lookupEnv: os.LookupEnv,
+	readFile:  os.ReadFile,
+	client:    network.NewClient(nil, 30*time.Second),
+}
+
+var (
+	// ReferencePattern matches $$ (an escaped $) and ${...} references in the values of the config
+	ReferencePattern = regexp.MustCompile(`\$\$|\$\{([^${}]+)\}`)
+	// SchemePattern matches the scheme of a config URI or a reference such as ${env:VAR}
+	SchemePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9+.-]*$`)
+)
+
+// ConfigURIs returns the config URIs of the -collector-config-path flag, which accepts a comma separated list of
+// directories containing a config.yaml, files and the URIs the collector accepts in --config:
+// file:<path>, env:<VAR>, yaml:<path>::<value>, http://<url> and https://<url>
+func ConfigURIs(configPath string) []string {
+	var uris []string
+	for _, uri := range strings.Split(configPath, ",") {
+		uri = strings.TrimSpace(uri)
+		if uri == "" && len(uris) > 0 {
+			continue
+		}
+		if uri == "" || strings.HasSuffix(uri, "/") {
+			uri += "config.yaml"
+		} else if info, err := os.Stat(uri); err == nil && info.IsDir() {
+			uri = filepath.Join(uri, "config.yaml")
+		}
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+// LoadConfigs loads the collector config from the URIs of the -collector-config-path flag. Configs are merged in
+// order like the collector does: maps are merged and later configs override values and lists of earlier configs.
+// The ${...} references are expanded after merging; the references that can't be resolved are returned in
+// Config.Unresolved.
+func LoadConfigs(configPath string) (*Config, error) {
+	return defaultProviders.load(ConfigURIs(configPath))
+}
+
+// LoadRawConfigs loads and merges the collector config like LoadConfigs, but keeps the ${...} references in the
+// values, e.g. to convert them instead of the values they resolve to
+func LoadRawConfigs(configPath string) (*Config, error) {
+	return defaultProviders.loadRaw(ConfigURIs(configPath))
+}
+
+func (p providers) load(uris []string) (*Config, error) {
+	merged, err := p.merge(uris)
+	if err != nil {
+		return nil, err
+	}
+	var unresolved []Reference
+	c, err := parseMerged(uris, p.expand(merged, nil, &unresolved))
+	if err != nil {
+		return nil, err
+	}
+	c.Unresolved = unresolved
+	return c, nil
+}
+
+func (p providers) loadRaw(uris []string) (*Config, error) {
+	merged, err := p.merge(uris)
+	if err != nil {
+		return nil, err
+	}
+	return parseMerged(uris, merged)
+}
+
+// merge retrieves the config URIs and merges them in order
+func (p providers) merge(uris []string) (map[string]any, error) {
+	var merged map[string]any
+	for _, uri := range uris {
+		data, err := p.retrieve(uri)
+		if err != nil {
+			return nil, err
+		}
+		var m map[string]any
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("could not parse file %s: %w", uri, err)
+		}
+		merged = mergeMaps(merged, m)
+	}
+	return merged, nil
+}
+
+func parseMerged(uris []string, merged any) (*Config, error) {
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse file %s: %w", strings.Join(uris, ", "), err)
+	}
+	return c, nil
+}
+
+// retrieve returns the YAML of a config URI
+func (p providers) retrieve(uri string) ([]byte, error) {
+	scheme, rest, hasScheme := strings.Cut(uri, ":")
+	if !hasScheme || !SchemePattern.MatchString(scheme) || len(scheme) == 1 {
+		// a path, including Windows paths like C:\config.yaml
+		scheme, rest = "file", uri
+	}
+	switch scheme {
+	case "file":
+		data, err := p.readFile(rest)
+		if err != nil {
+			return nil, fmt.Errorf("could not check file %s: %w", rest, err)
+		}
+		return data, nil
+	case "env":
+		value, ok := p.lookupEnv(rest)
+		if !ok {
+			return nil, fmt.Errorf("could not load %s: environment variable %s is not set", uri, rest)
+		}
+		return []byte(value), nil
+	case "yaml":
+		path, value, ok := strings.Cut(rest, "::")
+		if !ok {
+			return nil, fmt.Errorf("could not load %s: expected yaml:<path>::<value>", uri)
+		}
+		keys := strings.Split(path, "::")
+		var v any
+		if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+			return nil, fmt.Errorf("could not load %s: %w", uri, err)
+		}
+		for i := len(keys) - 1; i >= 0; i-- {
+			v = map[string]any{keys[i]: v}
+		}
+		return yaml.Marshal(v)
+	case "http", "https":
+		resp, err := p.client.Get(uri)
+		if err != nil {
+			return nil, fmt.Errorf("could not load %s: %w", uri, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("could not load %s: %s", uri, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	return nil, fmt.Errorf("could not load %s: unsupported scheme %s. Possible values: file, env, yaml, http, https", uri, scheme)
+}
+
+// mergeMaps merges src into dst like the collector: nested maps are merged and other values, including lists,
+// are replaced
+func mergeMaps(dst, src map[string]any) map[string]any {
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			dst[k] = mergeMaps(dstMap, srcMap)
+		} else {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// expand replaces the ${...} references in all string values
+func (p providers) expand(v any, path []string, unresolved *[]Reference) any {
+	switch value := v.(type) {
+	case map[string]any:
+		for k, child := range value {
+			value[k] = p.expand(child, append(append([]string{}, path...), k), unresolved)
+		}
+		return value
+	case []any:
+		for i, child := range value {
+			value[i] = p.expand(child, append(append([]string{}, path...), fmt.Sprint(i)), unresolved)
+		}
+		return value
+	case string:
+		return p.expandString(value, strings.Join(path, " > "), unresolved)
+	}
+	return v
+}
+
+func (p providers) expandString(s string, path string, unresolved *[]Reference) any {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	matches := ReferencePattern.FindAllStringSubmatchIndex(s, -1)
+	// a value that only consists of a reference gets the type of the resolved value, e.g. a number or a map
+	whole := len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) && s != "$$"
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(s[last:m[0]])
+		last = m[1]
+		ref := s[m[0]:m[1]]
+		if ref == "$$" {
+			b.WriteString("$")
+			continue
+		}
+		value, reason := p.resolve(s[m[2]:m[3]])
+		if reason != "" {
+			*unresolved = append(*unresolved, Reference{Path: path, Ref: ref, Reason: reason})
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(s[last:])
+
+	if whole {
+		var typed any
+		if err := yaml.Unmarshal([]byte(b.String()), &typed); err == nil && typed != nil {
+			return typed
+		}
+	}
+	return b.String()
+}
+
+// resolve returns the value of the content of a ${...} reference, or the reason why it can't be resolved
+func (p providers) resolve(content string) (string, string) {
+	scheme, rest, hasScheme := strings.Cut(content, ":")
+	if !hasScheme || !SchemePattern.MatchString(scheme) {
+		scheme, rest = "env", content
+	}
+	switch scheme {
+	case "env":
+		name, fallback, hasFallback := strings.Cut(rest, ":-")
+		if value, ok := p.lookupEnv(name); ok {
+			return value, ""
+		}
+		if hasFallback {
+			return fallback, ""
+		}
+		return "", fmt.Sprintf("environment variable %s is not set", name)
+	case "file":
+		data, err := p.readFile(rest)
+		if err != nil {
+			return "", fmt.Sprintf("file %s can't be read: %s", rest, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), ""
+	}
+	return "", fmt.Sprintf("the %s provider is not supported by otel-checker", scheme)
+}
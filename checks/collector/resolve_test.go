@@ -0,0 +1,157 @@
+package collector
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigURIs(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		configPath string
+		expected   []string
+	}{
+		{configPath: "", expected: []string{"config.yaml"}},
+		{configPath: "src/inst/", expected: []string{"src/inst/config.yaml"}},
+		{configPath: dir, expected: []string{filepath.Join(dir, "config.yaml")}},
+		{
+			configPath: "base.yaml, file:grafana.yaml,env:COLLECTOR_CONFIG,yaml:exporters::debug::verbosity: detailed",
+			expected:   []string{"base.yaml", "file:grafana.yaml", "env:COLLECTOR_CONFIG", "yaml:exporters::debug::verbosity: detailed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.configPath, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ConfigURIs(tt.configPath))
+		})
+	}
+}
+
+func TestLoadConfigs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/remote.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, "processors:\n  batch:\n")
+	}))
+	defer server.Close()
+
+	files := map[string]string{
+		"base.yaml": `
+receivers:
+  otlp:
+    protocols:
+      grpc:
+      http:
+        endpoint: ${env:OTLP_HTTP_ENDPOINT:-0.0.0.0:4318}
+exporters:
+  otlphttp:
+    endpoint: http://localhost:4318
+    sending_queue:
+      num_consumers: ${env:NUM_CONSUMERS}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [otlphttp]
+`,
+		"grafana.yaml": `
+exporters:
+  otlphttp:
+    endpoint: ${GRAFANA_CLOUD_OTLP_ENDPOINT}/otlp
+    headers:
+      Authorization: Basic ${file:token}
+      X-Scope-OrgID: ${env:TENANT}
+      X-Price: $$5 ${http://example.com/value}
+service:
+  pipelines:
+    traces:
+      receivers: [otlp]
+      processors: [batch]
+      exporters: [otlphttp]
+`,
+		"token": "MTIzOmFiYw==\n",
+	}
+	env := map[string]string{
+		"GRAFANA_CLOUD_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net",
+		"NUM_CONSUMERS":               "4",
+		"COLLECTOR_CONFIG":            "extensions:\n  health_check:\n",
+	}
+	p := providers{
+		lookupEnv: func(name string) (string, bool) {
+			v, ok := env[name]
+			return v, ok
+		},
+		readFile: func(name string) ([]byte, error) {
+			if content, ok := files[name]; ok {
+				return []byte(content), nil
+			}
+			return nil, os.ErrNotExist
+		},
+		client: server.Client(),
+	}
+
+	c, err := p.load([]string{"base.yaml", "file:grafana.yaml", "env:COLLECTOR_CONFIG", server.URL + "/remote.yaml", "yaml:service::extensions: [health_check]"})
+	require.NoError(t, err)
+
+	exporter, ok := c.Component(KindExporter, ComponentID{Type: "otlphttp"})
+	require.True(t, ok)
+	assert.Equal(t, "https://otlp-gateway-prod-us-east-0.grafana.net/otlp", exporter.StringValue("endpoint"))
+	assert.Equal(t, "Basic MTIzOmFiYw==", exporter.StringValue("headers", "Authorization"))
+	assert.Equal(t, "$5 ", exporter.StringValue("headers", "X-Price"))
+	numConsumers, _ := exporter.Value("sending_queue", "num_consumers")
+	assert.Equal(t, 4, numConsumers)
+
+	receiver, ok := c.Component(KindReceiver, ComponentID{Type: "otlp"})
+	require.True(t, ok)
+	assert.Equal(t, "0.0.0.0:4318", receiver.StringValue("protocols", "http", "endpoint"))
+	_, ok = receiver.Value("protocols", "grpc")
+	assert.True(t, ok, "maps of earlier configs are merged")
+
+	assert.Equal(t, []ComponentID{{Type: "batch"}}, c.Pipelines[0].Processors, "lists of later configs replace earlier lists")
+	assert.Equal(t, []ComponentID{{Type: "health_check"}}, c.ServiceExtensions)
+	_, ok = c.Component(KindProcessor, ComponentID{Type: "batch"})
+	assert.True(t, ok)
+
+	assert.ElementsMatch(t, []Reference{
+		{Path: "exporters > otlphttp > headers > X-Scope-OrgID", Ref: "${env:TENANT}", Reason: "environment variable TENANT is not set"},
+		{Path: "exporters > otlphttp > headers > X-Price", Ref: "${http://example.com/value}", Reason: "the http provider is not supported by otel-checker"},
+	}, c.Unresolved)
+
+	raw, err := p.loadRaw([]string{"base.yaml", "file:grafana.yaml"})
+	require.NoError(t, err)
+	exporter, ok = raw.Component(KindExporter, ComponentID{Type: "otlphttp"})
+	require.True(t, ok)
+	assert.Equal(t, "${GRAFANA_CLOUD_OTLP_ENDPOINT}/otlp", exporter.StringValue("endpoint"), "references are kept")
+	assert.Empty(t, raw.Unresolved)
+
+	_, err = p.load([]string{"missing.yaml"})
+	assert.EqualError(t, err, "could not check file missing.yaml: file does not exist")
+	_, err = p.load([]string{"env:MISSING"})
+	assert.EqualError(t, err, "could not load env:MISSING: environment variable MISSING is not set")
+	_, err = p.load([]string{server.URL + "/missing.yaml"})
+	assert.EqualError(t, err, fmt.Sprintf("could not load %s/missing.yaml: 404 Not Found", server.URL))
+}
+
+func TestLoadConfigsFromFlag(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	require.NoError(t, os.WriteFile(base, []byte("exporters:\n  debug:\n    verbosity: basic\n"), 0644))
+	t.Setenv("COLLECTOR_CONFIG", "exporters:\n  debug:\n    verbosity: detailed\n")
+
+	commands := utils.ParseArguments([]string{"-language=go", "-components=collector", "-collector-config-path=" + base + ",env:COLLECTOR_CONFIG"})
+	assert.Equal(t, base+",env:COLLECTOR_CONFIG", commands.CollectorConfigPath)
+
+	c, err := LoadConfigs(commands.CollectorConfigPath)
+	require.NoError(t, err)
+	verbosity, _ := c.Exporters[0].Value("verbosity")
+	assert.Equal(t, "detailed", verbosity)
+}
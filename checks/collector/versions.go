@@ -0,0 +1,174 @@
+package collector
+
+import (
+	_ "embed"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"golang.org/x/mod/semver"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed components.yaml
+var componentsFile []byte
+
+// componentChange is a component or a config key of a component that was deprecated or removed
+type componentChange struct {
+	Kind        string `yaml:"kind"`
+	Type        string `yaml:"type"`
+	Key         string `yaml:"key"`
+	Deprecated  string `yaml:"deprecated"`
+	Removed     string `yaml:"removed"`
+	Replacement string `yaml:"replacement"`
+}
+
+type componentsManifest struct {
+	Changes       []componentChange              `yaml:"changes"`
+	Distributions map[string]map[string][]string `yaml:"distributions"`
+}
+
+const (
+	distributionCore    = "otelcol"
+	distributionContrib = "otelcol-contrib"
+)
+
+func loadManifest() componentsManifest {
+	var m componentsManifest
+	if err := yaml.Unmarshal(componentsFile, &m); err != nil {
+		panic(fmt.Sprintf("invalid components.yaml: %s", err))
+	}
+	return m
+}
+
+// CollectorVersion is the version and distribution of the collector the config is checked against
+type CollectorVersion struct {
+	// Version without "v", e.g. 0.110.0. Empty if unknown.
+	Version string
+	// Distribution is the name of the binary, e.g. otelcol-contrib. Empty if unknown.
+	Distribution string
+}
+
+var versionPattern = regexp.MustCompile(`^(\S+) version v?([0-9]+\.[0-9]+\.[0-9]+)`)
+
+// ParseVersionOutput parses the output of `otelcol --version`, e.g. "otelcol-contrib version 0.110.0"
+func ParseVersionOutput(output string) (CollectorVersion, bool) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(output))
+	if m == nil {
+		return CollectorVersion{}, false
+	}
+	return CollectorVersion{Version: m[2], Distribution: m[1]}, true
+}
+
+// collectorBinaries are the binaries of the collector distributions that are searched on the PATH
+var collectorBinaries = []string{"otelcol-contrib", "otelcol", "otelcol-k8s", "otelcol-otlp"}
+
+// detectVersion returns the version given by the -collector-version flag, or the version of the first collector
+// binary found on the PATH
+var detectVersion = func(flagValue string) CollectorVersion {
+	if flagValue != "" {
+		return CollectorVersion{Version: strings.TrimPrefix(flagValue, "v")}
+	}
+	for _, binary := range collectorBinaries {
+		if _, err := exec.LookPath(binary); err != nil {
+			continue
+		}
+		out, err := exec.Command(binary, "--version").Output()
+		if err != nil {
+			continue
+		}
+		if v, ok := ParseVersionOutput(string(out)); ok {
+			return v
+		}
+	}
+	return CollectorVersion{}
+}
+
+// checkVersions reports deprecated and removed components and keys for the collector version, and components that
+// are not part of the distribution
+func checkVersions(reporter *utils.ComponentReporter, c *Config, version CollectorVersion) {
+	if version.Version != "" && !semver.IsValid("v"+version.Version) {
+		reporter.AddError(fmt.Sprintf("Collector version %s is not a valid version. Use the format 0.110.0", version.Version))
+		version.Version = ""
+	}
+
+	manifest := loadManifest()
+	for _, change := range manifest.Changes {
+		for _, location := range changeLocations(c, change) {
+			reportChange(reporter, change, location, version.Version)
+		}
+	}
+	checkDistribution(reporter, c, manifest, version.Distribution)
+}
+
+// changeLocations returns where the deprecated or removed component or key is used in the config
+func changeLocations(c *Config, change componentChange) []string {
+	if change.Kind == "service" {
+		if change.Type == "telemetry" && change.Key != "" {
+			if _, ok := (Component{Config: c.Telemetry}).Value(strings.Split(change.Key, " > ")...); ok {
+				return []string{fmt.Sprintf("service > telemetry > %s", change.Key)}
+			}
+		}
+		return nil
+	}
+
+	var locations []string
+	for _, component := range c.components(change.Kind) {
+		if component.ID.Type != change.Type {
+			continue
+		}
+		if change.Key == "" {
+			locations = append(locations, fmt.Sprintf("%s > %s", change.Kind, component.ID))
+		} else if _, ok := component.Value(strings.Split(change.Key, " > ")...); ok {
+			locations = append(locations, fmt.Sprintf("%s > %s > %s", change.Kind, component.ID, change.Key))
+		}
+	}
+	return locations
+}
+
+func reportChange(reporter *utils.ComponentReporter, change componentChange, location string, version string) {
+	replacement := ""
+	if change.Replacement != "" {
+		replacement = fmt.Sprintf(". Use %s instead", change.Replacement)
+	}
+	switch {
+	case change.Removed != "" && version != "" && semver.Compare("v"+version, "v"+change.Removed) >= 0:
+		reporter.AddError(fmt.Sprintf("%s on config.yaml was removed in collector v%s, so collector v%s fails to start%s", location, change.Removed, version, replacement))
+	case version != "" && semver.Compare("v"+version, "v"+change.Deprecated) < 0:
+		// not deprecated yet in this version
+	case change.Removed != "":
+		reporter.AddWarning(fmt.Sprintf("%s on config.yaml is deprecated since collector v%s and removed in v%s%s", location, change.Deprecated, change.Removed, replacement))
+	default:
+		reporter.AddWarning(fmt.Sprintf("%s on config.yaml is deprecated since collector v%s%s", location, change.Deprecated, replacement))
+	}
+}
+
+// checkDistribution checks that the components are part of the distribution of the collector. Without a known
+// distribution, components that are not part of otelcol-contrib are reported. The manifests only contain current
+// components, so deprecated and removed components are skipped.
+func checkDistribution(reporter *utils.ComponentReporter, c *Config, manifest componentsManifest, distribution string) {
+	core := manifest.Distributions[distributionCore]
+	contrib := manifest.Distributions[distributionContrib]
+	for _, kind := range []string{KindReceiver, KindProcessor, KindExporter, KindConnector, KindExtension} {
+		for _, component := range c.components(kind) {
+			typ := component.ID.Type
+			if slices.ContainsFunc(manifest.Changes, func(change componentChange) bool {
+				return change.Kind == kind && change.Type == typ && change.Key == ""
+			}) {
+				// reported as deprecated or removed component
+				continue
+			}
+			inCore := slices.Contains(core[kind], typ)
+			inContrib := inCore || slices.Contains(contrib[kind], typ)
+			switch {
+			case distribution == distributionCore && !inCore && inContrib:
+				reporter.AddError(fmt.Sprintf("%s > %s on config.yaml is not part of the %s distribution. Use %s instead", kind, component.ID, distributionCore, distributionContrib))
+			case (distribution == distributionCore || distribution == distributionContrib || distribution == "") && !inContrib:
+				reporter.AddWarning(fmt.Sprintf("%s > %s on config.yaml is not part of the %s distribution. Make sure your collector is built with it, e.g. with the OpenTelemetry Collector Builder (ocb)", kind, component.ID, distributionContrib))
+			}
+		}
+	}
+}
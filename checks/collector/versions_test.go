@@ -0,0 +1,122 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	v, ok := ParseVersionOutput("otelcol-contrib version 0.110.0\n")
+	require.True(t, ok)
+	assert.Equal(t, CollectorVersion{Version: "0.110.0", Distribution: "otelcol-contrib"}, v)
+
+	v, ok = ParseVersionOutput("otelcol version v0.98.0")
+	require.True(t, ok)
+	assert.Equal(t, CollectorVersion{Version: "0.98.0", Distribution: "otelcol"}, v)
+
+	_, ok = ParseVersionOutput("command not found")
+	assert.False(t, ok)
+}
+
+func TestCheckVersions(t *testing.T) {
+	config := `
+receivers:
+  otlp:
+  opencensus:
+  myreceiver:
+processors:
+  memory_limiter:
+    ballast_size_mib: 512
+  k8sattributes:
+exporters:
+  logging:
+    loglevel: debug
+  jaeger:
+extensions:
+  memory_ballast:
+service:
+  telemetry:
+    metrics:
+      address: 0.0.0.0:8888
+`
+	tests := []struct {
+		name             string
+		version          CollectorVersion
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			name:    "unknown version",
+			version: CollectorVersion{},
+			expectedWarnings: []string{
+				"collector: exporters > logging on config.yaml is deprecated since collector v0.86.0 and removed in v0.111.0. Use the debug exporter instead",
+				"collector: exporters > logging > loglevel on config.yaml is deprecated since collector v0.84.0. Use verbosity instead",
+				"collector: exporters > jaeger on config.yaml is deprecated since collector v0.80.0 and removed in v0.85.0. Use the otlp exporter, Jaeger accepts OTLP natively instead",
+				"collector: receivers > opencensus on config.yaml is deprecated since collector v0.116.0 and removed in v0.129.0. Use the otlp receiver instead",
+				"collector: extensions > memory_ballast on config.yaml is deprecated since collector v0.93.0 and removed in v0.103.0. Use the GOMEMLIMIT environment variable instead",
+				"collector: processors > memory_limiter > ballast_size_mib on config.yaml is deprecated since collector v0.93.0 and removed in v0.103.0. Use the GOMEMLIMIT environment variable instead",
+				"collector: service > telemetry > metrics > address on config.yaml is deprecated since collector v0.111.0. Use metrics > readers instead",
+				"collector: receivers > myreceiver on config.yaml is not part of the otelcol-contrib distribution. Make sure your collector is built with it, e.g. with the OpenTelemetry Collector Builder (ocb)",
+			},
+		},
+		{
+			name:    "old core collector",
+			version: CollectorVersion{Version: "0.85.0", Distribution: "otelcol"},
+			expectedErrors: []string{
+				"collector: exporters > jaeger on config.yaml was removed in collector v0.85.0, so collector v0.85.0 fails to start. Use the otlp exporter, Jaeger accepts OTLP natively instead",
+				"collector: processors > k8sattributes on config.yaml is not part of the otelcol distribution. Use otelcol-contrib instead",
+			},
+			expectedWarnings: []string{
+				"collector: exporters > logging > loglevel on config.yaml is deprecated since collector v0.84.0. Use verbosity instead",
+				"collector: receivers > myreceiver on config.yaml is not part of the otelcol-contrib distribution. Make sure your collector is built with it, e.g. with the OpenTelemetry Collector Builder (ocb)",
+			},
+		},
+		{
+			name:    "new custom collector",
+			version: CollectorVersion{Version: "0.111.0", Distribution: "otelcol-custom"},
+			expectedErrors: []string{
+				"collector: exporters > logging on config.yaml was removed in collector v0.111.0, so collector v0.111.0 fails to start. Use the debug exporter instead",
+				"collector: exporters > jaeger on config.yaml was removed in collector v0.85.0, so collector v0.111.0 fails to start. Use the otlp exporter, Jaeger accepts OTLP natively instead",
+				"collector: extensions > memory_ballast on config.yaml was removed in collector v0.103.0, so collector v0.111.0 fails to start. Use the GOMEMLIMIT environment variable instead",
+				"collector: processors > memory_limiter > ballast_size_mib on config.yaml was removed in collector v0.103.0, so collector v0.111.0 fails to start. Use the GOMEMLIMIT environment variable instead",
+			},
+			expectedWarnings: []string{
+				"collector: exporters > logging > loglevel on config.yaml is deprecated since collector v0.84.0. Use verbosity instead",
+				"collector: service > telemetry > metrics > address on config.yaml is deprecated since collector v0.111.0. Use metrics > readers instead",
+			},
+		},
+		{
+			name:    "invalid version",
+			version: CollectorVersion{Version: "latest", Distribution: "otelcol-custom"},
+			expectedErrors: []string{
+				"collector: Collector version latest is not a valid version. Use the format 0.110.0",
+			},
+			expectedWarnings: []string{
+				"collector: exporters > logging on config.yaml is deprecated since collector v0.86.0 and removed in v0.111.0. Use the debug exporter instead",
+				"collector: exporters > logging > loglevel on config.yaml is deprecated since collector v0.84.0. Use verbosity instead",
+				"collector: exporters > jaeger on config.yaml is deprecated since collector v0.80.0 and removed in v0.85.0. Use the otlp exporter, Jaeger accepts OTLP natively instead",
+				"collector: receivers > opencensus on config.yaml is deprecated since collector v0.116.0 and removed in v0.129.0. Use the otlp receiver instead",
+				"collector: extensions > memory_ballast on config.yaml is deprecated since collector v0.93.0 and removed in v0.103.0. Use the GOMEMLIMIT environment variable instead",
+				"collector: processors > memory_limiter > ballast_size_mib on config.yaml is deprecated since collector v0.93.0 and removed in v0.103.0. Use the GOMEMLIMIT environment variable instead",
+				"collector: service > telemetry > metrics > address on config.yaml is deprecated since collector v0.111.0. Use metrics > readers instead",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseConfig([]byte(config))
+			require.NoError(t, err)
+
+			reporter := utils.Reporter{}
+			componentReporter := reporter.Component("collector")
+			checkVersions(componentReporter, c, tt.version)
+
+			assert.ElementsMatch(t, tt.expectedErrors, componentReporter.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, componentReporter.Warnings, "warnings mismatch")
+		})
+	}
+}
@@ -0,0 +1,232 @@
+package env
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// KnownVar describes an environment variable known to the checker
+type KnownVar struct {
+	// Name of the variable. A '*' matches a family of variables, e.g. OTEL_INSTRUMENTATION_*_ENABLED
+	Name string
+	// Languages using the variable. Empty if the variable is used by all languages
+	Languages []string
+	// Sensitive is set if the value of the variable contains credentials that must not be shown in reports
+	Sensitive bool
+}
+
+// checkedPrefixes are the prefixes of the environment variables that are compared against the catalog
+var checkedPrefixes = []string{"OTEL_", "BEYLA_", "GRAFANA_CLOUD_", "CORECLR_", "OTEL_DOTNET_AUTO_"}
+
+// sensitiveVars are the known variables whose values contain credentials
+var sensitiveVars = []string{
+	"OTEL_EXPORTER_OTLP_HEADERS",
+	"OTEL_EXPORTER_OTLP_TRACES_HEADERS",
+	"OTEL_EXPORTER_OTLP_METRICS_HEADERS",
+	"OTEL_EXPORTER_OTLP_LOGS_HEADERS",
+	"GRAFANA_CLOUD_API_KEY",
+}
+
+// specVars are the environment variables defined by the OpenTelemetry specification
+// See: https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/
+var specVars = []string{
+	"OTEL_SDK_DISABLED",
+	"OTEL_RESOURCE_ATTRIBUTES",
+	"OTEL_SERVICE_NAME",
+	"OTEL_LOG_LEVEL",
+	"OTEL_PROPAGATORS",
+	"OTEL_TRACES_SAMPLER",
+	"OTEL_TRACES_SAMPLER_ARG",
+	"OTEL_TRACES_EXPORTER",
+	"OTEL_METRICS_EXPORTER",
+	"OTEL_LOGS_EXPORTER",
+	"OTEL_METRICS_EXEMPLAR_FILTER",
+	"OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE",
+	"OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION",
+	"OTEL_EXPORTER_ZIPKIN_ENDPOINT",
+	"OTEL_EXPORTER_ZIPKIN_TIMEOUT",
+	"OTEL_EXPORTER_PROMETHEUS_HOST",
+	"OTEL_EXPORTER_PROMETHEUS_PORT",
+	"OTEL_EXPERIMENTAL_CONFIG_FILE",
+	"OTEL_CONFIG_FILE",
+	"OTEL_SEMCONV_STABILITY_OPT_IN",
+}
+
+// otlpExporterOptions are the options of the OTLP exporter, each available for all signals and per signal
+var otlpExporterOptions = []string{
+	"ENDPOINT",
+	"HEADERS",
+	"PROTOCOL",
+	"TIMEOUT",
+	"COMPRESSION",
+	"CERTIFICATE",
+	"CLIENT_KEY",
+	"CLIENT_CERTIFICATE",
+	"INSECURE",
+}
+
+var languageVars = map[string][]string{
+	"dotnet": {
+		"CORECLR_ENABLE_PROFILING",
+		"CORECLR_PROFILER",
+		"CORECLR_PROFILER_PATH",
+		"CORECLR_PROFILER_PATH_32",
+		"CORECLR_PROFILER_PATH_64",
+		"OTEL_DOTNET_AUTO_HOME",
+		"OTEL_DOTNET_AUTO_EXCLUDE_PROCESSES",
+		"OTEL_DOTNET_AUTO_FAIL_FAST_ENABLED",
+		"OTEL_DOTNET_AUTO_TRACES_ENABLED",
+		"OTEL_DOTNET_AUTO_METRICS_ENABLED",
+		"OTEL_DOTNET_AUTO_LOGS_ENABLED",
+		"OTEL_DOTNET_AUTO_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_TRACES_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_METRICS_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_LOGS_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_TRACES_*_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_METRICS_*_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_LOGS_*_INSTRUMENTATION_ENABLED",
+		"OTEL_DOTNET_AUTO_RESOURCE_DETECTOR_ENABLED",
+		"OTEL_DOTNET_AUTO_*_RESOURCE_DETECTOR_ENABLED",
+		"OTEL_DOTNET_AUTO_TRACES_ADDITIONAL_SOURCES",
+		"OTEL_DOTNET_AUTO_TRACES_ADDITIONAL_LEGACY_SOURCES",
+		"OTEL_DOTNET_AUTO_METRICS_ADDITIONAL_SOURCES",
+		"OTEL_DOTNET_AUTO_LOGS_INCLUDE_FORMATTED_MESSAGE",
+		"OTEL_DOTNET_AUTO_LOG_DIRECTORY",
+		"OTEL_DOTNET_AUTO_LOGGER",
+		"OTEL_DOTNET_AUTO_PLUGINS",
+		"OTEL_DOTNET_AUTO_OPENTRACING_ENABLED",
+		"OTEL_DOTNET_AUTO_NETFX_REDIRECT_ENABLED",
+		"OTEL_DOTNET_AUTO_SQLCLIENT_SET_DBSTATEMENT_FOR_TEXT",
+		"OTEL_DOTNET_AUTO_ENTITYFRAMEWORKCORE_SET_DBSTATEMENT_FOR_TEXT",
+		"OTEL_DOTNET_AUTO_GRAPHQL_SET_DOCUMENT",
+		"OTEL_DOTNET_AUTO_HTTP_INSTRUMENTATION_CAPTURE_*_HEADERS",
+		"OTEL_DOTNET_AUTO_GRPCNETCLIENT_INSTRUMENTATION_CAPTURE_*_METADATA",
+	},
+	"go": {
+		"OTEL_GO_AUTO_TARGET_EXE",
+		"OTEL_GO_AUTO_INCLUDE_DB_STATEMENT",
+		"OTEL_GO_AUTO_PARSE_DB_STATEMENT",
+		"OTEL_GO_AUTO_GLOBAL",
+		"OTEL_GO_AUTO_SHOW_VERIFIER_LOG",
+		"OTEL_GO_X_*",
+	},
+	"java": {
+		"OTEL_JAVAAGENT_ENABLED",
+		"OTEL_JAVAAGENT_DEBUG",
+		"OTEL_JAVAAGENT_LOGGING",
+		"OTEL_JAVAAGENT_EXTENSIONS",
+		"OTEL_JAVAAGENT_CONFIGURATION_FILE",
+		"OTEL_JAVAAGENT_EXCLUDE_CLASSES",
+		"OTEL_JAVAAGENT_EXCLUDE_CLASS_LOADERS",
+		"OTEL_JAVA_GLOBAL_AUTOCONFIGURE_ENABLED",
+		"OTEL_JAVA_ENABLED_RESOURCE_PROVIDERS",
+		"OTEL_JAVA_DISABLED_RESOURCE_PROVIDERS",
+		"OTEL_INSTRUMENTATION_*",
+		"OTEL_EXPERIMENTAL_*",
+		"OTEL_RESOURCE_PROVIDERS_*_ENABLED",
+	},
+	"js": {
+		"OTEL_NODE_RESOURCE_DETECTORS",
+		"OTEL_NODE_ENABLED_INSTRUMENTATIONS",
+		"OTEL_NODE_DISABLED_INSTRUMENTATIONS",
+	},
+	"php": {
+		"OTEL_PHP_*",
+	},
+	"python": {
+		"OTEL_PYTHON_*",
+		"OTEL_EXPERIMENTAL_RESOURCE_DETECTORS",
+		"OTEL_INSTRUMENTATION_*",
+	},
+	"ruby": {
+		"OTEL_RUBY_*",
+	},
+}
+
+// beylaVars are the environment variables used by Beyla and its Grafana Cloud integration
+// See: https://grafana.com/docs/beyla/latest/configure/options/
+var beylaVars = []string{
+	"BEYLA_CONFIG_PATH",
+	"BEYLA_OPEN_PORT",
+	"BEYLA_EXECUTABLE_NAME",
+	"BEYLA_SERVICE_NAME",
+	"BEYLA_SERVICE_NAMESPACE",
+	"BEYLA_LOG_LEVEL",
+	"BEYLA_TRACE_PRINTER",
+	"BEYLA_SKIP_GO_SPECIFIC_TRACERS",
+	"BEYLA_KUBE_METADATA_ENABLE",
+	"BEYLA_PROFILE_PORT",
+	"BEYLA_ENFORCE_SYS_CAPS",
+	"BEYLA_BPF_*",
+	"BEYLA_NETWORK_*",
+	"BEYLA_KUBE_*",
+	"BEYLA_ROUTES_*",
+	"BEYLA_PROMETHEUS_*",
+	"BEYLA_INTERNAL_*",
+	"BEYLA_OTEL_*",
+	"BEYLA_METRICS_*",
+	"BEYLA_TRACES_*",
+	"BEYLA_DISCOVERY_*",
+	"BEYLA_ATTRIBUTES_*",
+	"GRAFANA_CLOUD_SUBMIT",
+	"GRAFANA_CLOUD_INSTANCE_ID",
+	"GRAFANA_CLOUD_API_KEY",
+	"GRAFANA_CLOUD_OTLP_ENDPOINT",
+	"GRAFANA_CLOUD_ZONE",
+}
+
+// Catalog returns all environment variables known to the checker
+func Catalog() []KnownVar {
+	var vars []KnownVar
+	for _, name := range specVars {
+		vars = append(vars, KnownVar{Name: name})
+	}
+	for _, envVar := range SDKConfigEnvVars() {
+		vars = append(vars, KnownVar{Name: envVar.Name})
+	}
+	for _, signal := range []string{"", "TRACES_", "METRICS_", "LOGS_"} {
+		for _, option := range otlpExporterOptions {
+			vars = append(vars, KnownVar{Name: "OTEL_EXPORTER_OTLP_" + signal + option})
+		}
+	}
+	for _, name := range beylaVars {
+		vars = append(vars, KnownVar{Name: name})
+	}
+	languages := make([]string, 0, len(languageVars))
+	for language := range languageVars {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+	for _, language := range languages {
+		for _, name := range languageVars[language] {
+			// a variable can be used by more than one language
+			i := slices.IndexFunc(vars, func(k KnownVar) bool { return k.Name == name })
+			if i >= 0 {
+				vars[i].Languages = append(vars[i].Languages, language)
+			} else {
+				vars = append(vars, KnownVar{Name: name, Languages: []string{language}})
+			}
+		}
+	}
+	for i := range vars {
+		vars[i].Sensitive = slices.Contains(sensitiveVars, vars[i].Name)
+	}
+	return vars
+}
+
+// Matches returns true if the name of the environment variable matches the known variable
+func (k KnownVar) Matches(name string) bool {
+	matched, err := path.Match(k.Name, name)
+	return err == nil && matched
+}
+
+// IsPattern returns true if the known variable describes a family of variables
+func (k KnownVar) IsPattern() bool {
+	return strings.Contains(k.Name, "*")
+}
+
+// UsedBy returns true if the known variable is used by the language
+func (k KnownVar) UsedBy(language string) bool {
+	return len(k.Languages) == 0 || slices.Contains(k.Languages, language)
+}
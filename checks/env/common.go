@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/grafana/otel-checker/checks/utils"
 	"strings"
+	"time"
 )
 
 // Common environment variables used across the project
@@ -111,10 +112,18 @@ func CheckResourceAttributes(reporter *utils.ComponentReporter) {
 	}
 }
 
-func CheckCommon(r *utils.ComponentReporter, language string) {
-	CheckExporterEnvVars(r, language)
+func CheckCommon(r *utils.ComponentReporter, commands utils.Commands) {
+	CheckExporterEnvVars(r, commands.Language)
 
 	CheckResourceAttributes(r)
+
+	CheckPropagators(r, commands)
+
+	CheckSDKConfig(r, commands.Language)
+
+	CheckExporterTLS(r, time.Now())
+
+	CheckUnknownEnvVars(r, commands.Language)
 }
 
 func CheckExporterEnvVars(r *utils.ComponentReporter, language string) {
@@ -3,10 +3,23 @@ package env
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/grafana/otel-checker/checks/utils"
 )
 
+// ValueType is the type the value of an environment variable must be parsed as
+type ValueType int
+
+const (
+	// TypeString accepts any value
+	TypeString ValueType = iota
+	// TypeInteger accepts non-negative integers
+	TypeInteger
+	// TypeDuration accepts non-negative integers, as the specification defines durations in milliseconds
+	TypeDuration
+)
+
 // EnvVar represents an environment variable configuration
 type EnvVar struct {
 	Name          string
@@ -14,6 +27,7 @@ type EnvVar struct {
 	Recommended   bool
 	DefaultValue  string
 	RequiredValue string
+	Type          ValueType
 	Validator     func(value string, language string, reporter *utils.ComponentReporter)
 	Description   string
 	Message       string
@@ -22,6 +36,9 @@ type EnvVar struct {
 // CheckEnvVar validates an environment variable against its configuration and reports the result
 func CheckEnvVar(language string, envVar EnvVar, reporter *utils.ComponentReporter) {
 	value := GetValue(envVar)
+	if value != "" && !checkType(envVar, value, reporter) {
+		return
+	}
 	if envVar.Validator != nil {
 		envVar.Validator(value, language, reporter)
 	} else {
@@ -62,6 +79,40 @@ func checkValue(e EnvVar, value string, report func(string)) bool {
 	return false
 }
 
+func checkType(e EnvVar, value string, reporter *utils.ComponentReporter) bool {
+	if e.Type == TypeString {
+		return true
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		if e.Type == TypeDuration {
+			reporter.AddError(fmt.Sprintf("%s must be a duration in milliseconds (e.g. '5000'), but is set to '%s'", e.Name, value))
+		} else {
+			reporter.AddError(fmt.Sprintf("%s must be an integer, but is set to '%s'", e.Name, value))
+		}
+		return false
+	}
+	if i < 0 {
+		reporter.AddError(fmt.Sprintf("%s must not be negative, but is set to '%s'", e.Name, value))
+		return false
+	}
+	return true
+}
+
+// GetIntValue returns the value of an integer or duration environment variable with its default value if not set.
+// The second return value is false if the value is unset without a default or cannot be parsed.
+func GetIntValue(envVar EnvVar) (int, bool) {
+	value := GetValue(envVar)
+	if value == "" {
+		return 0, false
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
 // CheckEnvVars validates multiple environment variables and reports the results
 func CheckEnvVars(reporter *utils.ComponentReporter, language string, envVars ...EnvVar) {
 	for _, envVar := range envVars {
@@ -0,0 +1,199 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// knownPropagators are the values of OTEL_PROPAGATORS defined by the OpenTelemetry specification
+// See: https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/#general-sdk-configuration
+var knownPropagators = []string{"tracecontext", "baggage", "b3", "b3multi", "jaeger", "xray", "ottrace", "none"}
+
+// unsupportedPropagators lists the propagators that cannot be selected through OTEL_PROPAGATORS for a language
+var unsupportedPropagators = map[string][]string{
+	"dotnet": {"jaeger", "xray", "ottrace"},
+	"js":     {"xray", "ottrace"},
+}
+
+// propagatorPackages lists, per language, the dependencies that provide a propagator.
+// Finding any of the listed dependencies is enough. Propagators not listed are built into the SDK.
+var propagatorPackages = map[string]map[string][]string{
+	"dotnet": {
+		"b3":      {"OpenTelemetry.AutoInstrumentation", "OpenTelemetry.Extensions.Propagators"},
+		"b3multi": {"OpenTelemetry.AutoInstrumentation", "OpenTelemetry.Extensions.Propagators"},
+	},
+	"go": {
+		"b3":      {"go.opentelemetry.io/contrib/propagators/b3", "go.opentelemetry.io/contrib/propagators/autoprop"},
+		"b3multi": {"go.opentelemetry.io/contrib/propagators/b3", "go.opentelemetry.io/contrib/propagators/autoprop"},
+		"jaeger":  {"go.opentelemetry.io/contrib/propagators/jaeger", "go.opentelemetry.io/contrib/propagators/autoprop"},
+		"xray":    {"go.opentelemetry.io/contrib/propagators/aws", "go.opentelemetry.io/contrib/propagators/autoprop"},
+		"ottrace": {"go.opentelemetry.io/contrib/propagators/ot", "go.opentelemetry.io/contrib/propagators/autoprop"},
+	},
+	"java": {
+		"b3":      {"opentelemetry-extension-trace-propagators"},
+		"b3multi": {"opentelemetry-extension-trace-propagators"},
+		"jaeger":  {"opentelemetry-extension-trace-propagators"},
+		"ottrace": {"opentelemetry-extension-trace-propagators"},
+		"xray":    {"opentelemetry-aws-xray-propagator"},
+	},
+	"js": {
+		"b3":      {"@opentelemetry/propagator-b3", "@opentelemetry/auto-instrumentations-node"},
+		"b3multi": {"@opentelemetry/propagator-b3", "@opentelemetry/auto-instrumentations-node"},
+		"jaeger":  {"@opentelemetry/propagator-jaeger", "@opentelemetry/auto-instrumentations-node"},
+	},
+	"php": {
+		"b3":      {"open-telemetry/extension-propagator-b3"},
+		"b3multi": {"open-telemetry/extension-propagator-b3"},
+		"jaeger":  {"open-telemetry/extension-propagator-jaeger"},
+		"xray":    {"open-telemetry/contrib-aws"},
+	},
+	"python": {
+		"b3":      {"opentelemetry-propagator-b3"},
+		"b3multi": {"opentelemetry-propagator-b3"},
+		"jaeger":  {"opentelemetry-propagator-jaeger"},
+		"xray":    {"opentelemetry-propagator-aws-xray"},
+		"ottrace": {"opentelemetry-propagator-ot-trace"},
+	},
+	"ruby": {
+		"b3":      {"opentelemetry-propagator-b3"},
+		"b3multi": {"opentelemetry-propagator-b3"},
+		"jaeger":  {"opentelemetry-propagator-jaeger"},
+		"xray":    {"opentelemetry-propagator-xray"},
+		"ottrace": {"opentelemetry-propagator-ottrace"},
+	},
+}
+
+// dependencyFiles lists, per language, the files that declare the dependencies of a project
+var dependencyFiles = map[string][]string{
+	"go":     {"go.mod"},
+	"java":   {"pom.xml", "build.gradle", "build.gradle.kts"},
+	"js":     {"package.json", "package-lock.json"},
+	"php":    {"composer.json", "composer.lock"},
+	"python": {"requirements.txt", "pyproject.toml", "poetry.lock"},
+	"ruby":   {"Gemfile", "Gemfile.lock"},
+}
+
+var OtelPropagators = EnvVar{
+	Name:         "OTEL_PROPAGATORS",
+	DefaultValue: "tracecontext,baggage",
+	Validator: func(value string, language string, reporter *utils.ComponentReporter) {
+		propagators := ParsePropagators(value)
+		valid := true
+		for _, p := range propagators {
+			if !slices.Contains(knownPropagators, p) {
+				reporter.AddError(fmt.Sprintf("OTEL_PROPAGATORS contains unknown propagator '%s'. Possible values: %s", p, strings.Join(knownPropagators, ", ")))
+				valid = false
+			} else if slices.Contains(unsupportedPropagators[language], p) {
+				reporter.AddError(fmt.Sprintf("OTEL_PROPAGATORS contains propagator '%s', which is not supported by the %s SDK", p, language))
+				valid = false
+			}
+		}
+
+		if slices.Contains(propagators, "none") {
+			if len(propagators) > 1 {
+				reporter.AddWarning("OTEL_PROPAGATORS contains 'none' together with other propagators. Use 'none' on its own to disable propagation, or remove it")
+			} else {
+				reporter.AddWarning("OTEL_PROPAGATORS is set to 'none'. Trace context will not be propagated between services")
+			}
+			return
+		}
+
+		if !slices.Contains(propagators, "tracecontext") {
+			reporter.AddWarning("OTEL_PROPAGATORS does not contain 'tracecontext'. Services using the default W3C Trace Context propagation will not join the same traces")
+			return
+		}
+
+		if valid {
+			reporter.AddSuccessfulCheck(fmt.Sprintf("OTEL_PROPAGATORS is set to '%s'", strings.Join(propagators, ",")))
+		}
+	},
+	Description: "Propagators used to inject and extract context",
+}
+
+// ParsePropagators parses the comma separated list of OTEL_PROPAGATORS, ignoring empty entries
+func ParsePropagators(value string) []string {
+	var propagators []string
+	for _, p := range strings.Split(value, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p != "" && !slices.Contains(propagators, p) {
+			propagators = append(propagators, p)
+		}
+	}
+	return propagators
+}
+
+// CheckPropagators validates OTEL_PROPAGATORS and checks that the propagators that need extra
+// packages can be found in the dependencies of the project
+func CheckPropagators(reporter *utils.ComponentReporter, commands utils.Commands) {
+	CheckEnvVar(commands.Language, OtelPropagators, reporter)
+
+	// the Java agent bundles all propagators
+	if commands.Language == "java" && !commands.ManualInstrumentation {
+		return
+	}
+	checkPropagatorDependencies(reporter, commands.Language, ParsePropagators(GetValue(OtelPropagators)), projectDir(commands))
+}
+
+func checkPropagatorDependencies(reporter *utils.ComponentReporter, language string, propagators []string, dir string) {
+	var required []string
+	for _, p := range propagators {
+		if _, ok := propagatorPackages[language][p]; ok {
+			required = append(required, p)
+		}
+	}
+	if len(required) == 0 {
+		return
+	}
+
+	files, content := readDependencyFiles(language, dir)
+	if len(files) == 0 {
+		reporter.AddWarning(fmt.Sprintf("Could not check dependencies for propagators %s: no dependency file found", strings.Join(required, ", ")))
+		return
+	}
+
+	for _, p := range required {
+		packages := propagatorPackages[language][p]
+		if slices.ContainsFunc(packages, func(pkg string) bool { return strings.Contains(content, pkg) }) {
+			reporter.AddSuccessfulCheck(fmt.Sprintf("Found dependency for propagator '%s'", p))
+		} else {
+			reporter.AddError(fmt.Sprintf("Propagator '%s' requires the dependency %s, which was not found in %s",
+				p, strings.Join(packages, " or "), strings.Join(files, ", ")))
+		}
+	}
+}
+
+// readDependencyFiles returns the names and the combined content of the dependency files found in dir
+func readDependencyFiles(language string, dir string) ([]string, string) {
+	candidates := dependencyFiles[language]
+	if language == "dotnet" {
+		candidates, _ = filepath.Glob(filepath.Join(dir, "*.csproj"))
+		for i, c := range candidates {
+			candidates[i] = filepath.Base(c)
+		}
+	}
+
+	var files []string
+	var content strings.Builder
+	for _, f := range candidates {
+		dat, err := os.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			continue
+		}
+		files = append(files, f)
+		content.Write(dat)
+		content.WriteString("\n")
+	}
+	return files, content.String()
+}
+
+func projectDir(commands utils.Commands) string {
+	if commands.Language == "js" && commands.PackageJsonPath != "" {
+		return commands.PackageJsonPath
+	}
+	return "."
+}
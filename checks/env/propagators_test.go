@@ -0,0 +1,167 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPropagatorsEnvVar(t *testing.T) {
+	tests := []utils.EnvVarTestCase{
+		{
+			Name:     "unset uses the default",
+			EnvVars:  map[string]string{},
+			Language: "python",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS is set to 'tracecontext,baggage'",
+			},
+		},
+		{
+			Name: "valid list with spaces",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "tracecontext, baggage, b3",
+			},
+			Language: "python",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS is set to 'tracecontext,baggage,b3'",
+			},
+		},
+		{
+			Name: "unknown propagator",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "tracecontext,w3c",
+			},
+			Language: "python",
+			ExpectedErrors: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS contains unknown propagator 'w3c'. Possible values: tracecontext, baggage, b3, b3multi, jaeger, xray, ottrace, none",
+			},
+		},
+		{
+			Name: "propagator not supported by the language",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "tracecontext,xray",
+			},
+			Language: "js",
+			ExpectedErrors: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS contains propagator 'xray', which is not supported by the js SDK",
+			},
+		},
+		{
+			Name: "tracecontext missing",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "b3multi",
+			},
+			Language: "java",
+			ExpectedWarnings: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS does not contain 'tracecontext'. Services using the default W3C Trace Context propagation will not join the same traces",
+			},
+		},
+		{
+			Name: "none mixed with other values",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "none,tracecontext",
+			},
+			Language: "go",
+			ExpectedWarnings: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS contains 'none' together with other propagators. Use 'none' on its own to disable propagation, or remove it",
+			},
+		},
+		{
+			Name: "none alone",
+			EnvVars: map[string]string{
+				"OTEL_PROPAGATORS": "none",
+			},
+			Language: "go",
+			ExpectedWarnings: []string{
+				"Common Environment Variables: OTEL_PROPAGATORS is set to 'none'. Trace context will not be propagated between services",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			utils.RunEnvVarComponentTest(t, tt, "Common Environment Variables",
+				func(reporter utils.Reporter, c *utils.ComponentReporter, language string, components []string) {
+					CheckEnvVar(language, OtelPropagators, c)
+				})
+		})
+	}
+}
+
+func TestCheckPropagatorDependencies(t *testing.T) {
+	tests := []struct {
+		name             string
+		language         string
+		files            map[string]string
+		propagators      []string
+		expectedChecks   []string
+		expectedWarnings []string
+		expectedErrors   []string
+	}{
+		{
+			name:        "built-in propagators need no dependency",
+			language:    "js",
+			propagators: []string{"tracecontext", "baggage"},
+		},
+		{
+			name:     "dependency found in package.json",
+			language: "js",
+			files: map[string]string{
+				"package.json": `{"dependencies": {"@opentelemetry/propagator-b3": "^1.30.0"}}`,
+			},
+			propagators:    []string{"tracecontext", "b3"},
+			expectedChecks: []string{"SDK: Found dependency for propagator 'b3'"},
+		},
+		{
+			name:     "dependency missing from requirements.txt",
+			language: "python",
+			files: map[string]string{
+				"requirements.txt": "opentelemetry-distro==0.50b0\n",
+			},
+			propagators: []string{"tracecontext", "jaeger"},
+			expectedErrors: []string{
+				"SDK: Propagator 'jaeger' requires the dependency opentelemetry-propagator-jaeger, which was not found in requirements.txt",
+			},
+		},
+		{
+			name:     "csproj is used for dotnet",
+			language: "dotnet",
+			files: map[string]string{
+				"app.csproj": `<Project Sdk="Microsoft.NET.Sdk"><ItemGroup><PackageReference Include="OpenTelemetry.Extensions.Propagators" Version="1.10.0" /></ItemGroup></Project>`,
+			},
+			propagators:    []string{"tracecontext", "b3multi"},
+			expectedChecks: []string{"SDK: Found dependency for propagator 'b3multi'"},
+		},
+		{
+			name:        "no dependency file",
+			language:    "go",
+			propagators: []string{"xray"},
+			expectedWarnings: []string{
+				"SDK: Could not check dependencies for propagators xray: no dependency file found",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+				if err != nil {
+					t.Fatalf("Failed to write file: %v", err)
+				}
+			}
+
+			reporter := utils.Reporter{}
+			component := reporter.Component("SDK")
+			checkPropagatorDependencies(component, tt.language, tt.propagators, dir)
+
+			assert.ElementsMatch(t, tt.expectedChecks, component.Checks, "checks mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, component.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedErrors, component.Errors, "errors mismatch")
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package env
+
+import (
+	"fmt"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// Batch processor, metric reader and limit environment variables
+// See: https://opentelemetry.io/docs/specs/otel/configuration/sdk-environment-variables/
+var (
+	OtelBSPScheduleDelay       = durationEnvVar("OTEL_BSP_SCHEDULE_DELAY", "5000", "Delay between two consecutive span exports")
+	OtelBSPExportTimeout       = durationEnvVar("OTEL_BSP_EXPORT_TIMEOUT", "30000", "Maximum allowed time to export spans")
+	OtelBSPMaxQueueSize        = intEnvVar("OTEL_BSP_MAX_QUEUE_SIZE", "2048", "Maximum queue size of the span processor")
+	OtelBSPMaxExportBatchSize  = intEnvVar("OTEL_BSP_MAX_EXPORT_BATCH_SIZE", "512", "Maximum batch size of the span processor")
+	OtelBLRPScheduleDelay      = durationEnvVar("OTEL_BLRP_SCHEDULE_DELAY", "1000", "Delay between two consecutive log exports")
+	OtelBLRPExportTimeout      = durationEnvVar("OTEL_BLRP_EXPORT_TIMEOUT", "30000", "Maximum allowed time to export logs")
+	OtelBLRPMaxQueueSize       = intEnvVar("OTEL_BLRP_MAX_QUEUE_SIZE", "2048", "Maximum queue size of the log record processor")
+	OtelBLRPMaxExportBatchSize = intEnvVar("OTEL_BLRP_MAX_EXPORT_BATCH_SIZE", "512", "Maximum batch size of the log record processor")
+	OtelMetricExportInterval   = durationEnvVar("OTEL_METRIC_EXPORT_INTERVAL", "60000", "Time interval between the start of two metric exports")
+	OtelMetricExportTimeout    = durationEnvVar("OTEL_METRIC_EXPORT_TIMEOUT", "30000", "Maximum allowed time to export metrics")
+	OtelExporterOTLPTimeout    = durationEnvVar("OTEL_EXPORTER_OTLP_TIMEOUT", "10000", "Maximum time the OTLP exporter waits for each batch export")
+
+	limitEnvVars = []EnvVar{
+		intEnvVar("OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT", "", "Maximum allowed attribute value size"),
+		intEnvVar("OTEL_ATTRIBUTE_COUNT_LIMIT", "128", "Maximum allowed attribute count"),
+		intEnvVar("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "", "Maximum allowed span attribute value size"),
+		intEnvVar("OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", "128", "Maximum allowed span attribute count"),
+		intEnvVar("OTEL_SPAN_EVENT_COUNT_LIMIT", "128", "Maximum allowed span event count"),
+		intEnvVar("OTEL_SPAN_LINK_COUNT_LIMIT", "128", "Maximum allowed span link count"),
+		intEnvVar("OTEL_EVENT_ATTRIBUTE_COUNT_LIMIT", "128", "Maximum allowed attribute per span event count"),
+		intEnvVar("OTEL_LINK_ATTRIBUTE_COUNT_LIMIT", "128", "Maximum allowed attribute per span link count"),
+		intEnvVar("OTEL_LOGRECORD_ATTRIBUTE_VALUE_LENGTH_LIMIT", "", "Maximum allowed log record attribute value size"),
+		intEnvVar("OTEL_LOGRECORD_ATTRIBUTE_COUNT_LIMIT", "128", "Maximum allowed log record attribute count"),
+	}
+)
+
+// SDKConfigEnvVars returns the batch processor, metric reader and limit environment variables
+func SDKConfigEnvVars() []EnvVar {
+	return append([]EnvVar{
+		OtelBSPScheduleDelay,
+		OtelBSPExportTimeout,
+		OtelBSPMaxQueueSize,
+		OtelBSPMaxExportBatchSize,
+		OtelBLRPScheduleDelay,
+		OtelBLRPExportTimeout,
+		OtelBLRPMaxQueueSize,
+		OtelBLRPMaxExportBatchSize,
+		OtelMetricExportInterval,
+		OtelMetricExportTimeout,
+		OtelExporterOTLPTimeout,
+	}, limitEnvVars...)
+}
+
+// CheckSDKConfig validates the types of the batch processor, metric reader and limit environment variables
+// that are set, and the rules between related variables
+func CheckSDKConfig(reporter *utils.ComponentReporter, language string) {
+	for _, envVar := range SDKConfigEnvVars() {
+		if IsEnvVarSet(envVar) {
+			CheckEnvVar(language, envVar, reporter)
+		}
+	}
+
+	checkOrder(OtelBSPMaxExportBatchSize, OtelBSPMaxQueueSize, true, reporter.AddError,
+		"%s (%d) must be less than or equal to %s (%d), otherwise the SDK ignores the batch size")
+	checkOrder(OtelBLRPMaxExportBatchSize, OtelBLRPMaxQueueSize, true, reporter.AddError,
+		"%s (%d) must be less than or equal to %s (%d), otherwise the SDK ignores the batch size")
+	checkOrder(OtelMetricExportTimeout, OtelMetricExportInterval, false, reporter.AddWarning,
+		"%s (%d) should be less than %s (%d), otherwise a slow export overlaps with the next one")
+}
+
+// checkOrder reports when the value of lower is greater than (or equal to, unless allowEqual is set) the value of upper.
+// The rule is only checked when at least one of the variables is set and both values are valid.
+func checkOrder(lower EnvVar, upper EnvVar, allowEqual bool, report func(string), format string) {
+	if !IsEnvVarSet(lower) && !IsEnvVarSet(upper) {
+		return
+	}
+	l, lok := GetIntValue(lower)
+	u, uok := GetIntValue(upper)
+	if !lok || !uok {
+		return
+	}
+	if l > u || (l == u && !allowEqual) {
+		report(fmt.Sprintf(format, lower.Name, l, upper.Name, u))
+	}
+}
+
+func intEnvVar(key string, defaultValue string, description string) EnvVar {
+	return EnvVar{
+		Name:         key,
+		DefaultValue: defaultValue,
+		Type:         TypeInteger,
+		Description:  description,
+	}
+}
+
+func durationEnvVar(key string, defaultValue string, description string) EnvVar {
+	return EnvVar{
+		Name:         key,
+		DefaultValue: defaultValue,
+		Type:         TypeDuration,
+		Description:  description,
+	}
+}
@@ -0,0 +1,102 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+func TestCheckSDKConfig(t *testing.T) {
+	tests := []utils.EnvVarTestCase{
+		{
+			Name:     "nothing set",
+			EnvVars:  map[string]string{},
+			Language: "java",
+		},
+		{
+			Name: "valid values",
+			EnvVars: map[string]string{
+				"OTEL_BSP_SCHEDULE_DELAY":     "1000",
+				"OTEL_BSP_MAX_QUEUE_SIZE":     "4096",
+				"OTEL_SPAN_EVENT_COUNT_LIMIT": "64",
+			},
+			Language: "java",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_BSP_SCHEDULE_DELAY is set to '1000'",
+				"Common Environment Variables: OTEL_BSP_MAX_QUEUE_SIZE is set to '4096'",
+				"Common Environment Variables: OTEL_SPAN_EVENT_COUNT_LIMIT is set to '64'",
+			},
+		},
+		{
+			Name: "wrong types and negative values",
+			EnvVars: map[string]string{
+				"OTEL_BSP_SCHEDULE_DELAY":    "5s",
+				"OTEL_ATTRIBUTE_COUNT_LIMIT": "many",
+				"OTEL_BLRP_MAX_QUEUE_SIZE":   "-1",
+			},
+			Language: "java",
+			ExpectedErrors: []string{
+				"Common Environment Variables: OTEL_BSP_SCHEDULE_DELAY must be a duration in milliseconds (e.g. '5000'), but is set to '5s'",
+				"Common Environment Variables: OTEL_ATTRIBUTE_COUNT_LIMIT must be an integer, but is set to 'many'",
+				"Common Environment Variables: OTEL_BLRP_MAX_QUEUE_SIZE must not be negative, but is set to '-1'",
+			},
+		},
+		{
+			Name: "batch size greater than the default queue size",
+			EnvVars: map[string]string{
+				"OTEL_BSP_MAX_EXPORT_BATCH_SIZE": "4096",
+			},
+			Language: "java",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_BSP_MAX_EXPORT_BATCH_SIZE is set to '4096'",
+			},
+			ExpectedErrors: []string{
+				"Common Environment Variables: OTEL_BSP_MAX_EXPORT_BATCH_SIZE (4096) must be less than or equal to OTEL_BSP_MAX_QUEUE_SIZE (2048), otherwise the SDK ignores the batch size",
+			},
+		},
+		{
+			Name: "log batch size equal to queue size",
+			EnvVars: map[string]string{
+				"OTEL_BLRP_MAX_EXPORT_BATCH_SIZE": "1024",
+				"OTEL_BLRP_MAX_QUEUE_SIZE":        "1024",
+			},
+			Language: "java",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_BLRP_MAX_EXPORT_BATCH_SIZE is set to '1024'",
+				"Common Environment Variables: OTEL_BLRP_MAX_QUEUE_SIZE is set to '1024'",
+			},
+		},
+		{
+			Name: "metric export timeout not less than interval",
+			EnvVars: map[string]string{
+				"OTEL_METRIC_EXPORT_INTERVAL": "30000",
+			},
+			Language: "java",
+			ExpectedChecks: []string{
+				"Common Environment Variables: OTEL_METRIC_EXPORT_INTERVAL is set to '30000'",
+			},
+			ExpectedWarnings: []string{
+				"Common Environment Variables: OTEL_METRIC_EXPORT_TIMEOUT (30000) should be less than OTEL_METRIC_EXPORT_INTERVAL (30000), otherwise a slow export overlaps with the next one",
+			},
+		},
+		{
+			Name: "cross field rule skipped for invalid values",
+			EnvVars: map[string]string{
+				"OTEL_METRIC_EXPORT_INTERVAL": "1m",
+			},
+			Language: "java",
+			ExpectedErrors: []string{
+				"Common Environment Variables: OTEL_METRIC_EXPORT_INTERVAL must be a duration in milliseconds (e.g. '5000'), but is set to '1m'",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			utils.RunEnvVarComponentTest(t, tt, "Common Environment Variables",
+				func(reporter utils.Reporter, c *utils.ComponentReporter, language string, components []string) {
+					CheckSDKConfig(c, language)
+				})
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package env
+
+import (
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SecretValues returns the values of the set environment variables that must be masked in reports:
+// the values of sensitive variables, each header value, the credentials of authorization headers
+// and the passwords of endpoint URLs
+func SecretValues() []string {
+	var secrets []string
+	for _, k := range Catalog() {
+		if k.IsPattern() {
+			continue
+		}
+		value := os.Getenv(k.Name)
+		if value == "" {
+			continue
+		}
+		if k.Sensitive {
+			secrets = append(secrets, value)
+			if strings.HasSuffix(k.Name, "_HEADERS") {
+				secrets = append(secrets, headerSecrets(value)...)
+			}
+		}
+		if strings.HasSuffix(k.Name, "_ENDPOINT") {
+			if u, err := url.Parse(value); err == nil && u.User != nil {
+				if password, ok := u.User.Password(); ok {
+					secrets = append(secrets, password)
+				}
+			}
+		}
+	}
+	return secrets
+}
+
+// headerSecrets returns the values of a header list in the format "key1=value1,key2=value2",
+// both as written and URL-decoded, and the credentials following an authorization scheme
+func headerSecrets(headers string) []string {
+	var secrets []string
+	for _, h := range strings.Split(headers, ",") {
+		_, value, found := strings.Cut(h, "=")
+		if !found {
+			continue
+		}
+		values := []string{strings.TrimSpace(value)}
+		if decoded, err := url.QueryUnescape(value); err == nil {
+			values = append(values, strings.TrimSpace(decoded))
+		}
+		for _, v := range values {
+			secrets = append(secrets, v)
+			if _, credentials, ok := strings.Cut(v, " "); ok {
+				secrets = append(secrets, strings.TrimSpace(credentials))
+			}
+		}
+	}
+	return secrets
+}
@@ -0,0 +1,182 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/otlp"
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// CheckTarget checks the protocol, endpoint and authorization of the OTLP exporter against the target profile
+func CheckTarget(reporter *utils.ComponentReporter, profile target.Profile) {
+	if profile.Name == "" {
+		return
+	}
+	checkTargetProtocol(reporter, profile)
+	checkTargetEndpoint(reporter, profile)
+	checkTargetAuth(reporter, profile)
+}
+
+func checkTargetProtocol(reporter *utils.ComponentReporter, profile target.Profile) {
+	if profile.Protocol == "" {
+		return
+	}
+	value := os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	if value == "" {
+		reporter.AddWarning(fmt.Sprintf("OTEL_EXPORTER_OTLP_PROTOCOL is not set. Set it to '%s', since the default differs between SDKs and target %s expects '%s'", profile.Protocol, profile.Name, profile.Protocol))
+		return
+	}
+	CheckProtocol(reporter, "OTEL_EXPORTER_OTLP_PROTOCOL", value, profile)
+}
+
+// CheckProtocol checks a set OTLP protocol, e.g. the value of OTEL_EXPORTER_OTLP_PROTOCOL, against the target profile.
+// name describes where the value is set.
+func CheckProtocol(reporter *utils.ComponentReporter, name string, value string, profile target.Profile) {
+	switch {
+	case profile.Protocol == "":
+	case value == profile.Protocol:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("%s is set to '%s' as expected by target %s", name, value, profile.Name))
+	default:
+		reporter.AddError(fmt.Sprintf("%s is set to '%s', but target %s expects '%s'", name, value, profile.Name, profile.Protocol))
+	}
+}
+
+func checkTargetEndpoint(reporter *utils.ComponentReporter, profile target.Profile) {
+	value := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	switch {
+	case value == "" && profile.Local:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("OTEL_EXPORTER_OTLP_ENDPOINT is not set, so the SDK sends to the default endpoint of %s", profile.Backend))
+	case value == "":
+		reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_ENDPOINT is not set. Set it to an endpoint similar to %s to send telemetry to %s", profile.EndpointExample, profile.Backend))
+	default:
+		CheckEndpoint(reporter, "OTEL_EXPORTER_OTLP_ENDPOINT", value, profile)
+	}
+}
+
+// CheckEndpoint checks a set OTLP endpoint, e.g. the value of OTEL_EXPORTER_OTLP_ENDPOINT, against the target profile.
+// name describes where the value is set.
+func CheckEndpoint(reporter *utils.ComponentReporter, name string, value string, profile target.Profile) {
+	switch {
+	case profile.Warns(target.WarnLocalhost) && strings.Contains(value, "localhost"):
+		reporter.AddWarning(fmt.Sprintf("%s is set to localhost. Update to an endpoint similar to %s to be able to send telemetry to %s", name, profile.EndpointExample, profile.Backend))
+	case !profile.MatchesEndpoint(value):
+		reporter.AddError(fmt.Sprintf("%s is not set in the format similar to %s expected by target %s", name, profile.EndpointExample, profile.Name))
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("%s matches the format expected by target %s", name, profile.Name))
+	}
+}
+
+func checkTargetAuth(reporter *utils.ComponentReporter, profile target.Profile) {
+	if profile.Auth == "" {
+		return
+	}
+	authorization, found := "", false
+	for k, v := range otlp.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")) {
+		if strings.EqualFold(k, "Authorization") {
+			authorization, found = v, true
+		}
+	}
+
+	if profile.Auth == target.AuthNone {
+		if found {
+			reporter.AddWarning(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS sets an Authorization header, but target %s does not expect authentication", profile.Name))
+		}
+		return
+	}
+	if !found {
+		reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS has no Authorization header, but target %s expects %s authentication", profile.Name, profile.Auth))
+		return
+	}
+	scheme, _, _ := strings.Cut(authorization, " ")
+	if !strings.EqualFold(scheme, profile.Auth) {
+		reporter.AddError(fmt.Sprintf("The Authorization header in OTEL_EXPORTER_OTLP_HEADERS uses the '%s' scheme, but target %s expects %s authentication", scheme, profile.Name, profile.Auth))
+		return
+	}
+	reporter.AddSuccessfulCheck(fmt.Sprintf("The Authorization header in OTEL_EXPORTER_OTLP_HEADERS uses %s authentication as expected by target %s", profile.Auth, profile.Name))
+}
+
+// SDKEndpoint returns the transport (http or grpc) and port the OTLP exporter of the SDK sends to, according to
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_PROTOCOL. ok is false if neither is set, if the endpoint is not a
+// valid URL, which the checks of the environment variables report, or if the SDK sends to Grafana Cloud directly.
+func SDKEndpoint(getenv func(string) string) (transport string, port string, ok bool) {
+	endpoint := getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	protocol := getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	if endpoint == "" && protocol == "" || strings.Contains(endpoint, "grafana.net") {
+		return "", "", false
+	}
+
+	transport, port = "http", "4318"
+	if protocol == "grpc" {
+		transport, port = "grpc", "4317"
+	}
+	if endpoint == "" {
+		return transport, port, true
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return "", "", false
+	}
+	switch {
+	case u.Port() != "":
+		port = u.Port()
+	case u.Scheme == "https":
+		port = "443"
+	default:
+		port = "80"
+	}
+	return transport, port, true
+}
+
+// OTLPReceiver is a server that can receive OTLP from the SDK, e.g. the http protocol of an otlp receiver of the
+// collector
+type OTLPReceiver struct {
+	// Name is the location of the server in the messages, e.g. receivers > otlp > protocols > http on config.yaml
+	Name string
+	// Transport is http or grpc
+	Transport string
+	Port      string
+}
+
+// CheckSDKReceivers checks that one of the receivers listens on the port the OTLP exporter of the SDK sends to, with
+// the same transport. kind describes the receivers in the messages, e.g. otlp receiver on config.yaml, and missing is
+// reported if there are no receivers, unless it is empty. It is only checked if the SDK is configured in the
+// environment and doesn't send to Grafana Cloud directly.
+func CheckSDKReceivers(reporter *utils.ComponentReporter, receivers []OTLPReceiver, kind string, missing string, getenv func(string) string) {
+	transport, port, ok := SDKEndpoint(getenv)
+	if !ok {
+		return
+	}
+	if len(receivers) == 0 {
+		if missing != "" {
+			reporter.AddError(fmt.Sprintf("The SDK sends OTLP over %s to port %s, but %s. Add one that receives OTLP over %s", transport, port, missing, transport))
+		}
+		return
+	}
+
+	var configured []string
+	for _, r := range receivers {
+		if r.Port != port {
+			configured = append(configured, fmt.Sprintf("%s (%s)", r.Port, r.Transport))
+			continue
+		}
+		if r.Transport == transport {
+			reporter.AddSuccessfulCheck(fmt.Sprintf("The SDK sends OTLP over %s to port %s, where %s receives it", transport, port, r.Name))
+		} else {
+			reporter.AddError(fmt.Sprintf("The SDK sends OTLP over %s to port %s, but %s receives OTLP over %s on this port. Set OTEL_EXPORTER_OTLP_PROTOCOL to %s or send to the port that receives OTLP over %s", transport, port, r.Name, r.Transport, sdkProtocol(r.Transport), transport))
+		}
+		return
+	}
+	reporter.AddError(fmt.Sprintf("The SDK sends OTLP over %s to port %s, but no %s listens on it. Configured ports: %s", transport, port, kind, strings.Join(configured, ", ")))
+}
+
+// sdkProtocol returns the value of OTEL_EXPORTER_OTLP_PROTOCOL for the transport
+func sdkProtocol(transport string) string {
+	if transport == "grpc" {
+		return "grpc"
+	}
+	return "http/protobuf"
+}
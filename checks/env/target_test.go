@@ -0,0 +1,111 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/target"
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckTarget(t *testing.T) {
+	tests := []struct {
+		utils.EnvVarTestCase
+		target string
+	}{
+		{
+			target: "grafana-cloud",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name: "grafana cloud",
+				EnvVars: map[string]string{
+					"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+					"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Basic%20MTIzOmFiYw==",
+				},
+				ExpectedChecks: []string{
+					"Target: OTEL_EXPORTER_OTLP_PROTOCOL is set to 'http/protobuf' as expected by target grafana-cloud",
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT matches the format expected by target grafana-cloud",
+					"Target: The Authorization header in OTEL_EXPORTER_OTLP_HEADERS uses basic authentication as expected by target grafana-cloud",
+				},
+			},
+		},
+		{
+			target: "grafana-cloud",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name: "grafana cloud with self-hosted settings",
+				EnvVars: map[string]string{
+					"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "https://lgtm.example.com:4317",
+					"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Bearer abc",
+				},
+				ExpectedErrors: []string{
+					"Target: OTEL_EXPORTER_OTLP_PROTOCOL is set to 'grpc', but target grafana-cloud expects 'http/protobuf'",
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT is not set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp expected by target grafana-cloud",
+					"Target: The Authorization header in OTEL_EXPORTER_OTLP_HEADERS uses the 'Bearer' scheme, but target grafana-cloud expects basic authentication",
+				},
+			},
+		},
+		{
+			target: "otlp",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name: "self-hosted",
+				EnvVars: map[string]string{
+					"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "https://lgtm.example.com:4317",
+				},
+				ExpectedChecks: []string{
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT matches the format expected by target otlp",
+				},
+			},
+		},
+		{
+			target: "otlp",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name: "self-hosted on localhost",
+				EnvVars: map[string]string{
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318",
+				},
+				ExpectedWarnings: []string{
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT is set to localhost. Update to an endpoint similar to https://otlp.example.com:4318 to be able to send telemetry to your OTLP endpoint",
+				},
+			},
+		},
+		{
+			target: "local-collector",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name:    "local collector with default endpoint",
+				EnvVars: map[string]string{},
+				ExpectedChecks: []string{
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT is not set, so the SDK sends to the default endpoint of the local collector",
+				},
+			},
+		},
+		{
+			target: "local-collector",
+			EnvVarTestCase: utils.EnvVarTestCase{
+				Name: "local collector with remote endpoint and credentials",
+				EnvVars: map[string]string{
+					"OTEL_EXPORTER_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+					"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Basic%20MTIzOmFiYw==",
+				},
+				ExpectedErrors: []string{
+					"Target: OTEL_EXPORTER_OTLP_ENDPOINT is not set in the format similar to http://localhost:4318 expected by target local-collector",
+				},
+				ExpectedWarnings: []string{
+					"Target: OTEL_EXPORTER_OTLP_HEADERS sets an Authorization header, but target local-collector does not expect authentication",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			profile, err := target.Load(tt.target, "")
+			require.NoError(t, err)
+			utils.RunEnvVarComponentTest(t, tt.EnvVarTestCase, "Target",
+				func(reporter utils.Reporter, c *utils.ComponentReporter, language string, components []string) {
+					CheckTarget(c, profile)
+				})
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package env
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// CertificateExpiryWarning is how long before expiry a certificate is reported, both for the certificate files of
+// the environment variables and for the certificates of the endpoints
+const CertificateExpiryWarning = 14 * 24 * time.Hour
+
+// ExporterTLS are the TLS options of the OTLP exporter
+type ExporterTLS struct {
+	// Certificate is the path of the CA certificates used to verify the server
+	Certificate string
+	// ClientCertificate and ClientKey are the paths of the client certificate and key for mTLS
+	ClientCertificate string
+	ClientKey         string
+}
+
+// ExporterTLSFromEnv returns the TLS options of the OTLP exporter of a signal ("traces", "metrics" or "logs"),
+// where signal specific variables take precedence. An empty signal returns the options for all signals.
+func ExporterTLSFromEnv(signal string) ExporterTLS {
+	return ExporterTLS{
+		Certificate:       exporterOption(signal, "CERTIFICATE"),
+		ClientCertificate: exporterOption(signal, "CLIENT_CERTIFICATE"),
+		ClientKey:         exporterOption(signal, "CLIENT_KEY"),
+	}
+}
+
+// ExporterTimeout returns the timeout of the OTLP exporter of a signal, where the signal specific variable takes precedence
+func ExporterTimeout(signal string) time.Duration {
+	ms, ok := GetIntValue(EnvVar{Name: exporterOptionName(signal, "TIMEOUT")})
+	if !ok {
+		ms, ok = GetIntValue(OtelExporterOTLPTimeout)
+	}
+	if !ok {
+		ms, _ = strconv.Atoi(OtelExporterOTLPTimeout.DefaultValue)
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+func exporterOption(signal string, option string) string {
+	if value := os.Getenv(exporterOptionName(signal, option)); value != "" {
+		return value
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + option)
+}
+
+func exporterOptionName(signal string, option string) string {
+	if signal == "" {
+		return "OTEL_EXPORTER_OTLP_" + option
+	}
+	return fmt.Sprintf("OTEL_EXPORTER_OTLP_%s_%s", strings.ToUpper(signal), option)
+}
+
+// TLSConfig returns the TLS configuration the exporter uses, or nil if no TLS option is set
+func (t ExporterTLS) TLSConfig() (*tls.Config, error) {
+	if t == (ExporterTLS{}) {
+		return nil, nil
+	}
+	config := &tls.Config{}
+	if t.Certificate != "" {
+		certs, err := readCertificates(t.Certificate)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		for _, c := range certs {
+			config.RootCAs.AddCert(c)
+		}
+	}
+	if t.ClientCertificate != "" || t.ClientKey != "" {
+		if t.ClientCertificate == "" || t.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and client key must be set together")
+		}
+		pair, err := tls.LoadX509KeyPair(t.ClientCertificate, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client key does not match client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{pair}
+	}
+	return config, nil
+}
+
+// readCertificates reads all PEM encoded certificates of a file
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("file '%s' does not exist", path)
+		}
+		return nil, fmt.Errorf("could not read '%s': %w", path, err)
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' contains an invalid certificate: %w", path, err)
+		}
+		certs = append(certs, c)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("'%s' does not contain a PEM encoded certificate", path)
+	}
+	return certs, nil
+}
+
+// CheckExporterTLS validates the certificate files referenced by the OTLP exporter TLS variables,
+// for all signals and per signal, and OTEL_EXPORTER_OTLP_INSECURE
+func CheckExporterTLS(reporter *utils.ComponentReporter, now time.Time) {
+	for _, signal := range []string{"", "traces", "metrics", "logs"} {
+		certificate := exporterOptionName(signal, "CERTIFICATE")
+		if path := os.Getenv(certificate); path != "" {
+			checkCertificateFile(reporter, certificate, path, now)
+		}
+		clientCertificate := exporterOptionName(signal, "CLIENT_CERTIFICATE")
+		clientKey := exporterOptionName(signal, "CLIENT_KEY")
+		certPath, keyPath := os.Getenv(clientCertificate), os.Getenv(clientKey)
+		if certPath != "" && !checkCertificateFile(reporter, clientCertificate, certPath, now) {
+			continue
+		}
+		switch {
+		case certPath != "" && keyPath == "":
+			reporter.AddError(fmt.Sprintf("%s is set, but %s is not", clientCertificate, clientKey))
+		case certPath == "" && keyPath != "":
+			reporter.AddError(fmt.Sprintf("%s is set, but %s is not", clientKey, clientCertificate))
+		case certPath != "" && keyPath != "":
+			if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+				reporter.AddError(fmt.Sprintf("%s '%s' is not a valid key for %s: %s", clientKey, keyPath, clientCertificate, err))
+			} else {
+				reporter.AddSuccessfulCheck(fmt.Sprintf("%s matches %s", clientKey, clientCertificate))
+			}
+		}
+	}
+
+	for _, signal := range []string{"", "traces", "metrics", "logs"} {
+		name := exporterOptionName(signal, "INSECURE")
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if value != "true" && value != "false" {
+			reporter.AddError(fmt.Sprintf("%s must be 'true' or 'false', but is set to '%s'", name, value))
+			continue
+		}
+		if value == "true" && exporterOption(signal, "PROTOCOL") != "grpc" {
+			reporter.AddWarning(fmt.Sprintf("%s only applies to grpc endpoints without a scheme and does not disable certificate verification for http/protobuf", name))
+		}
+	}
+}
+
+// checkCertificateFile reports whether the file exists, contains certificates and whether they are valid now
+func checkCertificateFile(reporter *utils.ComponentReporter, name string, path string, now time.Time) bool {
+	certs, err := readCertificates(path)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("%s: %s", name, err))
+		return false
+	}
+	valid := true
+	for _, c := range certs {
+		switch {
+		case now.After(c.NotAfter):
+			reporter.AddError(fmt.Sprintf("%s: certificate '%s' in '%s' expired on %s", name, c.Subject, path, c.NotAfter.Format(time.DateOnly)))
+			valid = false
+		case now.Before(c.NotBefore):
+			reporter.AddError(fmt.Sprintf("%s: certificate '%s' in '%s' is not valid before %s", name, c.Subject, path, c.NotBefore.Format(time.DateOnly)))
+			valid = false
+		case c.NotAfter.Sub(now) < CertificateExpiryWarning:
+			reporter.AddWarning(fmt.Sprintf("%s: certificate '%s' in '%s' expires on %s", name, c.Subject, path, c.NotAfter.Format(time.DateOnly)))
+		}
+	}
+	if valid {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("%s contains %d valid certificate(s)", name, len(certs)))
+	}
+	return valid
+}
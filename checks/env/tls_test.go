@@ -0,0 +1,171 @@
+package env
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+// writeCertificate writes a self-signed certificate and its key valid between notBefore and notAfter
+// and returns the paths of both files
+func writeCertificate(t *testing.T, dir string, name string, notBefore time.Time, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certPath, keyPath
+}
+
+func TestCheckExporterTLS(t *testing.T) {
+	dir := t.TempDir()
+	validCert, validKey := writeCertificate(t, dir, "valid", testNow.AddDate(-1, 0, 0), testNow.AddDate(1, 0, 0))
+	expiredCert, _ := writeCertificate(t, dir, "expired", testNow.AddDate(-2, 0, 0), testNow.AddDate(-1, 0, 0))
+	expiringCert, _ := writeCertificate(t, dir, "expiring", testNow.AddDate(-1, 0, 0), testNow.AddDate(0, 0, 3))
+	_, otherKey := writeCertificate(t, dir, "other", testNow.AddDate(-1, 0, 0), testNow.AddDate(1, 0, 0))
+	notPEM := filepath.Join(dir, "not-pem.crt")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+
+	tests := []utils.EnvVarTestCase{
+		{
+			Name: "valid CA and client certificate",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_CERTIFICATE":        validCert,
+				"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE": validCert,
+				"OTEL_EXPORTER_OTLP_CLIENT_KEY":         validKey,
+			},
+			ExpectedChecks: []string{
+				"TLS: OTEL_EXPORTER_OTLP_CERTIFICATE contains 1 valid certificate(s)",
+				"TLS: OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE contains 1 valid certificate(s)",
+				"TLS: OTEL_EXPORTER_OTLP_CLIENT_KEY matches OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE",
+			},
+		},
+		{
+			Name: "missing and invalid files",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_CERTIFICATE":        "DIR/missing.crt",
+				"OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE": notPEM,
+			},
+			ExpectedErrors: []string{
+				"TLS: OTEL_EXPORTER_OTLP_CERTIFICATE: file 'DIR/missing.crt' does not exist",
+				"TLS: OTEL_EXPORTER_OTLP_TRACES_CERTIFICATE: 'DIR/not-pem.crt' does not contain a PEM encoded certificate",
+			},
+		},
+		{
+			Name: "expired and expiring certificates",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_CERTIFICATE":         expiredCert,
+				"OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE": expiringCert,
+			},
+			ExpectedErrors: []string{
+				"TLS: OTEL_EXPORTER_OTLP_CERTIFICATE: certificate 'CN=expired' in 'DIR/expired.crt' expired on 2024-06-01",
+			},
+			ExpectedWarnings: []string{
+				"TLS: OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE: certificate 'CN=expiring' in 'DIR/expiring.crt' expires on 2025-06-04",
+			},
+			ExpectedChecks: []string{
+				"TLS: OTEL_EXPORTER_OTLP_METRICS_CERTIFICATE contains 1 valid certificate(s)",
+			},
+		},
+		{
+			Name: "client key does not match",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE": validCert,
+				"OTEL_EXPORTER_OTLP_CLIENT_KEY":         otherKey,
+			},
+			ExpectedErrors: []string{
+				"TLS: OTEL_EXPORTER_OTLP_CLIENT_KEY 'DIR/other.key' is not a valid key for OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE: tls: private key does not match public key",
+			},
+			ExpectedChecks: []string{
+				"TLS: OTEL_EXPORTER_OTLP_CLIENT_CERTIFICATE contains 1 valid certificate(s)",
+			},
+		},
+		{
+			Name: "client key without certificate",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_LOGS_CLIENT_KEY": validKey,
+			},
+			ExpectedErrors: []string{
+				"TLS: OTEL_EXPORTER_OTLP_LOGS_CLIENT_KEY is set, but OTEL_EXPORTER_OTLP_LOGS_CLIENT_CERTIFICATE is not",
+			},
+		},
+		{
+			Name: "insecure",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_INSECURE":        "true",
+				"OTEL_EXPORTER_OTLP_TRACES_INSECURE": "yes",
+			},
+			ExpectedErrors: []string{
+				"TLS: OTEL_EXPORTER_OTLP_TRACES_INSECURE must be 'true' or 'false', but is set to 'yes'",
+			},
+			ExpectedWarnings: []string{
+				"TLS: OTEL_EXPORTER_OTLP_INSECURE only applies to grpc endpoints without a scheme and does not disable certificate verification for http/protobuf",
+			},
+		},
+		{
+			Name: "insecure with grpc",
+			EnvVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_INSECURE": "true",
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			for k, v := range tt.EnvVars {
+				t.Setenv(k, v)
+			}
+			reporter := utils.Reporter{}
+			component := reporter.Component("TLS")
+			CheckExporterTLS(component, testNow)
+
+			replace := func(messages []string) []string {
+				var res []string
+				for _, m := range messages {
+					res = append(res, strings.ReplaceAll(m, dir, "DIR"))
+				}
+				return res
+			}
+			assert.ElementsMatch(t, tt.ExpectedErrors, replace(component.Errors), "errors mismatch")
+			assert.ElementsMatch(t, tt.ExpectedWarnings, replace(component.Warnings), "warnings mismatch")
+			assert.ElementsMatch(t, tt.ExpectedChecks, replace(component.Checks), "checks mismatch")
+		})
+	}
+}
+
+func TestExporterTimeout(t *testing.T) {
+	assert.Equal(t, 10*time.Second, ExporterTimeout("traces"))
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TIMEOUT", "5000")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_TIMEOUT", "2000")
+	assert.Equal(t, 5*time.Second, ExporterTimeout("traces"))
+	assert.Equal(t, 2*time.Second, ExporterTimeout("logs"))
+}
@@ -0,0 +1,91 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// CheckUnknownEnvVars reports environment variables with a checked prefix that are not in the catalog,
+// together with the closest known name
+func CheckUnknownEnvVars(reporter *utils.ComponentReporter, language string) {
+	var names []string
+	for _, e := range os.Environ() {
+		name, _, _ := strings.Cut(e, "=")
+		names = append(names, name)
+	}
+	checkUnknownEnvVars(reporter, language, names)
+}
+
+func checkUnknownEnvVars(reporter *utils.ComponentReporter, language string, names []string) {
+	catalog := Catalog()
+	slices.Sort(names)
+	for _, name := range names {
+		if !slices.ContainsFunc(checkedPrefixes, func(prefix string) bool { return strings.HasPrefix(name, prefix) }) {
+			continue
+		}
+
+		i := slices.IndexFunc(catalog, func(k KnownVar) bool { return k.Matches(name) })
+		if i >= 0 {
+			known := catalog[i]
+			if !known.UsedBy(language) {
+				reporter.AddWarning(fmt.Sprintf("%s is only used by %s and has no effect for %s", name, strings.Join(known.Languages, ", "), language))
+			}
+			continue
+		}
+
+		suggestion := closestKnownVar(catalog, name, language)
+		if suggestion != "" {
+			reporter.AddWarning(fmt.Sprintf("%s is not a known environment variable. Did you mean %s?", name, suggestion))
+		} else {
+			reporter.AddWarning(fmt.Sprintf("%s is not a known environment variable", name))
+		}
+	}
+}
+
+// closestKnownVar returns the known variable for the language with the smallest edit distance to name,
+// or an empty string if no known variable is close enough
+func closestKnownVar(catalog []KnownVar, name string, language string) string {
+	best := ""
+	bestDistance := max(2, len(name)/8) + 1
+	for _, k := range catalog {
+		if k.IsPattern() || !k.UsedBy(language) {
+			continue
+		}
+		d := editDistance(name, k.Name)
+		if d < bestDistance {
+			best = k.Name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// editDistance returns the optimal string alignment distance between a and b,
+// which counts insertions, deletions, substitutions and transpositions of adjacent characters
+func editDistance(a string, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
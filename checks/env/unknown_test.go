@@ -0,0 +1,83 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUnknownEnvVars(t *testing.T) {
+	tests := []struct {
+		name             string
+		language         string
+		names            []string
+		expectedWarnings []string
+	}{
+		{
+			name:     "known variables",
+			language: "java",
+			names: []string{
+				"OTEL_EXPORTER_OTLP_ENDPOINT",
+				"OTEL_EXPORTER_OTLP_TRACES_HEADERS",
+				"OTEL_BSP_MAX_QUEUE_SIZE",
+				"OTEL_INSTRUMENTATION_JDBC_ENABLED",
+				"BEYLA_OPEN_PORT",
+				"GRAFANA_CLOUD_API_KEY",
+				"HOME",
+				"PATH",
+			},
+		},
+		{
+			name:     "transposed letters",
+			language: "js",
+			names:    []string{"OTEL_EXPORTER_OTPL_ENDPOINT"},
+			expectedWarnings: []string{
+				"Common Environment Variables: OTEL_EXPORTER_OTPL_ENDPOINT is not a known environment variable. Did you mean OTEL_EXPORTER_OTLP_ENDPOINT?",
+			},
+		},
+		{
+			name:     "language specific suggestion",
+			language: "dotnet",
+			names:    []string{"OTEL_DOTNET_AUTO_HOMe", "CORECLR_PROFILER_PAHT"},
+			expectedWarnings: []string{
+				"Common Environment Variables: OTEL_DOTNET_AUTO_HOMe is not a known environment variable. Did you mean OTEL_DOTNET_AUTO_HOME?",
+				"Common Environment Variables: CORECLR_PROFILER_PAHT is not a known environment variable. Did you mean CORECLR_PROFILER_PATH?",
+			},
+		},
+		{
+			name:     "no close match",
+			language: "go",
+			names:    []string{"OTEL_SOMETHING_COMPLETELY_DIFFERENT"},
+			expectedWarnings: []string{
+				"Common Environment Variables: OTEL_SOMETHING_COMPLETELY_DIFFERENT is not a known environment variable",
+			},
+		},
+		{
+			name:     "variable of another language",
+			language: "python",
+			names:    []string{"OTEL_NODE_RESOURCE_DETECTORS", "OTEL_INSTRUMENTATION_HTTP_CAPTURE_HEADERS_SERVER_REQUEST"},
+			expectedWarnings: []string{
+				"Common Environment Variables: OTEL_NODE_RESOURCE_DETECTORS is only used by js and has no effect for python",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := utils.Reporter{}
+			component := reporter.Component("Common Environment Variables")
+			checkUnknownEnvVars(component, tt.language, tt.names)
+
+			assert.ElementsMatch(t, tt.expectedWarnings, component.Warnings, "warnings mismatch")
+			assert.Empty(t, component.Errors, "no errors expected")
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("OTEL_SERVICE_NAME", "OTEL_SERVICE_NAME"))
+	assert.Equal(t, 1, editDistance("OTEL_EXPORTER_OTPL_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"))
+	assert.Equal(t, 1, editDistance("OTEL_SERVICE_NAM", "OTEL_SERVICE_NAME"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
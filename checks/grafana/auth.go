@@ -0,0 +1,158 @@
+package grafana
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+var GrafanaCloudInstanceID = env.EnvVar{
+	Name:        "GRAFANA_CLOUD_INSTANCE_ID",
+	Description: "Grafana Cloud instance ID",
+}
+
+var instanceIDRegex = regexp.MustCompile(`^[0-9]+$`)
+
+// BasicAuth is the decoded credential of a Grafana Cloud Basic authorization header
+type BasicAuth struct {
+	InstanceID string
+	Token      string
+}
+
+// accessPolicyToken is the payload of a Grafana Cloud access policy token (glc_...)
+type accessPolicyToken struct {
+	Org  string `json:"o"`
+	Name string `json:"n"`
+	Key  string `json:"k"`
+}
+
+// basicAuthPrefix returns how the Basic scheme must be written in OTEL_EXPORTER_OTLP_HEADERS for a language
+func basicAuthPrefix(language string) string {
+	if language == "python" {
+		return "Basic%20"
+	}
+	return "Basic "
+}
+
+// authorizationHeader returns the value of the Authorization header in a list in the format "key1=value1,key2=value2"
+func authorizationHeader(headers string) (string, bool) {
+	for _, h := range strings.Split(headers, ",") {
+		key, value, _ := strings.Cut(h, "=")
+		if strings.EqualFold(strings.TrimSpace(key), "Authorization") {
+			return strings.TrimSpace(value), true
+		}
+	}
+	return "", false
+}
+
+func validateHeaders(value string, language string, reporter *utils.ComponentReporter) {
+	prefix := basicAuthPrefix(language)
+	expected := fmt.Sprintf("Value should have 'Authorization=%s...'", prefix)
+
+	header, found := authorizationHeader(value)
+	if !found {
+		reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS is not set. %s", expected))
+		return
+	}
+
+	credential, ok := strings.CutPrefix(header, prefix)
+	if !ok {
+		other := "Basic "
+		if prefix == other {
+			other = "Basic%20"
+		}
+		switch {
+		case strings.HasPrefix(header, "Basic%2520"):
+			reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS is URL-encoded twice ('Basic%%2520'). %s", expected))
+		case strings.HasPrefix(header, other):
+			reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS uses '%s', which is not read correctly by the %s SDK. %s", other, language, expected))
+		default:
+			reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS is not set. %s", expected))
+		}
+		return
+	}
+
+	auth, err := DecodeBasicAuth(credential)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("OTEL_EXPORTER_OTLP_HEADERS has an invalid Authorization header: %s", err))
+		return
+	}
+	reporter.AddSecret(auth.Token)
+	if !looksLikeToken(auth.Token) {
+		reporter.AddWarning("The token in the Authorization header of OTEL_EXPORTER_OTLP_HEADERS does not look like a Grafana Cloud access policy token (glc_...)")
+	}
+	reporter.AddSuccessfulCheck("OTEL_EXPORTER_OTLP_HEADERS is set correctly")
+
+	instanceID := env.GetValue(GrafanaCloudInstanceID)
+	if instanceID != "" {
+		if instanceID == auth.InstanceID {
+			reporter.AddSuccessfulCheck("GRAFANA_CLOUD_INSTANCE_ID matches the instance id in OTEL_EXPORTER_OTLP_HEADERS")
+		} else {
+			reporter.AddError(fmt.Sprintf("GRAFANA_CLOUD_INSTANCE_ID (%s) does not match the instance id in OTEL_EXPORTER_OTLP_HEADERS (%s)", instanceID, auth.InstanceID))
+		}
+	}
+}
+
+// DecodeBasicAuth decodes the base64 credential of a Basic authorization header and checks
+// that it has the format <instance-id>:<token>
+func DecodeBasicAuth(credential string) (BasicAuth, error) {
+	credential = strings.TrimSpace(credential)
+	if strings.HasPrefix(credential, "glc_") || strings.Contains(credential, ":") {
+		return BasicAuth{}, fmt.Errorf("the credential is not base64 encoded. Encode '<instance-id>:<token>' with base64, e.g. with 'echo -n \"<instance-id>:<token>\" | base64 -w0'")
+	}
+
+	decoded, err := decodeBase64(credential)
+	if err != nil {
+		return BasicAuth{}, fmt.Errorf("the credential is not valid base64: %s", err)
+	}
+
+	instanceID, token, found := strings.Cut(string(decoded), ":")
+	if !found {
+		return BasicAuth{}, fmt.Errorf("the decoded credential must have the format '<instance-id>:<token>', but it has no ':'")
+	}
+	if !instanceIDRegex.MatchString(instanceID) {
+		return BasicAuth{}, fmt.Errorf("the instance id '%s' of the decoded credential must be numeric", instanceID)
+	}
+	if token == "" {
+		return BasicAuth{}, fmt.Errorf("the token of the decoded credential is empty")
+	}
+	return BasicAuth{InstanceID: instanceID, Token: token}, nil
+}
+
+func looksLikeToken(token string) bool {
+	if _, ok := decodeAccessPolicyToken(token); ok {
+		return true
+	}
+	// legacy API keys are base64 encoded JSON objects
+	return strings.HasPrefix(token, "eyJ")
+}
+
+func decodeAccessPolicyToken(token string) (accessPolicyToken, bool) {
+	payload, ok := strings.CutPrefix(token, "glc_")
+	if !ok {
+		return accessPolicyToken{}, false
+	}
+	decoded, err := decodeBase64(payload)
+	if err != nil {
+		return accessPolicyToken{}, false
+	}
+	var t accessPolicyToken
+	if err := json.Unmarshal(decoded, &t); err != nil || t.Key == "" {
+		return accessPolicyToken{}, false
+	}
+	return t, true
+}
+
+// decodeBase64 decodes standard base64, with or without padding
+func decodeBase64(s string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+	}
+	return decoded, nil
+}
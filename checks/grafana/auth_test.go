@@ -0,0 +1,148 @@
+package grafana
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+func TestValidateHeaders(t *testing.T) {
+	tests := []utils.EnvVarTestCase{
+		{
+			Name:     "valid python header",
+			EnvVars:  correctWith(map[string]string{}),
+			Language: "python",
+			ExpectedChecks: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
+			},
+		},
+		{
+			Name: "valid java header",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic " + validCredential,
+			}),
+			Language: "java",
+			ExpectedChecks: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
+			},
+		},
+		{
+			Name: "space instead of %20 for python",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic " + validCredential,
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS uses 'Basic ', which is not read correctly by the python SDK. Value should have 'Authorization=Basic%20...'",
+			},
+		},
+		{
+			Name: "%20 instead of space for js",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20" + validCredential,
+			}),
+			Language: "js",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS uses 'Basic%20', which is not read correctly by the js SDK. Value should have 'Authorization=Basic ...'",
+			},
+		},
+		{
+			Name: "encoded twice",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%2520" + validCredential,
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is URL-encoded twice ('Basic%2520'). Value should have 'Authorization=Basic%20...'",
+			},
+		},
+		{
+			Name: "token pasted without encoding",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20123456:glc_abc",
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS has an invalid Authorization header: the credential is not base64 encoded. Encode '<instance-id>:<token>' with base64, e.g. with 'echo -n \"<instance-id>:<token>\" | base64 -w0'",
+			},
+		},
+		{
+			Name: "not base64",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20not*base64",
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS has an invalid Authorization header: the credential is not valid base64: illegal base64 data at input byte 3",
+			},
+		},
+		{
+			Name: "missing colon",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20" + base64.StdEncoding.EncodeToString([]byte("123456glc_abc")),
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS has an invalid Authorization header: the decoded credential must have the format '<instance-id>:<token>', but it has no ':'",
+			},
+		},
+		{
+			Name: "instance id not numeric",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20" + base64.StdEncoding.EncodeToString([]byte("username:password")),
+			}),
+			Language: "python",
+			ExpectedErrors: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS has an invalid Authorization header: the instance id 'username' of the decoded credential must be numeric",
+			},
+		},
+		{
+			Name: "token with unexpected shape",
+			EnvVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_HEADERS": "Authorization=Basic%20" + base64.StdEncoding.EncodeToString([]byte("123456:password")),
+			}),
+			Language: "python",
+			ExpectedChecks: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
+			},
+			ExpectedWarnings: []string{
+				"Grafana Cloud: The token in the Authorization header of OTEL_EXPORTER_OTLP_HEADERS does not look like a Grafana Cloud access policy token (glc_...)",
+			},
+		},
+		{
+			Name: "instance id matches GRAFANA_CLOUD_INSTANCE_ID",
+			EnvVars: correctWith(map[string]string{
+				"GRAFANA_CLOUD_INSTANCE_ID": "123456",
+			}),
+			Language: "python",
+			ExpectedChecks: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
+				"Grafana Cloud: GRAFANA_CLOUD_INSTANCE_ID matches the instance id in OTEL_EXPORTER_OTLP_HEADERS",
+			},
+		},
+		{
+			Name: "instance id does not match GRAFANA_CLOUD_INSTANCE_ID",
+			EnvVars: correctWith(map[string]string{
+				"GRAFANA_CLOUD_INSTANCE_ID": "654321",
+			}),
+			Language: "python",
+			ExpectedChecks: []string{
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
+			},
+			ExpectedErrors: []string{
+				"Grafana Cloud: GRAFANA_CLOUD_INSTANCE_ID (654321) does not match the instance id in OTEL_EXPORTER_OTLP_HEADERS (123456)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			utils.RunEnvVarComponentTest(t, tt, "Grafana Cloud",
+				func(reporter utils.Reporter, c *utils.ComponentReporter, language string, components []string) {
+					env.CheckEnvVar(language, OtelExporterOTLPHeaders, c)
+				})
+		})
+	}
+}
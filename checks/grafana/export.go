@@ -0,0 +1,92 @@
+package grafana
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/otlp"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+// checkExport sends a synthetic OTLP payload for each signal exported with OTLP, using the endpoint,
+// headers, protocol and compression the SDK would use, and interprets the response.
+// It returns the first endpoint that could not be reached, so it can be diagnosed.
+func checkExport(reporter *utils.ComponentReporter, clientFor func(otlp.Signal) (*http.Client, error)) string {
+	endpoint := env.GetValue(OtelExporterOTLPEndpoint)
+	if strings.Contains(endpoint, "localhost") {
+		reporter.AddWarning("Export test skipped, since OTEL_EXPORTER_OTLP_ENDPOINT is using localhost")
+		return ""
+	}
+
+	headers := env.GetValue(OtelExporterOTLPHeaders)
+	if endpoint == "" || headers == "" {
+		reporter.AddWarning("Export test skipped, since both environment variables OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_HEADERS need to be set for this check")
+		return ""
+	}
+
+	unreachable := ""
+	for _, signal := range otlp.Signals {
+		req, ok := otlp.FromEnv(signal)
+		if !ok {
+			continue
+		}
+		if req.Protocol == otlp.ProtocolGRPC {
+			reporter.AddWarning(fmt.Sprintf("Export test for %s skipped, since protocol grpc is not supported by the export test", signal))
+			continue
+		}
+		client, err := clientFor(signal)
+		if err != nil {
+			reporter.AddError(fmt.Sprintf("Export test for %s skipped, since the TLS options of the exporter are invalid: %s", signal, err))
+			continue
+		}
+		result, err := otlp.Export(client, req)
+		reportExport(reporter, req, result, err)
+		var urlErr *url.Error
+		if unreachable == "" && errors.As(err, &urlErr) {
+			unreachable = req.Endpoint
+		}
+	}
+	return unreachable
+}
+
+func reportExport(reporter *utils.ComponentReporter, req otlp.Request, result otlp.Result, err error) {
+	prefix := fmt.Sprintf("Export test for %s to %s", req.Signal, req.Endpoint)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("%s failed: %s", prefix, err))
+		return
+	}
+
+	detail := ""
+	if result.Message != "" {
+		detail = fmt.Sprintf(" (%s)", result.Message)
+	}
+	switch code := result.StatusCode; {
+	case code >= 200 && code < 300:
+		partial := result.PartialSuccess
+		switch {
+		case partial.Rejected > 0:
+			reporter.AddError(fmt.Sprintf("%s was partially rejected: %d item(s) rejected: %s", prefix, partial.Rejected, partial.ErrorMessage))
+		case partial.ErrorMessage != "":
+			reporter.AddWarning(fmt.Sprintf("%s succeeded with a warning: %s", prefix, partial.ErrorMessage))
+		default:
+			reporter.AddSuccessfulCheck(fmt.Sprintf("%s succeeded", prefix))
+		}
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		reporter.AddError(fmt.Sprintf("%s failed with %s%s: the credentials in OTEL_EXPORTER_OTLP_HEADERS are not accepted", prefix, result.Status, detail))
+	case code == http.StatusNotFound:
+		reporter.AddError(fmt.Sprintf("%s failed with %s%s: the path does not exist, check that OTEL_EXPORTER_OTLP_ENDPOINT ends with /otlp", prefix, result.Status, detail))
+	case code == http.StatusUnsupportedMediaType:
+		reporter.AddError(fmt.Sprintf("%s failed with %s%s: the endpoint does not accept %s, check OTEL_EXPORTER_OTLP_PROTOCOL", prefix, result.Status, detail, req.Protocol))
+	case code == http.StatusBadRequest:
+		reporter.AddError(fmt.Sprintf("%s failed with %s%s: the payload was rejected", prefix, result.Status, detail))
+	case code == http.StatusTooManyRequests || code == http.StatusBadGateway ||
+		code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout:
+		reporter.AddWarning(fmt.Sprintf("%s failed with %s%s: the error is temporary and the SDK retries the export", prefix, result.Status, detail))
+	default:
+		reporter.AddError(fmt.Sprintf("%s failed with %s%s", prefix, result.Status, detail))
+	}
+}
@@ -0,0 +1,165 @@
+package grafana
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/otlp"
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExport(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           int
+		response         string
+		envVars          map[string]string
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name:   "accepted",
+			status: http.StatusOK,
+			envVars: map[string]string{
+				"OTEL_LOGS_EXPORTER": "none",
+			},
+			expectedChecks: []string{
+				"Grafana Cloud: Export test for traces to URL/otlp/v1/traces succeeded",
+				"Grafana Cloud: Export test for metrics to URL/otlp/v1/metrics succeeded",
+			},
+		},
+		{
+			name:     "partially rejected",
+			status:   http.StatusOK,
+			response: `{"partialSuccess":{"rejectedSpans":"1","errorMessage":"span too old"}}`,
+			envVars: map[string]string{
+				"OTEL_METRICS_EXPORTER": "none",
+				"OTEL_LOGS_EXPORTER":    "none",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Export test for traces to URL/otlp/v1/traces was partially rejected: 1 item(s) rejected: span too old",
+			},
+		},
+		{
+			name:     "invalid credentials",
+			status:   http.StatusUnauthorized,
+			response: `{"code":16,"message":"authentication error: invalid token"}`,
+			envVars: map[string]string{
+				"OTEL_METRICS_EXPORTER": "none",
+				"OTEL_LOGS_EXPORTER":    "none",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Export test for traces to URL/otlp/v1/traces failed with 401 Unauthorized (authentication error: invalid token): the credentials in OTEL_EXPORTER_OTLP_HEADERS are not accepted",
+			},
+		},
+		{
+			name:   "wrong path",
+			status: http.StatusNotFound,
+			envVars: map[string]string{
+				"OTEL_METRICS_EXPORTER": "none",
+				"OTEL_LOGS_EXPORTER":    "none",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Export test for traces to URL/otlp/v1/traces failed with 404 Not Found: the path does not exist, check that OTEL_EXPORTER_OTLP_ENDPOINT ends with /otlp",
+			},
+		},
+		{
+			name:   "unsupported media type",
+			status: http.StatusUnsupportedMediaType,
+			envVars: map[string]string{
+				"OTEL_TRACES_EXPORTER": "none",
+				"OTEL_LOGS_EXPORTER":   "none",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Export test for metrics to URL/otlp/v1/metrics failed with 415 Unsupported Media Type: the endpoint does not accept http/protobuf, check OTEL_EXPORTER_OTLP_PROTOCOL",
+			},
+		},
+		{
+			name:   "temporary error",
+			status: http.StatusServiceUnavailable,
+			envVars: map[string]string{
+				"OTEL_TRACES_EXPORTER":  "none",
+				"OTEL_METRICS_EXPORTER": "none",
+			},
+			expectedWarnings: []string{
+				"Grafana Cloud: Export test for logs to URL/otlp/v1/logs failed with 503 Service Unavailable: the error is temporary and the SDK retries the export",
+			},
+		},
+		{
+			name:   "server error",
+			status: http.StatusInternalServerError,
+			envVars: map[string]string{
+				"OTEL_TRACES_EXPORTER":  "none",
+				"OTEL_METRICS_EXPORTER": "none",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Export test for logs to URL/otlp/v1/logs failed with 500 Internal Server Error",
+			},
+		},
+		{
+			name:   "grpc",
+			status: http.StatusOK,
+			envVars: map[string]string{
+				"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc",
+				"OTEL_METRICS_EXPORTER":       "none",
+				"OTEL_LOGS_EXPORTER":          "none",
+			},
+			expectedWarnings: []string{
+				"Grafana Cloud: Export test for traces skipped, since protocol grpc is not supported by the export test",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Basic "+validCredential, r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL+"/otlp")
+			t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Basic%20"+validCredential)
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+
+			reporter := utils.Reporter{}
+			component := reporter.Component("Grafana Cloud")
+			checkExport(component, func(otlp.Signal) (*http.Client, error) { return server.Client(), nil })
+
+			replace := func(messages []string) []string {
+				var res []string
+				for _, m := range messages {
+					res = append(res, strings.ReplaceAll(m, server.URL, "URL"))
+				}
+				return res
+			}
+			assert.ElementsMatch(t, tt.expectedErrors, replace(component.Errors), "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, replace(component.Warnings), "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, replace(component.Checks), "checks mismatch")
+		})
+	}
+}
+
+func TestCheckExportUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", server.URL+"/otlp")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Basic%20"+validCredential)
+	t.Setenv("OTEL_METRICS_EXPORTER", "none")
+	t.Setenv("OTEL_LOGS_EXPORTER", "none")
+
+	reporter := utils.Reporter{}
+	component := reporter.Component("Grafana Cloud")
+	unreachable := checkExport(component, func(otlp.Signal) (*http.Client, error) { return http.DefaultClient, nil })
+
+	assert.Equal(t, server.URL+"/otlp/v1/traces", unreachable)
+	assert.Len(t, component.Errors, 1)
+}
@@ -16,6 +16,7 @@ func TestCheckEnvVarsGrafana(t *testing.T) {
 			ExpectedChecks: []string{
 				"Grafana Cloud: OTEL_EXPORTER_OTLP_PROTOCOL is set to 'http/protobuf'",
 				"Grafana Cloud: OTEL_EXPORTER_OTLP_ENDPOINT set in the format similar to https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+				"Grafana Cloud: OTEL_EXPORTER_OTLP_ENDPOINT targets Grafana Cloud region prod-us-east-0",
 				"Grafana Cloud: OTEL_EXPORTER_OTLP_HEADERS is set correctly",
 			},
 		},
@@ -50,11 +51,14 @@ func TestCheckEnvVarsGrafana(t *testing.T) {
 	}
 }
 
+// validCredential is the base64 encoded "123456:glc_..." with a well-formed access policy token
+const validCredential = "MTIzNDU2OmdsY19leUp2SWpvaU1USXpJaXdpYmlJNkltOTBaV3d0WTJobFkydGxjaUlzSW1zaU9pSmhZbU5rWldabmFHbHFhMndpTENKdElqcDdJbklpT2lKd2NtOWtMWFZ6TFdWaGMzUXRNQ0o5ZlE9PQ=="
+
 func correctWith(add map[string]string) map[string]string {
 	m := map[string]string{
 		"OTEL_EXPORTER_OTLP_PROTOCOL": "http/protobuf",
 		"OTEL_EXPORTER_OTLP_ENDPOINT": "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
-		"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Basic%20dXNlcm5hbWU6cGFzc3dvcmQ=",
+		"OTEL_EXPORTER_OTLP_HEADERS":  "Authorization=Basic%20" + validCredential,
 	}
 	for k, v := range add {
 		m[k] = v
@@ -0,0 +1,248 @@
+package grafana
+
+import (
+	_ "embed"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/otel-checker/checks/collector"
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/utils"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed regions.yaml
+var regionsFile []byte
+
+var gatewayHostRegex = regexp.MustCompile(`^otlp-gateway-([a-z]+)-([a-z]+-[a-z]+-[0-9]+)\.grafana\.net$`)
+
+var (
+	GrafanaCloudZone = env.EnvVar{
+		Name:        "GRAFANA_CLOUD_ZONE",
+		Description: "Grafana Cloud zone used by Beyla, e.g. prod-eu-west-0",
+	}
+
+	GrafanaCloudOTLPEndpoint = env.EnvVar{
+		Name:        "GRAFANA_CLOUD_OTLP_ENDPOINT",
+		Description: "Grafana Cloud OTLP endpoint used by Beyla",
+	}
+)
+
+// Region is a Grafana Cloud region, e.g. environment "prod" and name "us-east-0"
+type Region struct {
+	Environment string
+	Name        string
+}
+
+func (r Region) String() string {
+	return r.Environment + "-" + r.Name
+}
+
+// endpointSource is a place where the Grafana Cloud region or stack is configured
+type endpointSource struct {
+	name       string
+	region     string
+	instanceID string
+}
+
+// knownRegions returns the known regions per environment
+func knownRegions() (map[string][]string, error) {
+	regions := map[string][]string{}
+	err := yaml.Unmarshal(regionsFile, &regions)
+	return regions, err
+}
+
+// ParseGatewayEndpoint returns the region of a Grafana Cloud OTLP gateway endpoint,
+// e.g. https://otlp-gateway-prod-us-east-0.grafana.net/otlp
+func ParseGatewayEndpoint(endpoint string) (Region, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return Region{}, fmt.Errorf("'%s' is not a valid URL: %w", endpoint, err)
+	}
+	if u.Scheme != "https" {
+		return Region{}, fmt.Errorf("'%s' must use https", endpoint)
+	}
+	match := gatewayHostRegex.FindStringSubmatch(u.Hostname())
+	if match == nil {
+		return Region{}, fmt.Errorf("host '%s' is not a Grafana Cloud OTLP gateway (otlp-gateway-<environment>-<region>.grafana.net)", u.Hostname())
+	}
+	if strings.TrimSuffix(u.Path, "/") != "/otlp" {
+		return Region{}, fmt.Errorf("path of '%s' must be /otlp", endpoint)
+	}
+	return Region{Environment: match[1], Name: match[2]}, nil
+}
+
+// ParseZone returns the region of a Grafana Cloud zone, e.g. prod-eu-west-0
+func ParseZone(zone string) (Region, error) {
+	environment, name, found := strings.Cut(zone, "-")
+	if !found {
+		return Region{}, fmt.Errorf("zone '%s' must have the format <environment>-<region>, e.g. prod-eu-west-0", zone)
+	}
+	return Region{Environment: environment, Name: name}, nil
+}
+
+// Known returns true if the region is in the embedded list of Grafana Cloud regions
+func (r Region) Known() bool {
+	regions, err := knownRegions()
+	return err == nil && slices.Contains(regions[r.Environment], r.Name)
+}
+
+// checkKnownRegion warns about a region that is not in the embedded list, which may be outdated when a new region is
+// launched
+func checkKnownRegion(reporter *utils.ComponentReporter, name string, region Region) {
+	if !region.Known() {
+		reporter.AddWarning(fmt.Sprintf("%s: region '%s' is not in otel-checker's list of Grafana Cloud regions. It may be a new region, otherwise check the endpoint in the Grafana Cloud portal", name, region))
+	}
+}
+
+// checkRegions checks that all configured endpoints target the same Grafana Cloud region and stack
+func checkRegions(reporter *utils.ComponentReporter, commands utils.Commands) {
+	sources := sdkEndpointSources(reporter)
+	sources = append(sources, beylaEndpointSources(reporter)...)
+	if slices.Contains(commands.Components, "collector") {
+		sources = append(sources, collectorEndpointSources(reporter, commands.CollectorConfigPath)...)
+	}
+	checkConsistency(reporter, sources)
+}
+
+func sdkEndpointSources(reporter *utils.ComponentReporter) []endpointSource {
+	instanceID := ""
+	if header, ok := authorizationHeader(env.GetValue(OtelExporterOTLPHeaders)); ok {
+		if auth, err := DecodeBasicAuth(strings.TrimPrefix(strings.TrimPrefix(header, "Basic%20"), "Basic ")); err == nil {
+			instanceID = auth.InstanceID
+		}
+	}
+	return endpointSources(reporter, instanceID)
+}
+
+func endpointSources(reporter *utils.ComponentReporter, instanceID string) []endpointSource {
+	var sources []endpointSource
+	for _, name := range []string{
+		"OTEL_EXPORTER_OTLP_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_METRICS_ENDPOINT",
+		"OTEL_EXPORTER_OTLP_LOGS_ENDPOINT",
+	} {
+		value := env.GetValue(env.EnvVar{Name: name})
+		if !strings.Contains(value, "grafana.net") {
+			continue
+		}
+		// per signal endpoints contain the full path, e.g. /otlp/v1/traces
+		base := value
+		if name != "OTEL_EXPORTER_OTLP_ENDPOINT" {
+			base = strings.TrimSuffix(strings.TrimSuffix(value, "/"), "/v1/"+signalOf(name))
+		}
+		region, err := ParseGatewayEndpoint(base)
+		if err != nil {
+			if name != "OTEL_EXPORTER_OTLP_ENDPOINT" {
+				// OTEL_EXPORTER_OTLP_ENDPOINT is already validated on its own
+				reporter.AddError(fmt.Sprintf("%s: %s", name, err))
+			}
+			continue
+		}
+		if name != "OTEL_EXPORTER_OTLP_ENDPOINT" {
+			checkKnownRegion(reporter, name, region)
+		}
+		sources = append(sources, endpointSource{name: name, region: region.String(), instanceID: instanceID})
+	}
+	return sources
+}
+
+func beylaEndpointSources(reporter *utils.ComponentReporter) []endpointSource {
+	var sources []endpointSource
+	instanceID := env.GetValue(GrafanaCloudInstanceID)
+	if zone := env.GetValue(GrafanaCloudZone); zone != "" {
+		region, err := ParseZone(zone)
+		if err != nil {
+			reporter.AddError(fmt.Sprintf("GRAFANA_CLOUD_ZONE: %s", err))
+		} else {
+			checkKnownRegion(reporter, "GRAFANA_CLOUD_ZONE", region)
+			sources = append(sources, endpointSource{name: "GRAFANA_CLOUD_ZONE", region: region.String(), instanceID: instanceID})
+		}
+	}
+	if endpoint := env.GetValue(GrafanaCloudOTLPEndpoint); endpoint != "" {
+		region, err := ParseGatewayEndpoint(endpoint)
+		if err != nil {
+			reporter.AddError(fmt.Sprintf("GRAFANA_CLOUD_OTLP_ENDPOINT: %s", err))
+		} else {
+			checkKnownRegion(reporter, "GRAFANA_CLOUD_OTLP_ENDPOINT", region)
+			sources = append(sources, endpointSource{name: "GRAFANA_CLOUD_OTLP_ENDPOINT", region: region.String(), instanceID: instanceID})
+		}
+	}
+	return sources
+}
+
+func collectorEndpointSources(reporter *utils.ComponentReporter, configPath string) []endpointSource {
+	targets, err := collector.ExporterTargets(configPath)
+	if err != nil {
+		// the collector component reports errors reading the config
+		return nil
+	}
+	var sources []endpointSource
+	for _, t := range targets {
+		if !strings.Contains(t.Endpoint, "grafana.net") {
+			continue
+		}
+		name := fmt.Sprintf("collector %s", t.Name)
+		region, err := ParseGatewayEndpoint(t.Endpoint)
+		if err != nil {
+			reporter.AddError(fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		checkKnownRegion(reporter, name, region)
+		instanceID := ""
+		if credential, ok := strings.CutPrefix(t.Authorization, "Basic "); ok {
+			if auth, err := DecodeBasicAuth(credential); err == nil {
+				instanceID = auth.InstanceID
+			}
+		}
+		sources = append(sources, endpointSource{name: name, region: region.String(), instanceID: instanceID})
+	}
+	return sources
+}
+
+func checkConsistency(reporter *utils.ComponentReporter, sources []endpointSource) {
+	regions := map[string][]string{}
+	stacks := map[string][]string{}
+	for _, s := range sources {
+		if s.region != "" {
+			regions[s.region] = append(regions[s.region], s.name)
+		}
+		if s.instanceID != "" {
+			stacks[s.instanceID] = append(stacks[s.instanceID], s.name)
+		}
+	}
+
+	if len(regions) > 1 {
+		reporter.AddError(fmt.Sprintf("Endpoints target different Grafana Cloud regions, so data ends up in more than one place: %s", describe(regions)))
+	} else if len(sources) > 1 && len(regions) == 1 {
+		for region := range regions {
+			reporter.AddSuccessfulCheck(fmt.Sprintf("All endpoints target Grafana Cloud region %s", region))
+		}
+	}
+
+	if len(stacks) > 1 {
+		reporter.AddError(fmt.Sprintf("Endpoints target different Grafana Cloud stacks, so data ends up in more than one place: %s", describe(stacks)))
+	}
+}
+
+// describe formats the sources per value, e.g. "prod-us-east-0 (OTEL_EXPORTER_OTLP_ENDPOINT), prod-eu-west-2 (GRAFANA_CLOUD_ZONE)"
+func describe(values map[string][]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s (%s)", k, strings.Join(values[k], ", ")))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func signalOf(envVarName string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(envVarName, "OTEL_EXPORTER_OTLP_"), "_ENDPOINT"))
+}
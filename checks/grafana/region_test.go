@@ -0,0 +1,158 @@
+package grafana
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGatewayEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint    string
+		want        string
+		unknown     bool
+		expectedErr string
+	}{
+		{
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			want:     "prod-us-east-0",
+		},
+		{
+			endpoint: "https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/",
+			want:     "prod-eu-west-2",
+		},
+		{
+			endpoint: "https://otlp-gateway-prod-mars-north-0.grafana.net/otlp",
+			want:     "prod-mars-north-0",
+			unknown:  true,
+		},
+		{
+			endpoint:    "https://example.grafana.net/otlp",
+			expectedErr: "host 'example.grafana.net' is not a Grafana Cloud OTLP gateway (otlp-gateway-<environment>-<region>.grafana.net)",
+		},
+		{
+			endpoint:    "https://otlp-gateway-prod-us-east-0.grafana.net.evil.com/otlp",
+			expectedErr: "host 'otlp-gateway-prod-us-east-0.grafana.net.evil.com' is not a Grafana Cloud OTLP gateway (otlp-gateway-<environment>-<region>.grafana.net)",
+		},
+		{
+			endpoint:    "https://otlp-gateway-prod-us-east-0.grafana.net/otlp/v1/traces",
+			expectedErr: "path of 'https://otlp-gateway-prod-us-east-0.grafana.net/otlp/v1/traces' must be /otlp",
+		},
+		{
+			endpoint:    "http://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			expectedErr: "'http://otlp-gateway-prod-us-east-0.grafana.net/otlp' must use https",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			region, err := ParseGatewayEndpoint(tt.endpoint)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, region.String())
+			assert.Equal(t, !tt.unknown, region.Known())
+		})
+	}
+}
+
+func TestCheckRegions(t *testing.T) {
+	otherStack := "Basic " + base64.StdEncoding.EncodeToString([]byte("654321:glc_abc"))
+	tests := []struct {
+		name             string
+		envVars          map[string]string
+		collectorConfig  string
+		expectedErrors   []string
+		expectedChecks   []string
+		expectedWarnings []string
+	}{
+		{
+			name:    "single endpoint",
+			envVars: correctWith(map[string]string{}),
+		},
+		{
+			name: "sdk and beyla in the same region",
+			envVars: correctWith(map[string]string{
+				"GRAFANA_CLOUD_ZONE":        "prod-us-east-0",
+				"GRAFANA_CLOUD_INSTANCE_ID": "123456",
+			}),
+			expectedChecks: []string{
+				"Grafana Cloud: All endpoints target Grafana Cloud region prod-us-east-0",
+			},
+		},
+		{
+			name: "per signal endpoint in another region",
+			envVars: correctWith(map[string]string{
+				"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "https://otlp-gateway-prod-eu-west-2.grafana.net/otlp/v1/traces",
+			}),
+			expectedErrors: []string{
+				"Grafana Cloud: Endpoints target different Grafana Cloud regions, so data ends up in more than one place: prod-eu-west-2 (OTEL_EXPORTER_OTLP_TRACES_ENDPOINT), prod-us-east-0 (OTEL_EXPORTER_OTLP_ENDPOINT)",
+			},
+		},
+		{
+			name: "unknown beyla zone",
+			envVars: correctWith(map[string]string{
+				"GRAFANA_CLOUD_ZONE": "prod-moon-0",
+			}),
+			expectedWarnings: []string{
+				"Grafana Cloud: GRAFANA_CLOUD_ZONE: region 'prod-moon-0' is not in otel-checker's list of Grafana Cloud regions. It may be a new region, otherwise check the endpoint in the Grafana Cloud portal",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Endpoints target different Grafana Cloud regions, so data ends up in more than one place: prod-moon-0 (GRAFANA_CLOUD_ZONE), prod-us-east-0 (OTEL_EXPORTER_OTLP_ENDPOINT)",
+			},
+		},
+		{
+			name:    "collector in another region and stack",
+			envVars: correctWith(map[string]string{}),
+			collectorConfig: `
+exporters:
+  otlphttp/grafana:
+    endpoint: https://otlp-gateway-prod-eu-west-2.grafana.net/otlp
+    headers:
+      Authorization: "` + otherStack + `"
+  otlphttp/unused:
+    endpoint: https://otlp-gateway-prod-us-west-0.grafana.net/otlp
+service:
+  pipelines:
+    traces/backend:
+      receivers: [otlp]
+      exporters: [otlphttp/grafana]
+`,
+			expectedErrors: []string{
+				"Grafana Cloud: Endpoints target different Grafana Cloud regions, so data ends up in more than one place: prod-eu-west-2 (collector exporter > otlphttp/grafana), prod-us-east-0 (OTEL_EXPORTER_OTLP_ENDPOINT)",
+				"Grafana Cloud: Endpoints target different Grafana Cloud stacks, so data ends up in more than one place: 123456 (OTEL_EXPORTER_OTLP_ENDPOINT), 654321 (collector exporter > otlphttp/grafana)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.envVars {
+				t.Setenv(k, v)
+			}
+			commands := utils.Commands{Components: []string{"grafana-cloud"}}
+			if tt.collectorConfig != "" {
+				dir := t.TempDir()
+				err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(tt.collectorConfig), 0644)
+				require.NoError(t, err)
+				commands.Components = append(commands.Components, "collector")
+				commands.CollectorConfigPath = dir + "/"
+			}
+
+			reporter := utils.Reporter{}
+			component := reporter.Component("Grafana Cloud")
+			checkRegions(component, commands)
+
+			assert.ElementsMatch(t, tt.expectedErrors, component.Errors, "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, component.Warnings, "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, component.Checks, "checks mismatch")
+		})
+	}
+}
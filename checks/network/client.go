@@ -0,0 +1,40 @@
+package network
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/env"
+)
+
+// NewClient returns an HTTP client with a timeout that connects through the proxy configured in the environment
+func NewClient(tlsConfig *tls.Config, timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		return proxyFor(req.URL, os.Getenv).URL, nil
+	}
+	return &http.Client{Transport: transport, Timeout: timeout}
+}
+
+// OTLPClient returns an HTTP client with the timeout, CA certificate, client certificate and proxy
+// the OTLP exporter of a signal would use, so probes see what the application experiences
+func OTLPClient(signal string) (*http.Client, error) {
+	tlsConfig, err := env.ExporterTLSFromEnv(signal).TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(tlsConfig, env.ExporterTimeout(signal)), nil
+}
+
+// OTLPDiagnostics returns Diagnostics that use the TLS options of the OTLP exporter
+func OTLPDiagnostics(signal string) *Diagnostics {
+	d := NewDiagnostics()
+	if tlsConfig, err := env.ExporterTLSFromEnv(signal).TLSConfig(); err == nil {
+		d.TLSConfig = tlsConfig
+	}
+	return d
+}
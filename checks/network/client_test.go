@@ -0,0 +1,84 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPClient(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	// the server certificate is used as CA and, with its key, as client certificate
+	dir := t.TempDir()
+	serverCert := server.TLS.Certificates[0]
+	certPath := filepath.Join(dir, "server.crt")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverCert.Certificate[0]}), 0600))
+	keyPath := filepath.Join(dir, "server.key")
+	keyDER, err := x509.MarshalPKCS8PrivateKey(serverCert.PrivateKey)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	tests := []struct {
+		name        string
+		env         map[string]string
+		expectedErr string
+	}{
+		{
+			name:        "untrusted CA",
+			expectedErr: "certificate signed by unknown authority",
+		},
+		{
+			name:        "missing client certificate",
+			env:         map[string]string{"OTEL_EXPORTER_OTLP_CERTIFICATE": certPath},
+			expectedErr: "certificate required",
+		},
+		{
+			name: "CA and client certificate",
+			env: map[string]string{
+				"OTEL_EXPORTER_OTLP_CERTIFICATE":               certPath,
+				"OTEL_EXPORTER_OTLP_TRACES_CLIENT_CERTIFICATE": certPath,
+				"OTEL_EXPORTER_OTLP_TRACES_CLIENT_KEY":         keyPath,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			client, err := OTLPClient("traces")
+			require.NoError(t, err)
+
+			resp, err := client.Get(server.URL)
+			if tt.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func TestOTLPClientInvalidKey(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_CLIENT_KEY", "/does/not/exist.key")
+	_, err := OTLPClient("")
+	assert.EqualError(t, err, "client certificate and client key must be set together")
+}
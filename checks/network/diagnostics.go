@@ -0,0 +1,287 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/utils"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	// round trips slower than this are reported
+	slowRoundTrip = time.Second
+)
+
+// Diagnostics checks step by step why an endpoint can or cannot be reached:
+// proxy settings, DNS resolution, TCP connect, TLS handshake and HTTP round trip
+type Diagnostics struct {
+	Resolver *net.Resolver
+	Dialer   *net.Dialer
+	// TLSConfig is used for the handshake, e.g. to trust a custom CA
+	TLSConfig *tls.Config
+	Getenv    func(string) string
+	Now       func() time.Time
+	Timeout   time.Duration
+}
+
+// NewDiagnostics returns Diagnostics using the system resolver, trust store and environment
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{
+		Resolver:  net.DefaultResolver,
+		Dialer:    &net.Dialer{},
+		TLSConfig: &tls.Config{},
+		Getenv:    os.Getenv,
+		Now:       time.Now,
+		Timeout:   defaultTimeout,
+	}
+}
+
+// Run reports the result of each step for the endpoint and stops at the first step that fails
+func (d *Diagnostics) Run(reporter *utils.ComponentReporter, endpoint string) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		reporter.AddError(fmt.Sprintf("Network: '%s' is not a valid URL", endpoint))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d.Timeout)
+	defer cancel()
+
+	target := net.JoinHostPort(u.Hostname(), portOf(u))
+	proxy := proxyFor(u, d.Getenv)
+	dialHost, dialPort := u.Hostname(), portOf(u)
+	switch {
+	case proxy.ExcludedBy != "":
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Proxy: NO_PROXY entry '%s' excludes %s from %s, connecting directly", proxy.ExcludedBy, u.Hostname(), proxy.Source))
+	case proxy.URL != nil:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Proxy: %s applies to %s, connecting through %s", proxy.Source, u.Hostname(), proxy.URL.Host))
+		dialHost, dialPort = proxy.URL.Hostname(), portOf(proxy.URL)
+	case proxy.Source != "":
+		reporter.AddError(fmt.Sprintf("Proxy: %s is not a valid URL", proxy.Source))
+		return
+	default:
+		reporter.AddSuccessfulCheck(fmt.Sprintf("Proxy: no proxy is configured for %s, connecting directly", u.Hostname()))
+	}
+
+	addr, ok := d.resolve(ctx, reporter, dialHost)
+	if !ok {
+		return
+	}
+
+	conn, ok := d.connect(ctx, reporter, net.JoinHostPort(addr, dialPort))
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	if proxy.URL != nil && !d.tunnel(reporter, conn, proxy.URL, target) {
+		return
+	}
+
+	if u.Scheme == "https" {
+		tlsConn, ok := d.handshake(ctx, reporter, conn, u.Hostname(), proxy.URL != nil)
+		if !ok {
+			return
+		}
+		conn = tlsConn
+	}
+
+	d.roundTrip(reporter, conn, u)
+}
+
+func (d *Diagnostics) resolve(ctx context.Context, reporter *utils.ComponentReporter, host string) (string, bool) {
+	if net.ParseIP(host) != nil {
+		reporter.AddSuccessfulCheck(fmt.Sprintf("DNS: %s is an IP address, no lookup needed", host))
+		return host, true
+	}
+	start := d.Now()
+	addrs, err := d.Resolver.LookupIPAddr(ctx, host)
+	elapsed := d.Now().Sub(start)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			reporter.AddError(fmt.Sprintf("DNS: %s does not exist, check the host name for typos", host))
+		} else {
+			reporter.AddError(fmt.Sprintf("DNS: could not resolve %s: %s", host, err))
+		}
+		return "", false
+	}
+	var ips []string
+	for _, a := range addrs {
+		ips = append(ips, a.IP.String())
+	}
+	reporter.AddSuccessfulCheck(fmt.Sprintf("DNS: %s resolves to %s in %dms", host, strings.Join(ips, ", "), elapsed.Milliseconds()))
+	return ips[0], true
+}
+
+func (d *Diagnostics) connect(ctx context.Context, reporter *utils.ComponentReporter, addr string) (net.Conn, bool) {
+	start := d.Now()
+	conn, err := d.Dialer.DialContext(ctx, "tcp", addr)
+	elapsed := d.Now().Sub(start)
+	if err != nil {
+		var netErr net.Error
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			reporter.AddError(fmt.Sprintf("TCP: connection to %s refused, nothing is listening on this port", addr))
+		case errors.As(err, &netErr) && netErr.Timeout():
+			reporter.AddError(fmt.Sprintf("TCP: connection to %s timed out, a firewall probably drops the traffic", addr))
+		default:
+			reporter.AddError(fmt.Sprintf("TCP: could not connect to %s: %s", addr, err))
+		}
+		return nil, false
+	}
+	reporter.AddSuccessfulCheck(fmt.Sprintf("TCP: connected to %s in %dms", addr, elapsed.Milliseconds()))
+	return conn, true
+}
+
+// tunnel asks the proxy to open a connection to the target with CONNECT
+func (d *Diagnostics) tunnel(reporter *utils.ComponentReporter, conn net.Conn, proxy *url.URL, target string) bool {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: http.Header{},
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	_ = conn.SetDeadline(d.Now().Add(d.Timeout))
+	defer conn.SetDeadline(time.Time{})
+	if err := req.Write(conn); err != nil {
+		reporter.AddError(fmt.Sprintf("Proxy: could not send CONNECT to %s: %s", proxy.Host, err))
+		return false
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Proxy: no valid response to CONNECT from %s: %s", proxy.Host, err))
+		return false
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		reporter.AddError(fmt.Sprintf("Proxy: %s refused CONNECT to %s with %s", proxy.Host, target, resp.Status))
+		return false
+	}
+	reporter.AddSuccessfulCheck(fmt.Sprintf("Proxy: %s opened a tunnel to %s", proxy.Host, target))
+	return true
+}
+
+// handshake performs the TLS handshake with SNI and verifies the certificate chain itself,
+// so the presented chain can be reported even if it is not trusted
+func (d *Diagnostics) handshake(ctx context.Context, reporter *utils.ComponentReporter, conn net.Conn, host string, viaProxy bool) (net.Conn, bool) {
+	config := &tls.Config{}
+	if d.TLSConfig != nil {
+		config = d.TLSConfig.Clone()
+	}
+	config.ServerName = host
+	config.InsecureSkipVerify = true
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		reporter.AddError(fmt.Sprintf("TLS: handshake with %s (SNI %s) failed: %s", conn.RemoteAddr(), host, err))
+		return nil, false
+	}
+
+	state := tlsConn.ConnectionState()
+	chain := describeChain(state.PeerCertificates)
+	leaf := state.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, c := range state.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := leaf.Verify(x509.VerifyOptions{
+		DNSName:       host,
+		Roots:         config.RootCAs,
+		Intermediates: intermediates,
+		CurrentTime:   d.Now(),
+	})
+	if err != nil {
+		var unknownAuthority x509.UnknownAuthorityError
+		var invalid x509.CertificateInvalidError
+		var hostname x509.HostnameError
+		switch {
+		case errors.As(err, &unknownAuthority):
+			interceptor := "a firewall or proxy probably intercepts TLS"
+			if viaProxy {
+				interceptor = "the proxy probably intercepts TLS"
+			}
+			reporter.AddError(fmt.Sprintf("TLS: certificate of %s is signed by an unknown authority, %s; trust its CA with OTEL_EXPORTER_OTLP_CERTIFICATE. Certificate chain: %s", host, interceptor, chain))
+		case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+			reporter.AddError(fmt.Sprintf("TLS: certificate of %s is expired or not yet valid (valid from %s to %s). Certificate chain: %s", host, leaf.NotBefore.Format(time.DateOnly), leaf.NotAfter.Format(time.DateOnly), chain))
+		case errors.As(err, &hostname):
+			reporter.AddError(fmt.Sprintf("TLS: certificate is not valid for %s, it is valid for %s. Certificate chain: %s", host, strings.Join(certificateNames(leaf), ", "), chain))
+		default:
+			reporter.AddError(fmt.Sprintf("TLS: certificate of %s is not valid: %s. Certificate chain: %s", host, err, chain))
+		}
+		tlsConn.Close()
+		return nil, false
+	}
+
+	reporter.AddSuccessfulCheck(fmt.Sprintf("TLS: %s handshake with SNI %s succeeded, certificate chain: %s", tls.VersionName(state.Version), host, chain))
+	if remaining := leaf.NotAfter.Sub(d.Now()); remaining < env.CertificateExpiryWarning {
+		reporter.AddWarning(fmt.Sprintf("TLS: certificate of %s expires on %s", host, leaf.NotAfter.Format(time.DateOnly)))
+	}
+	return tlsConn, true
+}
+
+// roundTrip sends a request over the established connection and measures the time until the response
+func (d *Diagnostics) roundTrip(reporter *utils.ComponentReporter, conn net.Conn, u *url.URL) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Latency: could not create request: %s", err))
+		return
+	}
+	req.Close = true
+	_ = conn.SetDeadline(d.Now().Add(d.Timeout))
+	start := d.Now()
+	if err := req.Write(conn); err != nil {
+		reporter.AddError(fmt.Sprintf("Latency: could not send request to %s: %s", u.Host, err))
+		return
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	elapsed := d.Now().Sub(start)
+	if err != nil {
+		reporter.AddError(fmt.Sprintf("Latency: no valid HTTP response from %s: %s", u.Host, err))
+		return
+	}
+	resp.Body.Close()
+	message := fmt.Sprintf("Latency: HTTP round trip to %s took %dms", u.Host, elapsed.Milliseconds())
+	if elapsed > slowRoundTrip {
+		reporter.AddWarning(message + ", exports may time out with a low OTEL_EXPORTER_OTLP_TIMEOUT")
+	} else {
+		reporter.AddSuccessfulCheck(message)
+	}
+}
+
+// describeChain returns the subject, issuer and expiry of each certificate
+func describeChain(certs []*x509.Certificate) string {
+	var parts []string
+	for _, c := range certs {
+		parts = append(parts, fmt.Sprintf("[subject '%s', issuer '%s', expires %s]", c.Subject, c.Issuer, c.NotAfter.Format(time.DateOnly)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func certificateNames(c *x509.Certificate) []string {
+	names := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		names = append(names, ip.String())
+	}
+	if len(names) == 0 && c.Subject.CommonName != "" {
+		names = append(names, c.Subject.CommonName)
+	}
+	return names
+}
@@ -0,0 +1,205 @@
+package network
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/otel-checker/checks/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var durationRegex = regexp.MustCompile(`[0-9]+ms`)
+
+// normalize replaces the parts of the messages that change between runs
+func normalize(messages []string, replacements ...string) []string {
+	var res []string
+	for _, m := range messages {
+		m = durationRegex.ReplaceAllString(m, "Xms")
+		m = strings.NewReplacer(replacements...).Replace(m)
+		res = append(res, m)
+	}
+	return res
+}
+
+// connectProxy is a forward proxy that tunnels CONNECT requests
+func connectProxy(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		dst, err := net.Dial("tcp", r.Host)
+		require.NoError(t, err)
+		src, buf, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		_, _ = src.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			_, _ = io.Copy(dst, buf)
+			dst.Close()
+		}()
+		_, _ = io.Copy(src, dst)
+		src.Close()
+	}))
+}
+
+func TestDiagnostics(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	trusted := x509.NewCertPool()
+	trusted.AddCert(server.Certificate())
+	serverAddr := server.Listener.Addr().String()
+	expiry := server.Certificate().NotAfter
+	chain := fmt.Sprintf("[subject 'O=Acme Co', issuer 'O=Acme Co', expires %s]", expiry.Format(time.DateOnly))
+
+	proxy := connectProxy(t)
+	defer proxy.Close()
+	proxyAddr := proxy.Listener.Addr().String()
+
+	refusingProxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+	}))
+	defer refusingProxy.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	tests := []struct {
+		name             string
+		endpoint         string
+		rootCAs          *x509.CertPool
+		env              map[string]string
+		now              time.Time
+		expectedErrors   []string
+		expectedWarnings []string
+		expectedChecks   []string
+	}{
+		{
+			name:     "direct connection",
+			endpoint: server.URL + "/otlp",
+			rootCAs:  trusted,
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: no proxy is configured for 127.0.0.1, connecting directly",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to SERVER in Xms",
+				"Grafana Cloud: TLS: TLS 1.3 handshake with SNI 127.0.0.1 succeeded, certificate chain: " + chain,
+				"Grafana Cloud: Latency: HTTP round trip to SERVER took Xms",
+			},
+		},
+		{
+			name:     "intercepted by proxy",
+			endpoint: server.URL + "/otlp",
+			env:      map[string]string{"HTTPS_PROXY": proxy.URL},
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: HTTPS_PROXY applies to 127.0.0.1, connecting through PROXY",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to PROXY in Xms",
+				"Grafana Cloud: Proxy: PROXY opened a tunnel to SERVER",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: TLS: certificate of 127.0.0.1 is signed by an unknown authority, the proxy probably intercepts TLS; trust its CA with OTEL_EXPORTER_OTLP_CERTIFICATE. Certificate chain: " + chain,
+			},
+		},
+		{
+			name:     "excluded from proxy",
+			endpoint: server.URL + "/otlp",
+			rootCAs:  trusted,
+			env:      map[string]string{"HTTPS_PROXY": refusingProxy.URL, "NO_PROXY": "127.0.0.1"},
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: NO_PROXY entry '127.0.0.1' excludes 127.0.0.1 from HTTPS_PROXY, connecting directly",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to SERVER in Xms",
+				"Grafana Cloud: TLS: TLS 1.3 handshake with SNI 127.0.0.1 succeeded, certificate chain: " + chain,
+				"Grafana Cloud: Latency: HTTP round trip to SERVER took Xms",
+			},
+		},
+		{
+			name:     "proxy refuses",
+			endpoint: server.URL + "/otlp",
+			env:      map[string]string{"HTTPS_PROXY": refusingProxy.URL},
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: HTTPS_PROXY applies to 127.0.0.1, connecting through REFUSING",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to REFUSING in Xms",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: Proxy: REFUSING refused CONNECT to SERVER with 407 Proxy Authentication Required",
+			},
+		},
+		{
+			name:     "certificate expires soon",
+			endpoint: server.URL + "/otlp",
+			rootCAs:  trusted,
+			now:      expiry.Add(-24 * time.Hour),
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: no proxy is configured for 127.0.0.1, connecting directly",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to SERVER in Xms",
+				"Grafana Cloud: TLS: TLS 1.3 handshake with SNI 127.0.0.1 succeeded, certificate chain: " + chain,
+				"Grafana Cloud: Latency: HTTP round trip to SERVER took Xms",
+			},
+			expectedWarnings: []string{
+				"Grafana Cloud: TLS: certificate of 127.0.0.1 expires on " + expiry.Format(time.DateOnly),
+			},
+		},
+		{
+			name:     "certificate expired",
+			endpoint: server.URL + "/otlp",
+			rootCAs:  trusted,
+			now:      expiry.Add(24 * time.Hour),
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: no proxy is configured for 127.0.0.1, connecting directly",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+				"Grafana Cloud: TCP: connected to SERVER in Xms",
+			},
+			expectedErrors: []string{
+				fmt.Sprintf("Grafana Cloud: TLS: certificate of 127.0.0.1 is expired or not yet valid (valid from %s to %s). Certificate chain: %s",
+					server.Certificate().NotBefore.Format(time.DateOnly), expiry.Format(time.DateOnly), chain),
+			},
+		},
+		{
+			name:     "connection refused",
+			endpoint: "https://" + closedAddr + "/otlp",
+			expectedChecks: []string{
+				"Grafana Cloud: Proxy: no proxy is configured for 127.0.0.1, connecting directly",
+				"Grafana Cloud: DNS: 127.0.0.1 is an IP address, no lookup needed",
+			},
+			expectedErrors: []string{
+				"Grafana Cloud: TCP: connection to CLOSED refused, nothing is listening on this port",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDiagnostics()
+			d.TLSConfig = &tls.Config{RootCAs: tt.rootCAs}
+			d.Getenv = func(k string) string { return tt.env[k] }
+			if !tt.now.IsZero() {
+				d.Now = func() time.Time { return tt.now }
+			}
+
+			reporter := utils.Reporter{}
+			component := reporter.Component("Grafana Cloud")
+			d.Run(component, tt.endpoint)
+
+			replacements := []string{serverAddr, "SERVER", proxyAddr, "PROXY", refusingProxy.Listener.Addr().String(), "REFUSING", closedAddr, "CLOSED"}
+			assert.ElementsMatch(t, tt.expectedErrors, normalize(component.Errors, replacements...), "errors mismatch")
+			assert.ElementsMatch(t, tt.expectedWarnings, normalize(component.Warnings, replacements...), "warnings mismatch")
+			assert.ElementsMatch(t, tt.expectedChecks, normalize(component.Checks, replacements...), "checks mismatch")
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package network
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// proxySetting is the proxy that applies to a URL according to the environment
+type proxySetting struct {
+	// URL is the proxy to connect through, nil if the connection is direct
+	URL *url.URL
+	// Source is the environment variable the proxy is configured with, e.g. HTTPS_PROXY
+	Source string
+	// ExcludedBy is the NO_PROXY entry that excludes the host from the proxy
+	ExcludedBy string
+}
+
+// proxyFor resolves the proxy for a URL the way Go's http.ProxyFromEnvironment does, but also
+// reports which variable or NO_PROXY entry is responsible
+func proxyFor(u *url.URL, getenv func(string) string) proxySetting {
+	keys := []string{"HTTP_PROXY", "http_proxy"}
+	if u.Scheme == "https" {
+		keys = []string{"HTTPS_PROXY", "https_proxy"}
+	}
+	var setting proxySetting
+	for _, k := range keys {
+		if value := getenv(k); value != "" {
+			if !strings.Contains(value, "://") {
+				value = "http://" + value
+			}
+			proxy, err := url.Parse(value)
+			if err != nil {
+				return proxySetting{Source: k}
+			}
+			setting = proxySetting{URL: proxy, Source: k}
+			break
+		}
+	}
+	if setting.URL == nil {
+		return setting
+	}
+
+	noProxy := getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = getenv("no_proxy")
+	}
+	if entry := matchNoProxy(u, noProxy); entry != "" {
+		return proxySetting{Source: setting.Source, ExcludedBy: entry}
+	}
+	return setting
+}
+
+// matchNoProxy returns the entry of a NO_PROXY list that matches the host of the URL
+func matchNoProxy(u *url.URL, noProxy string) string {
+	host := strings.ToLower(u.Hostname())
+	port := portOf(u)
+	ip := net.ParseIP(host)
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return entry
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return entry
+			}
+			continue
+		}
+		name := entry
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			name = h
+		}
+		if entryIP := net.ParseIP(name); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return entry
+			}
+			continue
+		}
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "*"), ".")
+		if host == name || strings.HasSuffix(host, "."+name) {
+			return entry
+		}
+	}
+	return ""
+}
+
+// portOf returns the port of a URL, or the default port of its scheme
+func portOf(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "http" {
+		return "80"
+	}
+	return "443"
+}
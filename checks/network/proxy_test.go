@@ -0,0 +1,96 @@
+package network
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		env      map[string]string
+		expected string
+		source   string
+		excluded string
+	}{
+		{
+			name:     "no proxy",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+		},
+		{
+			name:     "https proxy",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			env:      map[string]string{"HTTPS_PROXY": "proxy.corp:3128"},
+			expected: "http://proxy.corp:3128",
+			source:   "HTTPS_PROXY",
+		},
+		{
+			name:     "lower case https proxy",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			env:      map[string]string{"https_proxy": "http://proxy.corp:3128"},
+			expected: "http://proxy.corp:3128",
+			source:   "https_proxy",
+		},
+		{
+			name:     "http proxy does not apply to https",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			env:      map[string]string{"HTTP_PROXY": "http://proxy.corp:3128"},
+		},
+		{
+			name:     "excluded by domain",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "NO_PROXY": "localhost,.grafana.net"},
+			source:   "HTTPS_PROXY",
+			excluded: ".grafana.net",
+		},
+		{
+			name:     "excluded by wildcard",
+			endpoint: "https://otlp-gateway-prod-us-east-0.grafana.net/otlp",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "no_proxy": "*"},
+			source:   "HTTPS_PROXY",
+			excluded: "*",
+		},
+		{
+			name:     "excluded by CIDR",
+			endpoint: "http://10.0.0.5:4318",
+			env:      map[string]string{"HTTP_PROXY": "http://proxy.corp:3128", "NO_PROXY": "10.0.0.0/8"},
+			source:   "HTTP_PROXY",
+			excluded: "10.0.0.0/8",
+		},
+		{
+			name:     "NO_PROXY for other port",
+			endpoint: "https://collector.internal:4318",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "NO_PROXY": "collector.internal:443"},
+			expected: "http://proxy.corp:3128",
+			source:   "HTTPS_PROXY",
+		},
+		{
+			name:     "NO_PROXY does not match partial domain",
+			endpoint: "https://notgrafana.net/otlp",
+			env:      map[string]string{"HTTPS_PROXY": "http://proxy.corp:3128", "NO_PROXY": "grafana.net"},
+			expected: "http://proxy.corp:3128",
+			source:   "HTTPS_PROXY",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.Parse(tt.endpoint)
+			require.NoError(t, err)
+			setting := proxyFor(u, func(k string) string { return tt.env[k] })
+
+			if tt.expected == "" {
+				assert.Nil(t, setting.URL)
+			} else {
+				require.NotNil(t, setting.URL)
+				assert.Equal(t, tt.expected, setting.URL.String())
+			}
+			assert.Equal(t, tt.source, setting.Source)
+			assert.Equal(t, tt.excluded, setting.ExcludedBy)
+		})
+	}
+}
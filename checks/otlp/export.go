@@ -0,0 +1,246 @@
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxResponseSize limits how much of a response body is read
+const maxResponseSize = 64 * 1024
+
+// Request is the resolved OTLP exporter configuration of a signal
+type Request struct {
+	Signal      Signal
+	Endpoint    string
+	Protocol    string
+	Headers     map[string]string
+	Compression string
+}
+
+// PartialSuccess is the partial_success field of an export response
+type PartialSuccess struct {
+	Rejected     int64
+	ErrorMessage string
+}
+
+// Result is the outcome of an export request
+type Result struct {
+	StatusCode     int
+	Status         string
+	PartialSuccess PartialSuccess
+	// Message is the error message of a failed request, if the server sent one
+	Message string
+}
+
+// FromEnv resolves the exporter configuration of a signal the way the SDKs do: signal specific
+// environment variables take precedence, and the base endpoint gets the /v1/<signal> path appended.
+// It returns false if the signal is not exported with OTLP.
+func FromEnv(signal Signal) (Request, bool) {
+	exporter := os.Getenv(fmt.Sprintf("OTEL_%s_EXPORTER", strings.ToUpper(string(signal))))
+	if exporter != "" && !strings.Contains(exporter, "otlp") {
+		return Request{}, false
+	}
+
+	suffix := strings.ToUpper(string(signal))
+	req := Request{Signal: signal, Headers: map[string]string{}}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_" + suffix + "_ENDPOINT"); endpoint != "" {
+		req.Endpoint = endpoint
+	} else if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		req.Endpoint = strings.TrimSuffix(endpoint, "/") + "/v1/" + string(signal)
+	}
+	req.Protocol = signalValue("PROTOCOL", suffix)
+	if req.Protocol == "" {
+		req.Protocol = ProtocolProtobuf
+	}
+	req.Compression = signalValue("COMPRESSION", suffix)
+	for k, v := range ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")) {
+		req.Headers[k] = v
+	}
+	for k, v := range ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_" + suffix + "_HEADERS")) {
+		req.Headers[k] = v
+	}
+	return req, true
+}
+
+func signalValue(option string, signal string) string {
+	if value := os.Getenv("OTEL_EXPORTER_OTLP_" + signal + "_" + option); value != "" {
+		return value
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_" + option)
+}
+
+// ParseHeaders parses a header list in the format "key1=value1,key2=value2" with URL-encoded values
+func ParseHeaders(headers string) map[string]string {
+	res := map[string]string{}
+	for _, h := range strings.Split(headers, ",") {
+		key, value, found := strings.Cut(h, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			continue
+		}
+		// PathUnescape keeps '+', which is part of base64 credentials
+		if decoded, err := url.PathUnescape(value); err == nil {
+			value = decoded
+		}
+		res[key] = strings.TrimSpace(value)
+	}
+	return res
+}
+
+// Export sends a synthetic export request and returns the response status and partial success
+func Export(client *http.Client, req Request) (Result, error) {
+	body, contentType, err := Payload(req.Signal, req.Protocol, time.Now())
+	if err != nil {
+		return Result{}, err
+	}
+
+	encoding := ""
+	switch req.Compression {
+	case "", "none":
+	case "gzip":
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return Result{}, err
+		}
+		if err := w.Close(); err != nil {
+			return Result{}, err
+		}
+		body = buf.Bytes()
+		encoding = "gzip"
+	default:
+		return Result{}, fmt.Errorf("compression '%s' is not supported, use gzip or none", req.Compression)
+	}
+
+	httpReq, err := http.NewRequest(http.MethodPost, req.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpReq.Header.Set("Content-Type", contentType)
+	if encoding != "" {
+		httpReq.Header.Set("Content-Encoding", encoding)
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return Result{}, fmt.Errorf("could not read response: %w", err)
+	}
+
+	result := Result{StatusCode: resp.StatusCode, Status: resp.Status}
+	respType := resp.Header.Get("Content-Type")
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		result.PartialSuccess, err = ParsePartialSuccess(respType, respBody)
+		if err != nil {
+			return result, fmt.Errorf("could not parse response: %w", err)
+		}
+	} else {
+		result.Message = ParseStatusMessage(respType, respBody)
+	}
+	return result, nil
+}
+
+// ParsePartialSuccess returns the partial_success field of an export response. The field has the
+// same layout for all signals: the number of rejected items is field 1 and the error message field 2.
+func ParsePartialSuccess(contentType string, body []byte) (PartialSuccess, error) {
+	if len(body) == 0 {
+		return PartialSuccess{}, nil
+	}
+	if strings.HasPrefix(contentType, contentTypeJSON) {
+		return parseJSONPartialSuccess(body)
+	}
+
+	fields, err := parseFields(body)
+	if err != nil {
+		return PartialSuccess{}, err
+	}
+	var res PartialSuccess
+	for _, f := range fields {
+		if f.number != 1 || f.wireType != wireBytes {
+			continue
+		}
+		inner, err := parseFields(f.data)
+		if err != nil {
+			return PartialSuccess{}, err
+		}
+		for _, i := range inner {
+			switch {
+			case i.number == 1 && i.wireType == wireVarint:
+				res.Rejected = int64(i.value)
+			case i.number == 2 && i.wireType == wireBytes:
+				res.ErrorMessage = string(i.data)
+			}
+		}
+	}
+	return res, nil
+}
+
+func parseJSONPartialSuccess(body []byte) (PartialSuccess, error) {
+	var response map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return PartialSuccess{}, err
+	}
+	partial, ok := response["partialSuccess"]
+	if !ok {
+		partial = response["partial_success"]
+	}
+
+	var res PartialSuccess
+	for k, v := range partial {
+		switch {
+		case strings.HasPrefix(k, "rejected"):
+			// int64 values are encoded as strings in OTLP/JSON, but numbers are accepted too
+			n, err := strconv.ParseInt(string(bytes.Trim(v, `"`)), 10, 64)
+			if err != nil {
+				return PartialSuccess{}, fmt.Errorf("invalid %s: %w", k, err)
+			}
+			res.Rejected = n
+		case k == "errorMessage" || k == "error_message":
+			if err := json.Unmarshal(v, &res.ErrorMessage); err != nil {
+				return PartialSuccess{}, fmt.Errorf("invalid %s: %w", k, err)
+			}
+		}
+	}
+	return res, nil
+}
+
+// ParseStatusMessage returns the message of the google.rpc.Status body of a failed export request,
+// or the body itself if it is not a status
+func ParseStatusMessage(contentType string, body []byte) string {
+	if strings.HasPrefix(contentType, contentTypeProtobuf) {
+		if fields, err := parseFields(body); err == nil {
+			for _, f := range fields {
+				if f.number == 2 && f.wireType == wireBytes {
+					return string(f.data)
+				}
+			}
+		}
+		return ""
+	}
+	if strings.HasPrefix(contentType, contentTypeJSON) {
+		var status struct {
+			Message string `json:"message"`
+		}
+		if err := json.Unmarshal(body, &status); err == nil && status.Message != "" {
+			return status.Message
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,175 @@
+package otlp
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectorStandIn is a minimal OTLP/HTTP receiver that validates the payload and answers with the given response
+func collectorStandIn(t *testing.T, status int, contentType string, response []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			require.NoError(t, err)
+			body = gz
+		}
+		data, err := io.ReadAll(body)
+		require.NoError(t, err)
+
+		switch r.Header.Get("Content-Type") {
+		case contentTypeProtobuf:
+			assertProtobufPayload(t, data)
+		case contentTypeJSON:
+			var payload map[string]any
+			require.NoError(t, json.Unmarshal(data, &payload))
+			assert.Len(t, payload, 1)
+		default:
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		_, _ = w.Write(response)
+	}))
+}
+
+// assertProtobufPayload checks that the payload has one resource with one scope and one item
+func assertProtobufPayload(t *testing.T, data []byte) {
+	fields, err := parseFields(data)
+	require.NoError(t, err)
+	require.Len(t, fields, 1)
+	resource, err := parseFields(fields[0].data)
+	require.NoError(t, err)
+	require.Len(t, resource, 2)
+	scope, err := parseFields(resource[1].data)
+	require.NoError(t, err)
+	require.Len(t, scope, 2)
+	item, err := parseFields(scope[1].data)
+	require.NoError(t, err)
+	assert.NotEmpty(t, item)
+}
+
+func TestExport(t *testing.T) {
+	partialProtobuf := appendBytesField(nil, 1, appendStringField(appendVarintField(nil, 1, 2), 2, "dropped"))
+
+	tests := []struct {
+		name         string
+		signal       Signal
+		protocol     string
+		compression  string
+		status       int
+		contentType  string
+		response     []byte
+		expected     Result
+		expectedPath string
+	}{
+		{
+			name:        "protobuf with gzip",
+			signal:      Traces,
+			protocol:    ProtocolProtobuf,
+			compression: "gzip",
+			status:      http.StatusOK,
+			contentType: contentTypeProtobuf,
+			expected:    Result{StatusCode: 200, Status: "200 OK"},
+		},
+		{
+			name:        "protobuf partial success",
+			signal:      Logs,
+			protocol:    ProtocolProtobuf,
+			status:      http.StatusOK,
+			contentType: contentTypeProtobuf,
+			response:    partialProtobuf,
+			expected:    Result{StatusCode: 200, Status: "200 OK", PartialSuccess: PartialSuccess{Rejected: 2, ErrorMessage: "dropped"}},
+		},
+		{
+			name:        "json partial success",
+			signal:      Metrics,
+			protocol:    ProtocolJSON,
+			status:      http.StatusOK,
+			contentType: contentTypeJSON,
+			response:    []byte(`{"partialSuccess":{"rejectedDataPoints":"3","errorMessage":"too old"}}`),
+			expected:    Result{StatusCode: 200, Status: "200 OK", PartialSuccess: PartialSuccess{Rejected: 3, ErrorMessage: "too old"}},
+		},
+		{
+			name:        "json error status",
+			signal:      Traces,
+			protocol:    ProtocolJSON,
+			status:      http.StatusBadRequest,
+			contentType: contentTypeJSON,
+			response:    []byte(`{"code":3,"message":"invalid span"}`),
+			expected:    Result{StatusCode: 400, Status: "400 Bad Request", Message: "invalid span"},
+		},
+		{
+			name:        "protobuf error status",
+			signal:      Metrics,
+			protocol:    ProtocolProtobuf,
+			status:      http.StatusUnauthorized,
+			contentType: contentTypeProtobuf,
+			response:    appendStringField(appendVarintField(nil, 1, 16), 2, "invalid token"),
+			expected:    Result{StatusCode: 401, Status: "401 Unauthorized", Message: "invalid token"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := collectorStandIn(t, tt.status, tt.contentType, tt.response)
+			defer server.Close()
+
+			result, err := Export(server.Client(), Request{
+				Signal:      tt.signal,
+				Endpoint:    server.URL + "/v1/" + string(tt.signal),
+				Protocol:    tt.protocol,
+				Compression: tt.compression,
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestPayloadUnsupportedProtocol(t *testing.T) {
+	_, _, err := Payload(Traces, ProtocolGRPC, time.Now())
+	assert.EqualError(t, err, "protocol 'grpc' is not supported, use http/protobuf or http/json")
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "https://example.com/otlp/")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "Authorization=Basic%20YWJj+ZA==,X-Scope=a")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_ENDPOINT", "https://logs.example.com/v1/logs")
+	t.Setenv("OTEL_EXPORTER_OTLP_LOGS_HEADERS", "X-Scope=b")
+	t.Setenv("OTEL_EXPORTER_OTLP_COMPRESSION", "gzip")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_PROTOCOL", "http/json")
+	t.Setenv("OTEL_TRACES_EXPORTER", "console")
+
+	_, ok := FromEnv(Traces)
+	assert.False(t, ok)
+
+	metrics, ok := FromEnv(Metrics)
+	require.True(t, ok)
+	assert.Equal(t, Request{
+		Signal:      Metrics,
+		Endpoint:    "https://example.com/otlp/v1/metrics",
+		Protocol:    ProtocolJSON,
+		Compression: "gzip",
+		Headers:     map[string]string{"Authorization": "Basic YWJj+ZA==", "X-Scope": "a"},
+	}, metrics)
+
+	logs, ok := FromEnv(Logs)
+	require.True(t, ok)
+	assert.Equal(t, Request{
+		Signal:      Logs,
+		Endpoint:    "https://logs.example.com/v1/logs",
+		Protocol:    ProtocolProtobuf,
+		Compression: "gzip",
+		Headers:     map[string]string{"Authorization": "Basic YWJj+ZA==", "X-Scope": "b"},
+	}, logs)
+}
@@ -0,0 +1,165 @@
+package otlp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Signal is an OpenTelemetry signal that can be exported with OTLP
+type Signal string
+
+const (
+	Traces  Signal = "traces"
+	Metrics Signal = "metrics"
+	Logs    Signal = "logs"
+)
+
+// Signals are all signals in the order they are checked
+var Signals = []Signal{Traces, Metrics, Logs}
+
+const (
+	ProtocolProtobuf = "http/protobuf"
+	ProtocolJSON     = "http/json"
+	ProtocolGRPC     = "grpc"
+)
+
+const (
+	contentTypeProtobuf = "application/x-protobuf"
+	contentTypeJSON     = "application/json"
+)
+
+// the synthetic telemetry is sent as service "otel-checker", so it can be told apart from the application's data
+const (
+	serviceName  = "otel-checker"
+	testName     = "otel-checker export test"
+	metricName   = "otel_checker.export_test"
+	severityInfo = 9
+)
+
+// Payload returns a minimal valid export request for the signal, encoded for the protocol,
+// and the content type to send it with
+func Payload(signal Signal, protocol string, now time.Time) ([]byte, string, error) {
+	switch protocol {
+	case ProtocolProtobuf:
+		body, err := protobufPayload(signal, now)
+		return body, contentTypeProtobuf, err
+	case ProtocolJSON:
+		body, err := jsonPayload(signal, now)
+		return body, contentTypeJSON, err
+	default:
+		return nil, "", fmt.Errorf("protocol '%s' is not supported, use %s or %s", protocol, ProtocolProtobuf, ProtocolJSON)
+	}
+}
+
+func protobufPayload(signal Signal, now time.Time) ([]byte, error) {
+	attribute := appendStringField(nil, 1, "service.name")
+	attribute = appendBytesField(attribute, 2, appendStringField(nil, 1, serviceName))
+	resource := appendBytesField(nil, 1, attribute)
+	scope := appendStringField(nil, 1, serviceName)
+	timestamp := uint64(now.UnixNano())
+
+	var item []byte
+	switch signal {
+	case Traces:
+		traceID, spanID, err := newIDs()
+		if err != nil {
+			return nil, err
+		}
+		item = appendBytesField(item, 1, traceID)
+		item = appendBytesField(item, 2, spanID)
+		item = appendStringField(item, 5, testName)
+		item = appendVarintField(item, 6, 1) // SPAN_KIND_INTERNAL
+		item = appendFixed64Field(item, 7, timestamp-uint64(time.Millisecond))
+		item = appendFixed64Field(item, 8, timestamp)
+	case Metrics:
+		dataPoint := appendFixed64Field(nil, 3, timestamp)
+		dataPoint = appendFixed64Field(dataPoint, 6, 1) // as_int
+		gauge := appendBytesField(nil, 1, dataPoint)
+		item = appendStringField(item, 1, metricName)
+		item = appendStringField(item, 3, "1")
+		item = appendBytesField(item, 5, gauge)
+	case Logs:
+		item = appendFixed64Field(item, 1, timestamp)
+		item = appendVarintField(item, 2, severityInfo)
+		item = appendStringField(item, 3, "INFO")
+		item = appendBytesField(item, 5, appendStringField(nil, 1, testName))
+		item = appendFixed64Field(item, 11, timestamp)
+	default:
+		return nil, fmt.Errorf("unknown signal '%s'", signal)
+	}
+
+	scoped := appendBytesField(nil, 1, scope)
+	scoped = appendBytesField(scoped, 2, item)
+	resourceData := appendBytesField(nil, 1, resource)
+	resourceData = appendBytesField(resourceData, 2, scoped)
+	return appendBytesField(nil, 1, resourceData), nil
+}
+
+func jsonPayload(signal Signal, now time.Time) ([]byte, error) {
+	resource := map[string]any{
+		"attributes": []any{
+			map[string]any{"key": "service.name", "value": map[string]any{"stringValue": serviceName}},
+		},
+	}
+	scope := map[string]any{"name": serviceName}
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+
+	var request map[string]any
+	switch signal {
+	case Traces:
+		traceID, spanID, err := newIDs()
+		if err != nil {
+			return nil, err
+		}
+		span := map[string]any{
+			"traceId":           hex.EncodeToString(traceID),
+			"spanId":            hex.EncodeToString(spanID),
+			"name":              testName,
+			"kind":              1,
+			"startTimeUnixNano": strconv.FormatInt(now.Add(-time.Millisecond).UnixNano(), 10),
+			"endTimeUnixNano":   timestamp,
+		}
+		request = map[string]any{"resourceSpans": []any{map[string]any{
+			"resource":   resource,
+			"scopeSpans": []any{map[string]any{"scope": scope, "spans": []any{span}}},
+		}}}
+	case Metrics:
+		metric := map[string]any{
+			"name":  metricName,
+			"unit":  "1",
+			"gauge": map[string]any{"dataPoints": []any{map[string]any{"timeUnixNano": timestamp, "asInt": "1"}}},
+		}
+		request = map[string]any{"resourceMetrics": []any{map[string]any{
+			"resource":     resource,
+			"scopeMetrics": []any{map[string]any{"scope": scope, "metrics": []any{metric}}},
+		}}}
+	case Logs:
+		record := map[string]any{
+			"timeUnixNano":         timestamp,
+			"observedTimeUnixNano": timestamp,
+			"severityNumber":       severityInfo,
+			"severityText":         "INFO",
+			"body":                 map[string]any{"stringValue": testName},
+		}
+		request = map[string]any{"resourceLogs": []any{map[string]any{
+			"resource":  resource,
+			"scopeLogs": []any{map[string]any{"scope": scope, "logRecords": []any{record}}},
+		}}}
+	default:
+		return nil, fmt.Errorf("unknown signal '%s'", signal)
+	}
+	return json.Marshal(request)
+}
+
+// newIDs returns a random trace id and span id
+func newIDs() ([]byte, []byte, error) {
+	ids := make([]byte, 24)
+	if _, err := rand.Read(ids); err != nil {
+		return nil, nil, fmt.Errorf("could not generate trace id: %w", err)
+	}
+	return ids[:16], ids[16:], nil
+}
@@ -3,6 +3,7 @@ package js
 import (
 	"fmt"
 	"github.com/grafana/otel-checker/checks/env"
+	"github.com/grafana/otel-checker/checks/target"
 	"github.com/grafana/otel-checker/checks/utils"
 	"os"
 	"os/exec"
@@ -14,7 +15,7 @@ func CheckJSSetup(reporter *utils.ComponentReporter, commands utils.Commands) {
 	checkResourceDetectors(reporter)
 	checkNodeVersion(reporter)
 	if commands.ManualInstrumentation {
-		checkJSCodeBasedInstrumentation(reporter, commands.PackageJsonPath, commands.InstrumentationFile)
+		checkJSCodeBasedInstrumentation(reporter, commands.PackageJsonPath, commands.InstrumentationFile, commands.Target)
 	} else {
 		checkJSAutoInstrumentation(reporter, commands.PackageJsonPath)
 	}
@@ -99,6 +100,7 @@ func checkJSCodeBasedInstrumentation(
 	reporter *utils.ComponentReporter,
 	packageJsonPath string,
 	instrumentationFile string,
+	profile target.Profile,
 ) {
 	if os.Getenv("NODE_OPTIONS") == "--require @opentelemetry/auto-instrumentations-node/register" {
 		reporter.AddError(`The flag "-manual-instrumentation" was set, but the value of NODE_OPTIONS is set to require auto-instrumentation. Run "unset NODE_OPTIONS" to remove the requirement that can cause a conflict with manual instrumentations`)
@@ -116,8 +118,8 @@ func checkJSCodeBasedInstrumentation(
 			reporter.AddError("Dependency @opentelemetry/api missing on package.json")
 		}
 
-		if strings.Contains(string(packageJsonContent), `"@opentelemetry/exporter-trace-otlp-proto"`) {
-			reporter.AddError(`Dependency @opentelemetry/exporter-trace-otlp-proto added on package.json, which is not supported by Grafana. Switch the dependency to "@opentelemetry/exporter-trace-otlp-http" instead`)
+		if profile.Warns(target.WarnJSOTLPProto) && strings.Contains(string(packageJsonContent), `"@opentelemetry/exporter-trace-otlp-proto"`) {
+			reporter.AddError(fmt.Sprintf(`Dependency @opentelemetry/exporter-trace-otlp-proto added on package.json, which is not supported by target %s. Switch the dependency to "@opentelemetry/exporter-trace-otlp-http" instead`, profile.Name))
 		}
 	}
 
@@ -125,12 +127,12 @@ func checkJSCodeBasedInstrumentation(
 	instrumentationFileContent, err := os.ReadFile(instrumentationFile)
 	if err != nil {
 		reporter.AddError(fmt.Sprintf("Could not check file %s: %s", instrumentationFile, err))
-	} else {
+	} else if profile.Warns(target.WarnConsoleExporter) {
 		if strings.Contains(string(instrumentationFileContent), "ConsoleSpanExporter") {
-			reporter.AddWarning("Instrumentation file is using ConsoleSpanExporter. This exporter is useful during debugging, but replace with OTLPTraceExporter to send to Grafana Cloud")
+			reporter.AddWarning(fmt.Sprintf("Instrumentation file is using ConsoleSpanExporter. This exporter is useful during debugging, but replace with OTLPTraceExporter to send to %s", profile.Backend))
 		}
 		if strings.Contains(string(instrumentationFileContent), "ConsoleMetricExporter") {
-			reporter.AddWarning("Instrumentation file is using ConsoleMetricExporter. This exporter is useful during debugging, but replace with OTLPMetricExporter to send to Grafana Cloud")
+			reporter.AddWarning(fmt.Sprintf("Instrumentation file is using ConsoleMetricExporter. This exporter is useful during debugging, but replace with OTLPMetricExporter to send to %s", profile.Backend))
 		}
 	}
 }
@@ -2,11 +2,12 @@ package sdk
 
 import (
 	"fmt"
+	"github.com/grafana/otel-checker/checks/network"
 	"github.com/grafana/otel-checker/checks/utils"
 	"io"
-	"net/http"
 	"os/exec"
 	"strings"
+	"time"
 
 	"golang.org/x/mod/semver"
 )
@@ -128,8 +129,11 @@ func RunCommand(reporter *utils.ComponentReporter, cmd *exec.Cmd) string {
 	return string(output)
 }
 
+// downloadClient is used to fetch the lists of supported libraries
+var downloadClient = network.NewClient(nil, 30*time.Second)
+
 func LoadUrl(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+	resp, err := downloadClient.Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("error fetching instrumentation list: %v", err)
 	}
@@ -0,0 +1,198 @@
+package target
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed profiles.yaml
+var profilesFile []byte
+
+const (
+	// AuthBasic expects an Authorization header with the Basic scheme
+	AuthBasic = "basic"
+	// AuthBearer expects an Authorization header with the Bearer scheme
+	AuthBearer = "bearer"
+	// AuthNone expects no Authorization header
+	AuthNone = "none"
+)
+
+// Warnings that a profile can enable
+const (
+	// WarnLocalhost warns when the endpoint points to localhost
+	WarnLocalhost = "localhost"
+	// WarnConsoleExporter warns when console exporters are used instead of OTLP
+	WarnConsoleExporter = "console-exporter"
+	// WarnJSOTLPProto reports @opentelemetry/exporter-trace-otlp-proto as unsupported
+	WarnJSOTLPProto = "js-otlp-proto"
+)
+
+const DefaultName = "grafana-cloud"
+
+// Profile describes the backend telemetry is sent to and what the checks expect from it
+type Profile struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Extends is the name of a profile whose fields are used for fields not set in this profile
+	Extends string `yaml:"extends"`
+	// Backend is how messages refer to the target, e.g. "your Grafana Cloud instance"
+	Backend string `yaml:"backend"`
+	// Protocol is the required OTEL_EXPORTER_OTLP_PROTOCOL, empty accepts any protocol
+	Protocol string `yaml:"protocol"`
+	// Auth is the expected scheme of the Authorization header: basic, bearer or none. Empty accepts any
+	Auth string `yaml:"auth"`
+	// Endpoint is a regular expression the OTLP endpoint must match
+	Endpoint        string `yaml:"endpoint"`
+	EndpointExample string `yaml:"endpoint_example"`
+	// Local is set for targets running next to the application, e.g. a local collector.
+	// The exporters of a collector are not checked against a local target.
+	Local    bool     `yaml:"local"`
+	Warnings []string `yaml:"warnings"`
+}
+
+type profilesConfig struct {
+	Targets []Profile `yaml:"targets"`
+}
+
+// Builtin returns the built-in profiles
+func Builtin() []Profile {
+	var c profilesConfig
+	if err := yaml.Unmarshal(profilesFile, &c); err != nil {
+		panic(fmt.Sprintf("invalid built-in profiles: %s", err))
+	}
+	return c.Targets
+}
+
+// Default returns the profile used when no -target is given
+func Default() Profile {
+	p, _ := find(Builtin(), DefaultName)
+	return p
+}
+
+// Load returns the profile with the given name from the built-in profiles and the profiles
+// defined in the config file at configPath, which take precedence
+func Load(name string, configPath string) (Profile, error) {
+	profiles := Builtin()
+	if configPath != "" {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return Profile{}, fmt.Errorf("could not read config file %s: %w", configPath, err)
+		}
+		var c profilesConfig
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return Profile{}, fmt.Errorf("could not parse config file %s: %w", configPath, err)
+		}
+		// user profiles are searched first, so they can replace built-in profiles
+		profiles = append(c.Targets, profiles...)
+	}
+
+	p, ok := find(profiles, name)
+	if !ok {
+		var names []string
+		for _, p := range profiles {
+			if !slices.Contains(names, p.Name) {
+				names = append(names, p.Name)
+			}
+		}
+		return Profile{}, fmt.Errorf("target %s not found. Possible values: %s", name, strings.Join(names, ", "))
+	}
+	p, err := resolve(profiles, p, []string{p.Name})
+	if err != nil {
+		return Profile{}, err
+	}
+	return p, p.validate()
+}
+
+func find(profiles []Profile, name string) (Profile, bool) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Profile{}, false
+}
+
+// resolve fills the fields not set in the profile from the profile it extends
+func resolve(profiles []Profile, p Profile, seen []string) (Profile, error) {
+	if p.Extends == "" {
+		return p, nil
+	}
+	if slices.Contains(seen, p.Extends) {
+		return Profile{}, fmt.Errorf("target %s extends itself via %s", seen[0], strings.Join(append(seen, p.Extends), " > "))
+	}
+	parent, ok := find(profiles, p.Extends)
+	if !ok {
+		return Profile{}, fmt.Errorf("target %s extends unknown target %s", p.Name, p.Extends)
+	}
+	parent, err := resolve(profiles, parent, append(seen, p.Extends))
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if p.Description == "" {
+		p.Description = parent.Description
+	}
+	if p.Backend == "" {
+		p.Backend = parent.Backend
+	}
+	if p.Protocol == "" {
+		p.Protocol = parent.Protocol
+	}
+	if p.Auth == "" {
+		p.Auth = parent.Auth
+	}
+	if p.Endpoint == "" {
+		p.Endpoint = parent.Endpoint
+		if p.EndpointExample == "" {
+			p.EndpointExample = parent.EndpointExample
+		}
+	}
+	if !p.Local {
+		p.Local = parent.Local
+	}
+	if p.Warnings == nil {
+		p.Warnings = parent.Warnings
+	}
+	p.Extends = ""
+	return p, nil
+}
+
+func (p Profile) validate() error {
+	if p.Protocol != "" && !slices.Contains([]string{"http/protobuf", "http/json", "grpc"}, p.Protocol) {
+		return fmt.Errorf("target %s: protocol must be http/protobuf, http/json or grpc, but is %s", p.Name, p.Protocol)
+	}
+	if p.Auth != "" && !slices.Contains([]string{AuthBasic, AuthBearer, AuthNone}, p.Auth) {
+		return fmt.Errorf("target %s: auth must be %s, %s or %s, but is %s", p.Name, AuthBasic, AuthBearer, AuthNone, p.Auth)
+	}
+	if p.Endpoint != "" {
+		if _, err := regexp.Compile(p.Endpoint); err != nil {
+			return fmt.Errorf("target %s: endpoint is not a valid regular expression: %w", p.Name, err)
+		}
+	}
+	for _, w := range p.Warnings {
+		if !slices.Contains([]string{WarnLocalhost, WarnConsoleExporter, WarnJSOTLPProto}, w) {
+			return fmt.Errorf("target %s: unknown warning %s. Possible values: %s, %s, %s", p.Name, w, WarnLocalhost, WarnConsoleExporter, WarnJSOTLPProto)
+		}
+	}
+	return nil
+}
+
+// Warns returns true if the profile enables the warning
+func (p Profile) Warns(warning string) bool {
+	return slices.Contains(p.Warnings, warning)
+}
+
+// MatchesEndpoint returns true if the endpoint has the shape the profile expects
+func (p Profile) MatchesEndpoint(endpoint string) bool {
+	if p.Endpoint == "" {
+		return true
+	}
+	match, err := regexp.MatchString(p.Endpoint, endpoint)
+	return err == nil && match
+}
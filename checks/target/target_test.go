@@ -0,0 +1,108 @@
+package target
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuiltin(t *testing.T) {
+	for _, p := range Builtin() {
+		t.Run(p.Name, func(t *testing.T) {
+			loaded, err := Load(p.Name, "")
+			require.NoError(t, err)
+			assert.True(t, loaded.MatchesEndpoint(loaded.EndpointExample), "example must match the endpoint of the profile")
+		})
+	}
+	assert.Equal(t, DefaultName, Default().Name)
+}
+
+func TestLoad(t *testing.T) {
+	config := `
+targets:
+  - name: lgtm
+    extends: otlp
+    backend: the LGTM stack
+    protocol: http/protobuf
+    auth: bearer
+    endpoint: ^https://lgtm\.example\.com/otlp$
+    endpoint_example: https://lgtm.example.com/otlp
+  - name: lgtm-dev
+    extends: lgtm
+    warnings: []
+  - name: loop-a
+    extends: loop-b
+  - name: loop-b
+    extends: loop-a
+  - name: broken
+    protocol: http
+  - name: grafana-cloud
+    description: replaced
+`
+	path := filepath.Join(t.TempDir(), "otel-checker.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+
+	tests := []struct {
+		name        string
+		expected    Profile
+		expectedErr string
+	}{
+		{
+			name: "lgtm",
+			expected: Profile{
+				Name:            "lgtm",
+				Description:     "Self-hosted OTLP endpoint, e.g. an LGTM stack or a collector gateway",
+				Backend:         "the LGTM stack",
+				Protocol:        "http/protobuf",
+				Auth:            AuthBearer,
+				Endpoint:        `^https://lgtm\.example\.com/otlp$`,
+				EndpointExample: "https://lgtm.example.com/otlp",
+				Warnings:        []string{WarnLocalhost, WarnConsoleExporter},
+			},
+		},
+		{
+			name: "lgtm-dev",
+			expected: Profile{
+				Name:            "lgtm-dev",
+				Description:     "Self-hosted OTLP endpoint, e.g. an LGTM stack or a collector gateway",
+				Backend:         "the LGTM stack",
+				Protocol:        "http/protobuf",
+				Auth:            AuthBearer,
+				Endpoint:        `^https://lgtm\.example\.com/otlp$`,
+				EndpointExample: "https://lgtm.example.com/otlp",
+				Warnings:        []string{},
+			},
+		},
+		{
+			name:     "grafana-cloud",
+			expected: Profile{Name: "grafana-cloud", Description: "replaced"},
+		},
+		{
+			name:        "loop-a",
+			expectedErr: "target loop-a extends itself via loop-a > loop-b > loop-a",
+		},
+		{
+			name:        "broken",
+			expectedErr: "target broken: protocol must be http/protobuf, http/json or grpc, but is http",
+		},
+		{
+			name:        "unknown",
+			expectedErr: "target unknown not found. Possible values: lgtm, lgtm-dev, loop-a, loop-b, broken, grafana-cloud, otlp, local-collector",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Load(tt.name, path)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+		})
+	}
+}
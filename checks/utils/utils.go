@@ -3,11 +3,13 @@ package utils
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"slices"
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/grafana/otel-checker/checks/target"
 )
 
 const ERRORS = "errors"
@@ -22,30 +24,60 @@ type Commands struct {
 	InstrumentationFile   string
 	PackageJsonPath       string
 	CollectorConfigPath   string
+	CollectorVersion      string
+	CollectorURL          string
+	Fix                   bool
+	DryRun                bool
+	AlloyConfigPath       string
+	AlloyURL              string
+	AlloyOutputPath       string
+	BeylaConfigPath       string
 	Debug                 bool
+	ShowSecrets           bool
+	// Target is the profile of the backend telemetry is sent to
+	Target target.Profile
+	// TargetSet is true if -target was passed rather than defaulting to grafana-cloud
+	TargetSet bool
 }
 
 func GetArguments() Commands {
-	command := Commands{}
 	args := os.Args[1:]
 	if len(args) < 1 {
 		fmt.Println(color.RedString("You must pass a language used for your instrumentation, such as -language=js"))
 		os.Exit(1)
 	}
+	return ParseArguments(args)
+}
 
-	languageValue := flag.String("language", "", "Language used for instrumentation (required). Possible values: dotnet, go, java, js, python")
-	componentsString := flag.String("components", "", "Instrumentation components to test, separated by ',' (required). Possible values: sdk, collector, beyla, alloy")
-	manualInstrumentation := flag.Bool("manual-instrumentation", false, "Provide if your application is using manual instrumentation")
-	debug := flag.Bool("debug", false, "Output debug information")
-	webServer := flag.Bool("web-server", false, "Set if you would like the results served in a web server in addition to console output")
+// ParseArguments parses the flags of the checks from args
+func ParseArguments(args []string) Commands {
+	command := Commands{}
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	languageValue := flags.String("language", "", "Language used for instrumentation (required). Possible values: dotnet, go, java, js, python")
+	componentsString := flags.String("components", "", "Instrumentation components to test, separated by ',' (required). Possible values: sdk, collector, beyla, alloy")
+	manualInstrumentation := flags.Bool("manual-instrumentation", false, "Provide if your application is using manual instrumentation")
+	debug := flags.Bool("debug", false, "Output debug information")
+	webServer := flags.Bool("web-server", false, "Set if you would like the results served in a web server in addition to console output")
+	targetName := flags.String("target", target.DefaultName, "Backend telemetry is sent to. Possible values: grafana-cloud, otlp, local-collector, or a target defined in the -config file")
+	configPath := flags.String("config", "", `Path to a YAML file with additional target profiles. E.g. "-config=otel-checker.yaml"`)
+	showSecrets := flags.Bool("show-secrets", false, "Show the values of API keys, authorization headers and passwords in the results instead of masking them")
 
 	// javascript
-	instrumentationFile := flag.String("instrumentation-file", "", `Name (including path) to instrumentation file. Required if using manual-instrumentation. E.g."-instrumentation-file=src/inst/instrumentation.js"`)
-	packageJsonPath := flag.String("package-json-path", "", `Path to package.json file. Required if instrumentation is in JavaScript and the file is not in the same location as the otel-checker is being executed from. E.g. "-package-json-path=src/inst/"`)
+	instrumentationFile := flags.String("instrumentation-file", "", `Name (including path) to instrumentation file. Required if using manual-instrumentation. E.g."-instrumentation-file=src/inst/instrumentation.js"`)
+	packageJsonPath := flags.String("package-json-path", "", `Path to package.json file. Required if instrumentation is in JavaScript and the file is not in the same location as the otel-checker is being executed from. E.g. "-package-json-path=src/inst/"`)
 
 	// collector
-	collectorConfigPath := flag.String("collector-config-path", "", `Path to collector's config.yaml file. Required if using Collector and the config file is not in the same location as the otel-checker is being executed from. E.g. "-collector-config-path=src/inst/"`)
-	flag.Parse()
+	collectorConfigPath := flags.String("collector-config-path", "", `Path to collector's config.yaml file. Required if using Collector and the config file is not in the same location as the otel-checker is being executed from. E.g. "-collector-config-path=src/inst/". Accepts several directories, files or URIs (file:, env:, yaml:, http:, https:) separated by ',', which are merged like the collector's --config flags. E.g. "-collector-config-path=base.yaml,env:COLLECTOR_CONFIG"`)
+	collectorVersion := flags.String("collector-version", "", `Version of the collector the config is checked against for deprecated and removed components. Detected from "otelcol-contrib --version" or "otelcol --version" if not set. E.g. "-collector-version=0.110.0"`)
+	collectorURL := flags.String("collector-url", "", `URL of the running collector to check its health and internal telemetry for failed and refused data. The ports of the health_check extension and the telemetry are taken from the collector config. E.g. "-collector-url=http://localhost"`)
+	fix := flags.Bool("fix", false, "Fix findings of the collector config that have an obvious fix, such as a missing memory_limiter or batch processor. Comments are kept, the original file is kept as <file>.bak, or <file>.bak.N if that exists, and a diff of the changes is printed")
+	dryRun := flags.Bool("dry-run", false, "Print the diff of the fixes of -fix without changing the collector config")
+	// beyla
+	beylaConfigPath := flags.String("beyla-config-path", "", `Path to Beyla's YAML config file. Defaults to BEYLA_CONFIG_PATH, which Beyla reads its config file from. E.g. "-beyla-config-path=beyla-config.yml"`)
+	// alloy
+	alloyConfigPath := flags.String("alloy-config-path", "", `Path to Alloy's config.alloy file or the directory containing it. Required if using Alloy and the config file is not in the same location as the otel-checker is being executed from. E.g. "-alloy-config-path=/etc/alloy/config.alloy"`)
+	alloyURL := flags.String("alloy-url", "", `URL of the HTTP server of the running Alloy to check the health of its otelcol components and the receivers the SDK sends to. E.g. "-alloy-url=http://localhost:12345"`)
+	_ = flags.Parse(args)
 
 	possibleLanguages := []string{"dotnet", "go", "java", "js", "python", "ruby", "php"}
 	if !slices.Contains(possibleLanguages, *languageValue) {
@@ -67,6 +99,12 @@ func GetArguments() Commands {
 		}
 	}
 
+	profile, err := target.Load(*targetName, *configPath)
+	if err != nil {
+		fmt.Println(color.RedString(err.Error()))
+		os.Exit(1)
+	}
+
 	// javascript
 	if *languageValue == "js" && *instrumentationFile == "" && *manualInstrumentation {
 		fmt.Println(color.RedString(`When manual-instrumentation is being used, a instrumentation file is required. Remove "-manual-instrumentation" or "-instrumentation-file=path/to/file/file.js"`))
@@ -76,11 +114,6 @@ func GetArguments() Commands {
 		*packageJsonPath = *packageJsonPath + "/"
 	}
 
-	// collector
-	if *collectorConfigPath != "" && !strings.HasSuffix(*collectorConfigPath, "/") {
-		*collectorConfigPath = *collectorConfigPath + "/"
-	}
-
 	command.Language = *languageValue
 	command.Components = components
 	command.WebServer = *webServer
@@ -88,16 +121,60 @@ func GetArguments() Commands {
 	command.InstrumentationFile = *instrumentationFile
 	command.PackageJsonPath = *packageJsonPath
 	command.CollectorConfigPath = *collectorConfigPath
+	command.CollectorVersion = *collectorVersion
+	command.CollectorURL = *collectorURL
+	command.Fix = *fix
+	command.DryRun = *dryRun
+	command.AlloyConfigPath = *alloyConfigPath
+	command.AlloyURL = *alloyURL
+	command.BeylaConfigPath = *beylaConfigPath
 	command.Debug = *debug
+	command.ShowSecrets = *showSecrets
+	command.Target = profile
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == "target" {
+			command.TargetSet = true
+		}
+	})
 	return command
 }
 
+// GetConvertArguments parses the flags of the convert-collector command
+func GetConvertArguments(args []string) Commands {
+	flags := flag.NewFlagSet("convert-collector", flag.ExitOnError)
+	collectorConfigPath := flags.String("collector-config-path", "", `Path to collector's config.yaml file to convert. Accepts the same values as -collector-config-path of the checks. E.g. "-collector-config-path=src/inst/"`)
+	output := flags.String("output", "", `Path of the Alloy config file to write. The config is printed if not set. E.g. "-output=config.alloy"`)
+	targetName := flags.String("target", target.DefaultName, "Backend telemetry is sent to, which the converted config is checked against. Possible values: grafana-cloud, otlp, local-collector, or a target defined in the -config file")
+	configPath := flags.String("config", "", `Path to a YAML file with additional target profiles. E.g. "-config=otel-checker.yaml"`)
+	showSecrets := flags.Bool("show-secrets", false, "Show the values of API keys, authorization headers and passwords in the results instead of masking them")
+	_ = flags.Parse(args)
+
+	profile, err := target.Load(*targetName, *configPath)
+	if err != nil {
+		fmt.Println(color.RedString(err.Error()))
+		os.Exit(1)
+	}
+
+	return Commands{
+		Components:          []string{"alloy"},
+		CollectorConfigPath: *collectorConfigPath,
+		AlloyOutputPath:     *output,
+		ShowSecrets:         *showSecrets,
+		Target:              profile,
+	}
+}
+
 type Reporter struct {
 	components []*ComponentReporter
+	// Redactor masks secrets in the results. Secrets are shown if it is nil
+	Redactor *Redactor
+	// Out is where the results are printed, os.Stdout if it is nil
+	Out io.Writer
 }
 
 type ComponentReporter struct {
 	name     string
+	redactor *Redactor
 	Checks   []string
 	Warnings []string
 	Errors   []string
@@ -109,7 +186,7 @@ func (r *Reporter) Component(name string) *ComponentReporter {
 			return component
 		}
 	}
-	c := &ComponentReporter{name: name}
+	c := &ComponentReporter{name: name, redactor: r.Redactor}
 	r.components = append(r.components, c)
 	return c
 }
@@ -132,25 +209,36 @@ func (r *Reporter) PrintResults() map[string][]string {
 	}
 	res[ERRORS] = errors
 
+	if r.Redactor != nil {
+		for k, messages := range res {
+			res[k] = r.Redactor.RedactAll(messages)
+		}
+		checks, warnings, errors = res[CHECKS], res[WARNINGS], res[ERRORS]
+	}
+
+	var out io.Writer = os.Stdout
+	if r.Out != nil {
+		out = r.Out
+	}
 	if len(checks) > 0 {
 		green := color.New(color.FgGreen)
-		green.Printf("\n%d Successful Check(s)\n", len(checks))
+		green.Fprintf(out, "\n%d Successful Check(s)\n", len(checks))
 		for _, m := range checks {
-			green.Printf("✔ %s \n", m)
+			green.Fprintf(out, "✔ %s \n", m)
 		}
 	}
 	if len(warnings) > 0 {
 		yellow := color.New(color.FgYellow)
-		yellow.Printf("\n%d Warning(s)\n", len(warnings))
+		yellow.Fprintf(out, "\n%d Warning(s)\n", len(warnings))
 		for _, m := range warnings {
-			yellow.Printf("• %s \n", m)
+			yellow.Fprintf(out, "• %s \n", m)
 		}
 	}
 	if len(errors) > 0 {
 		red := color.New(color.FgRed)
-		red.Printf("\n%d Error(s)\n", len(errors))
+		red.Fprintf(out, "\n%d Error(s)\n", len(errors))
 		for _, m := range errors {
-			red.Printf("✖ %s \n", m)
+			red.Fprintf(out, "✖ %s \n", m)
 		}
 	}
 	return res
@@ -168,6 +256,30 @@ func (r *ComponentReporter) AddError(message string) {
 	r.Errors = append(r.Errors, fmt.Sprintf(`%s: %s`, r.name, message))
 }
 
+// AddSecret registers a value found by a check, e.g. a password in a config file, that must be masked in the results
+func (r *ComponentReporter) AddSecret(secret string) {
+	if r.redactor != nil {
+		r.redactor.AddSecret(secret)
+	}
+}
+
+// Redact returns the text with the secrets of the report masked, for output that is printed outside of the results,
+// such as a diff
+func (r *ComponentReporter) Redact(text string) string {
+	if r.redactor == nil {
+		return text
+	}
+	return r.redactor.Redact(text)
+}
+
+// Capitalize returns the message with its first letter in upper case, e.g. for an error that becomes a finding
+func Capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
 func FileExists(path string) bool {
 	_, err := os.ReadFile(path)
 	return err == nil
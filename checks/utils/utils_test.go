@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseArguments(t *testing.T) {
+	tests := []struct {
+		name              string
+		args              []string
+		expectedTarget    string
+		expectedTargetSet bool
+	}{
+		{
+			name:           "default target",
+			args:           []string{"-language=go", "-components=collector"},
+			expectedTarget: "grafana-cloud",
+		},
+		{
+			name:              "explicit target",
+			args:              []string{"-language=go", "-components=collector", "-target=grafana-cloud"},
+			expectedTarget:    "grafana-cloud",
+			expectedTargetSet: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commands := ParseArguments(tt.args)
+			assert.Equal(t, tt.expectedTarget, commands.Target.Name)
+			assert.Equal(t, tt.expectedTargetSet, commands.TargetSet)
+		})
+	}
+}
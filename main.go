@@ -5,6 +5,7 @@ import (
 	"html/template"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/grafana/otel-checker/checks"
 	"github.com/grafana/otel-checker/checks/utils"
@@ -19,6 +20,11 @@ var tmpls embed.FS
 var messages map[string][]string
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "convert-collector" {
+		checks.ConvertCollector(utils.GetConvertArguments(os.Args[2:]))
+		return
+	}
+
 	commands := utils.GetArguments()
 	messages = checks.RunAllChecks(commands)
 